@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -9,9 +10,21 @@ import (
 )
 
 func main() {
+	// The profile decides which config file is loaded
+	if name := cli.ProfileFromArgs(os.Args[1:]); name != "" {
+		if err := config.SetProfile(name); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	// Load configuration
 	cfg, err := config.Load()
-	if err != nil {
+	var invalidKeys *config.InvalidKeysError
+	if errors.As(err, &invalidKeys) {
+		// The rest of the file was applied
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	} else if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: Failed to load config: %v\n", err)
 		fmt.Fprintf(os.Stderr, "Using default configuration\n")
 		cfg = config.DefaultConfig()
@@ -19,6 +32,13 @@ func main() {
 
 	// Execute CLI
 	if err := cli.Execute(cfg); err != nil {
+		var exit *cli.ExitError
+		if errors.As(err, &exit) {
+			if exit.Msg != "" {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", exit.Msg)
+			}
+			os.Exit(exit.Code)
+		}
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,123 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/config"
+	"github.com/MithileshwaranS/queuectl/internal/job"
+)
+
+// Timeout bounds each audit command run and HTTP request
+const Timeout = 10 * time.Second
+
+// Record describes a job attempt that is about to run
+type Record struct {
+	Event     string    `json:"event"`
+	JobID     string    `json:"job_id"`
+	Queue     string    `json:"queue"`
+	Command   string    `json:"command"`
+	Attempt   int       `json:"attempt"`
+	WorkerID  string    `json:"worker_id"`
+	Host      string    `json:"host,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// Sink delivers a record of every job start to an audit command and/or URL
+type Sink struct {
+	Command string // Run with sh -c; receives the record as JSON on stdin
+	URL     string // Receives the record as a JSON POST
+	client  *http.Client
+}
+
+// New creates the sink selected by the configuration. It returns nil when
+// neither audit-command nor audit-url is set.
+func New(cfg *config.Config) *Sink {
+	if cfg.AuditCommand == "" && cfg.AuditURL == "" {
+		return nil
+	}
+	return &Sink{
+		Command: cfg.AuditCommand,
+		URL:     cfg.AuditURL,
+		client:  &http.Client{Timeout: Timeout},
+	}
+}
+
+// NewRecord builds the audit record for the job's next attempt
+func NewRecord(j *job.Job, workerID string) Record {
+	host, _ := os.Hostname()
+	return Record{
+		Event:     "started",
+		JobID:     j.ID,
+		Queue:     j.Queue,
+		Command:   j.CommandForAttempt(),
+		Attempt:   j.Attempts + 1,
+		WorkerID:  workerID,
+		Host:      host,
+		StartedAt: time.Now(),
+	}
+}
+
+// Send delivers the record to every configured destination. It returns an
+// error if any of them fails.
+func (s *Sink) Send(rec Record) error {
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	// One record per line, so a command can append to a JSON Lines file
+	payload = append(payload, '\n')
+
+	var errs []string
+	if s.Command != "" {
+		if err := s.runCommand(payload); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if s.URL != "" {
+		if err := s.post(payload); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// runCommand runs the audit command with the record on stdin
+func (s *Sink) runCommand(payload []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", s.Command)
+	cmd.Stdin = bytes.NewReader(payload)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("audit command failed: %w: %s", err, msg)
+		}
+		return fmt.Errorf("audit command failed: %w", err)
+	}
+	return nil
+}
+
+// post sends the record to the audit URL
+func (s *Sink) post(payload []byte) error {
+	resp, err := s.client.Post(s.URL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to post audit record: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("audit url returned %s", resp.Status)
+	}
+	return nil
+}
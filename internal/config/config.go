@@ -1,47 +1,319 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 // Config holds the application configuration
 type Config struct {
-	MaxRetries  int     `mapstructure:"max_retries"`
-	BackoffBase float64 `mapstructure:"backoff_base"`
-	DBPath      string  `mapstructure:"db_path"`
-	WorkerCount int     `mapstructure:"worker_count"`
+	MaxRetries           int     `mapstructure:"max_retries"`
+	BackoffBase          float64 `mapstructure:"backoff_base"`
+	DBPath               string  `mapstructure:"db_path"`
+	WorkerCount          int     `mapstructure:"worker_count"`
+	TimeFormat           string  `mapstructure:"time_format"`
+	AgePriorityBoost     int     `mapstructure:"age_priority_boost"`
+	LogMaxSizeMB         int     `mapstructure:"log_max_size_mb"`
+	LogMaxBackups        int     `mapstructure:"log_max_backups"`
+	MaxConsecutiveErrors int     `mapstructure:"max_consecutive_errors"`
+	Notifier             string  `mapstructure:"notifier"`
+	NotifyOnSuccess      bool    `mapstructure:"notify_on_success"`
+	SlackWebhookURL      string  `mapstructure:"slack_webhook_url"`
+	SMTPHost             string  `mapstructure:"smtp_host"`
+	SMTPPort             int     `mapstructure:"smtp_port"`
+	SMTPUsername         string  `mapstructure:"smtp_username"`
+	SMTPPassword         string  `mapstructure:"smtp_password"`
+	SMTPFrom             string  `mapstructure:"smtp_from"`
+	SMTPTo               string  `mapstructure:"smtp_to"`
+	PollIntervalMS       int     `mapstructure:"poll_interval_ms"`
+
+	// CompletedRetention is how long completed jobs are kept before the
+	// worker pool deletes them (0 keeps them forever)
+	CompletedRetention time.Duration `mapstructure:"completed_retention"`
+
+	// OTelEndpoint is the OTLP/HTTP collector workers push metrics to
+	// (empty disables)
+	OTelEndpoint string `mapstructure:"otel_endpoint"`
+
+	// List display truncation lengths in characters (0 disables)
+	ListOutputTruncate int `mapstructure:"list_output_truncate"`
+	ListErrorTruncate  int `mapstructure:"list_error_truncate"`
+
+	// StatsInterval is how often the worker pool records job counts for
+	// 'stats history' (0 disables)
+	StatsInterval time.Duration `mapstructure:"stats_interval"`
+	// StatsRetention is how long job count samples are kept; older ones
+	// are deleted as new ones are recorded (0 keeps them forever)
+	StatsRetention time.Duration `mapstructure:"stats_retention"`
+
+	// JobSchemaPath is a JSON Schema file enqueued job JSON must conform
+	// to (empty accepts any valid job)
+	JobSchemaPath string `mapstructure:"job_schema_path"`
+
+	// OutputTailLines caps the lines of output stored per attempt (0 keeps
+	// all); OutputKeep chooses whether the last or first lines survive
+	OutputTailLines int    `mapstructure:"output_tail_lines"`
+	OutputKeep      string `mapstructure:"output_keep"`
+
+	// MaxOutputBytes caps the bytes of stdout and of stderr a worker keeps
+	// per attempt, while the job runs (0 keeps all). The tail survives.
+	MaxOutputBytes int `mapstructure:"max_output_bytes"`
+
+	// Audit destinations told about every job start (empty disables).
+	// With AuditRequired a job is not run unless its start was recorded.
+	AuditCommand  string `mapstructure:"audit_command"`
+	AuditURL      string `mapstructure:"audit_url"`
+	AuditRequired bool   `mapstructure:"audit_required"`
+
+	// JobTimeoutSeconds is how long a job may run when neither it nor its
+	// queue sets timeout_seconds
+	JobTimeoutSeconds int `mapstructure:"job_timeout_seconds"`
+
+	// BackoffJitter is the fraction of each exponential backoff delay that
+	// is randomized, from 0 (exact delays) to 1 (anywhere from 0 to the
+	// full delay)
+	BackoffJitter float64 `mapstructure:"backoff_jitter"`
+
+	// MaxBackoffSeconds caps the exponential backoff delay between retries
+	MaxBackoffSeconds int `mapstructure:"max_backoff_seconds"`
+
+	// StaleJobThreshold is how long a processing job may go without a
+	// heartbeat from its worker before the pool returns it to pending
+	// (0 disables)
+	StaleJobThreshold time.Duration `mapstructure:"stale_job_threshold"`
+
+	// KillGraceSeconds is how long a job's processes get to exit after
+	// SIGTERM when it times out or is cancelled, before they are killed
+	// with SIGKILL (0 kills them at once)
+	KillGraceSeconds int `mapstructure:"kill_grace_seconds"`
+
+	// JobsPerSecond caps how many jobs all workers of a pool start per
+	// second together (0 is unlimited)
+	JobsPerSecond float64 `mapstructure:"jobs_per_second"`
+
+	// IdempotencyWindow is how long a completed job keeps its idempotency
+	// key, returning it to enqueues with the same key (0 frees the key on
+	// completion)
+	IdempotencyWindow time.Duration `mapstructure:"idempotency_window"`
+
+	// LogFormat is how workers write their log, as human-readable text or
+	// one JSON object per line; LogLevel is the least severe level written
+	LogFormat string `mapstructure:"log_format"`
+	LogLevel  string `mapstructure:"log_level"`
+
+	// DBBusyRetries is how many times a job save or claim is retried, with
+	// backoff, when the SQLite database stays locked past its busy timeout
+	DBBusyRetries int `mapstructure:"db_busy_retries"`
+
+	// Queues maps queue names to their enqueue defaults
+	Queues map[string]QueueConfig `mapstructure:"queues"`
+}
+
+// QueueConfig holds the enqueue defaults for a named queue. Zero values,
+// and a max_retries left out, fall back to the global defaults.
+type QueueConfig struct {
+	// MaxRetries is nil when the queue does not set it, so that an
+	// explicit 0 (no retries) is kept
+	MaxRetries     *int    `mapstructure:"max_retries"`
+	TimeoutSeconds int     `mapstructure:"timeout_seconds"`
+	BackoffBase    float64 `mapstructure:"backoff_base"`
+
+	// Weight is the queue's share of worker capacity under weighted fair
+	// scheduling (0 = unweighted, which counts as 1 once any queue is)
+	Weight int `mapstructure:"weight"`
+}
+
+// Supported values for TimeFormat
+const (
+	TimeFormatLocal    = "local"
+	TimeFormatUTC      = "utc"
+	TimeFormatRFC3339  = "rfc3339"
+	TimeFormatUnix     = "unix"
+	TimeFormatRelative = "relative"
+)
+
+// Supported values for OutputKeep
+const (
+	OutputKeepLast  = "last"
+	OutputKeepFirst = "first"
+)
+
+// Supported values for LogFormat
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// ValidNotifiers lists the accepted notifier values; empty, like none,
+// disables notifications
+var ValidNotifiers = []string{"", "none", "slack", "email"}
+
+// ValidLogLevels lists the accepted log_level values
+var ValidLogLevels = []string{"debug", "info", "warn", "error"}
+
+// ValidTimeFormats lists the accepted time_format values
+var ValidTimeFormats = []string{
+	TimeFormatLocal,
+	TimeFormatUTC,
+	TimeFormatRFC3339,
+	TimeFormatUnix,
+	TimeFormatRelative,
 }
 
 var (
 	instance *Config
 	once     sync.Once
 	mu       sync.RWMutex
+
+	// overrides holds the values changed with Set in this process. Save
+	// writes them over the config file's own settings, so defaults and
+	// environment variables are never persisted.
+	overrides = map[string]interface{}{}
 )
 
+// EnvPrefix prefixes the environment variables that override config keys,
+// e.g. QUEUECTL_MAX_RETRIES for max_retries
+const EnvPrefix = "QUEUECTL"
+
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
 	return &Config{
-		MaxRetries:  3,
-		BackoffBase: 2.0,
-		DBPath:      getDefaultDBPath(),
-		WorkerCount: 1,
+		MaxRetries:           3,
+		BackoffBase:          2.0,
+		DBPath:               getDefaultDBPath(),
+		WorkerCount:          1,
+		TimeFormat:           TimeFormatLocal,
+		LogMaxSizeMB:         10,
+		LogMaxBackups:        3,
+		MaxConsecutiveErrors: 10,
+		SMTPPort:             587,
+		PollIntervalMS:       1000,
+		ListOutputTruncate:   200,
+		ListErrorTruncate:    300,
+		OutputKeep:           OutputKeepLast,
+		MaxOutputBytes:       1 << 20,
+		JobTimeoutSeconds:    300,
+		MaxBackoffSeconds:    3600,
+		IdempotencyWindow:    time.Hour,
+		LogFormat:            LogFormatText,
+		LogLevel:             "info",
+		DBBusyRetries:        5,
+		StatsRetention:       7 * 24 * time.Hour,
 	}
 }
 
-// getDefaultDBPath returns the default database path
+// QueueDefaults returns the enqueue defaults for the named queue, with
+// MaxRetries always set. Unlisted queues and unset max_retries use the
+// global max_retries; timeout and backoff are left at 0 so they resolve
+// to the global values at run time.
+func (c *Config) QueueDefaults(queue string) QueueConfig {
+	// Viper lowercases map keys read from the config file
+	qc := c.Queues[strings.ToLower(queue)]
+	if qc.MaxRetries == nil {
+		maxRetries := c.MaxRetries
+		qc.MaxRetries = &maxRetries
+	}
+	return qc
+}
+
+// QueueWeights returns the configured queue weights keyed by lowercase
+// queue name, or nil if no queue has a weight (strict priority claims)
+func (c *Config) QueueWeights() map[string]int {
+	var weights map[string]int
+	for name, qc := range c.Queues {
+		if qc.Weight > 0 {
+			if weights == nil {
+				weights = make(map[string]int)
+			}
+			weights[strings.ToLower(name)] = qc.Weight
+		}
+	}
+	return weights
+}
+
+// validationRule is a range check on the value of one key
+type validationRule struct {
+	key   string
+	valid func(c *Config) bool
+	rule  string
+}
+
+// err describes a value breaking the rule
+func (r validationRule) err() error {
+	return fmt.Errorf("%s %s", r.key, r.rule)
+}
+
+// validationRules are the checks Validate applies
+var validationRules = []validationRule{
+	{"max_retries", func(c *Config) bool { return c.MaxRetries >= 0 }, "cannot be negative"},
+	{"worker_count", func(c *Config) bool { return c.WorkerCount >= 1 }, "must be at least 1"},
+	{"backoff_base", func(c *Config) bool { return c.BackoffBase >= 1 }, "must be at least 1"},
+	{"db_path", func(c *Config) bool { return c.DBPath != "" }, "cannot be empty"},
+	{"time_format", func(c *Config) bool { return slices.Contains(ValidTimeFormats, c.TimeFormat) }, "must be local, utc, rfc3339, unix or relative"},
+	{"age_priority_boost", func(c *Config) bool { return c.AgePriorityBoost >= 0 }, "cannot be negative"},
+	{"log_max_size_mb", func(c *Config) bool { return c.LogMaxSizeMB >= 1 }, "must be at least 1"},
+	{"log_max_backups", func(c *Config) bool { return c.LogMaxBackups >= 0 }, "cannot be negative"},
+	{"max_consecutive_errors", func(c *Config) bool { return c.MaxConsecutiveErrors >= 0 }, "cannot be negative"},
+	{"notifier", func(c *Config) bool { return slices.Contains(ValidNotifiers, c.Notifier) }, "must be none, slack or email"},
+	{"smtp_port", func(c *Config) bool { return c.SMTPPort >= 1 }, "must be at least 1"},
+	{"poll_interval_ms", func(c *Config) bool { return c.PollIntervalMS >= 10 }, "must be at least 10"},
+	{"completed_retention", func(c *Config) bool { return c.CompletedRetention >= 0 }, "cannot be negative"},
+	{"otel_endpoint", func(c *Config) bool { return isHTTPURL(c.OTelEndpoint) }, "must be an http:// or https:// URL"},
+	{"list_output_truncate", func(c *Config) bool { return c.ListOutputTruncate >= 0 }, "cannot be negative"},
+	{"list_error_truncate", func(c *Config) bool { return c.ListErrorTruncate >= 0 }, "cannot be negative"},
+	{"stats_interval", func(c *Config) bool { return c.StatsInterval >= 0 }, "cannot be negative"},
+	{"stats_retention", func(c *Config) bool { return c.StatsRetention >= 0 }, "cannot be negative"},
+	{"output_tail_lines", func(c *Config) bool { return c.OutputTailLines >= 0 }, "cannot be negative"},
+	{"output_keep", func(c *Config) bool { return c.OutputKeep == OutputKeepLast || c.OutputKeep == OutputKeepFirst }, "must be last or first"},
+	{"max_output_bytes", func(c *Config) bool { return c.MaxOutputBytes >= 0 }, "cannot be negative"},
+	{"audit_url", func(c *Config) bool { return isHTTPURL(c.AuditURL) }, "must be an http:// or https:// URL"},
+	{"job_timeout_seconds", func(c *Config) bool { return c.JobTimeoutSeconds >= 1 }, "must be at least 1"},
+	{"backoff_jitter", func(c *Config) bool { return c.BackoffJitter >= 0 && c.BackoffJitter <= 1 }, "must be between 0 and 1"},
+	{"max_backoff_seconds", func(c *Config) bool { return c.MaxBackoffSeconds >= 1 }, "must be at least 1"},
+	{"stale_job_threshold", func(c *Config) bool { return c.StaleJobThreshold >= 0 }, "cannot be negative"},
+	{"kill_grace_seconds", func(c *Config) bool { return c.KillGraceSeconds >= 0 }, "cannot be negative"},
+	{"jobs_per_second", func(c *Config) bool { return c.JobsPerSecond >= 0 }, "cannot be negative"},
+	{"idempotency_window", func(c *Config) bool { return c.IdempotencyWindow >= 0 }, "cannot be negative"},
+	{"log_format", func(c *Config) bool { return c.LogFormat == LogFormatText || c.LogFormat == LogFormatJSON }, "must be text or json"},
+	{"log_level", func(c *Config) bool { return slices.Contains(ValidLogLevels, c.LogLevel) }, "must be debug, info, warn or error"},
+	{"db_busy_retries", func(c *Config) bool { return c.DBBusyRetries >= 0 }, "cannot be negative"},
+}
+
+// isHTTPURL reports whether url is empty or an http:// or https:// URL
+func isHTTPURL(url string) bool {
+	return url == "" || strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")
+}
+
+// Validate checks every key against its allowed range or values,
+// reporting every key that is out of range
+func (c *Config) Validate() error {
+	var errs []error
+	for _, r := range validationRules {
+		if !r.valid(c) {
+			errs = append(errs, r.err())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// getDefaultDBPath returns the default database path of the active profile
 func getDefaultDBPath() string {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
+	if _, err := os.UserHomeDir(); err != nil {
 		return "./queuectl.db"
 	}
-	configDir := filepath.Join(homeDir, ".queuectl")
-	os.MkdirAll(configDir, 0755)
-	return filepath.Join(configDir, "queuectl.db")
+	dir := Dir()
+	os.MkdirAll(dir, 0755)
+	return filepath.Join(dir, "queuectl.db")
 }
 
 // Load loads configuration from file or creates default
@@ -51,10 +323,11 @@ func Load() (*Config, error) {
 		viper.SetConfigName("config")
 		viper.SetConfigType("yaml")
 
-		// Add config paths
-		homeDir, _ := os.UserHomeDir()
-		viper.AddConfigPath(filepath.Join(homeDir, ".queuectl"))
-		viper.AddConfigPath(".")
+		// Add config paths; a profile only reads its own directory
+		viper.AddConfigPath(Dir())
+		if ActiveProfile() == DefaultProfile {
+			viper.AddConfigPath(".")
+		}
 
 		// Set defaults
 		defaultCfg := DefaultConfig()
@@ -62,26 +335,154 @@ func Load() (*Config, error) {
 		viper.SetDefault("backoff_base", defaultCfg.BackoffBase)
 		viper.SetDefault("db_path", defaultCfg.DBPath)
 		viper.SetDefault("worker_count", defaultCfg.WorkerCount)
+		viper.SetDefault("time_format", defaultCfg.TimeFormat)
+		viper.SetDefault("age_priority_boost", defaultCfg.AgePriorityBoost)
+		viper.SetDefault("log_max_size_mb", defaultCfg.LogMaxSizeMB)
+		viper.SetDefault("log_max_backups", defaultCfg.LogMaxBackups)
+		viper.SetDefault("max_consecutive_errors", defaultCfg.MaxConsecutiveErrors)
+		viper.SetDefault("notifier", defaultCfg.Notifier)
+		viper.SetDefault("notify_on_success", defaultCfg.NotifyOnSuccess)
+		viper.SetDefault("slack_webhook_url", defaultCfg.SlackWebhookURL)
+		viper.SetDefault("smtp_host", defaultCfg.SMTPHost)
+		viper.SetDefault("smtp_port", defaultCfg.SMTPPort)
+		viper.SetDefault("smtp_username", defaultCfg.SMTPUsername)
+		viper.SetDefault("smtp_password", defaultCfg.SMTPPassword)
+		viper.SetDefault("smtp_from", defaultCfg.SMTPFrom)
+		viper.SetDefault("smtp_to", defaultCfg.SMTPTo)
+		viper.SetDefault("poll_interval_ms", defaultCfg.PollIntervalMS)
+		viper.SetDefault("completed_retention", defaultCfg.CompletedRetention)
+		viper.SetDefault("otel_endpoint", defaultCfg.OTelEndpoint)
+		viper.SetDefault("list_output_truncate", defaultCfg.ListOutputTruncate)
+		viper.SetDefault("list_error_truncate", defaultCfg.ListErrorTruncate)
+		viper.SetDefault("stats_interval", defaultCfg.StatsInterval)
+		viper.SetDefault("stats_retention", defaultCfg.StatsRetention)
+		viper.SetDefault("job_schema_path", defaultCfg.JobSchemaPath)
+		viper.SetDefault("output_tail_lines", defaultCfg.OutputTailLines)
+		viper.SetDefault("output_keep", defaultCfg.OutputKeep)
+		viper.SetDefault("max_output_bytes", defaultCfg.MaxOutputBytes)
+		viper.SetDefault("audit_command", defaultCfg.AuditCommand)
+		viper.SetDefault("audit_url", defaultCfg.AuditURL)
+		viper.SetDefault("audit_required", defaultCfg.AuditRequired)
+		viper.SetDefault("job_timeout_seconds", defaultCfg.JobTimeoutSeconds)
+		viper.SetDefault("backoff_jitter", defaultCfg.BackoffJitter)
+		viper.SetDefault("max_backoff_seconds", defaultCfg.MaxBackoffSeconds)
+		viper.SetDefault("stale_job_threshold", defaultCfg.StaleJobThreshold)
+		viper.SetDefault("kill_grace_seconds", defaultCfg.KillGraceSeconds)
+		viper.SetDefault("jobs_per_second", defaultCfg.JobsPerSecond)
+		viper.SetDefault("idempotency_window", defaultCfg.IdempotencyWindow)
+		viper.SetDefault("log_format", defaultCfg.LogFormat)
+		viper.SetDefault("log_level", defaultCfg.LogLevel)
+		viper.SetDefault("db_busy_retries", defaultCfg.DBBusyRetries)
+
+		// Environment variables override the file, e.g. QUEUECTL_DB_PATH
+		viper.SetEnvPrefix(EnvPrefix)
+		viper.AutomaticEnv()
+		bindEnv()
 
 		// Try to read config file
 		if err := viper.ReadInConfig(); err != nil {
 			if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-				loadErr = fmt.Errorf("error reading config file: %w", err)
+				// The YAML error carries the line of the problem
+				loadErr = fmt.Errorf("error reading config file %s: %w", viper.ConfigFileUsed(), err)
 				return
 			}
 			// Config file not found, use defaults
 		}
 
 		instance = &Config{}
+		var errs []error
 		if err := viper.Unmarshal(instance); err != nil {
-			loadErr = fmt.Errorf("error unmarshaling config: %w", err)
-			return
+			// Keep every key that does parse and default the rest
+			instance = DefaultConfig()
+			errs = unmarshalPerKey(instance)
+		}
+
+		// Values that parse but are out of range are defaulted too
+		for _, r := range validationRules {
+			if !r.valid(instance) {
+				errs = append(errs, r.err())
+				resetField(instance, r.key)
+			}
+		}
+
+		if len(errs) > 0 {
+			loadErr = &InvalidKeysError{
+				Path:   viper.ConfigFileUsed(),
+				Errors: errs,
+			}
 		}
 	})
 
 	return instance, loadErr
 }
 
+// bindEnv binds every scalar config key to its environment variable, so
+// they are picked up by Unmarshal even without a default
+func bindEnv() {
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		key := t.Field(i).Tag.Get("mapstructure")
+		if key == "" || t.Field(i).Type.Kind() == reflect.Map {
+			continue
+		}
+		viper.BindEnv(key)
+	}
+}
+
+// InvalidKeysError reports config keys whose values could not be parsed.
+// Those keys keep their defaults; the rest of the file is still applied.
+type InvalidKeysError struct {
+	Path   string
+	Errors []error
+}
+
+func (e *InvalidKeysError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("invalid values in config file %s or %s_* environment (using defaults for these keys): %s",
+		e.Path, EnvPrefix, strings.Join(msgs, "; "))
+}
+
+// unmarshalPerKey decodes each config key on its own into cfg, leaving the
+// current value in place for keys that fail. It returns one error per
+// failed key.
+func unmarshalPerKey(cfg *Config) []error {
+	var errs []error
+
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key := t.Field(i).Tag.Get("mapstructure")
+		if key == "" || !viper.IsSet(key) {
+			continue
+		}
+
+		value := reflect.New(t.Field(i).Type)
+		if err := viper.UnmarshalKey(key, value.Interface()); err != nil {
+			// The decoder names the field '' when decoding a single key
+			errs = append(errs, fmt.Errorf("%s: %s", key, strings.TrimPrefix(err.Error(), "'' ")))
+			continue
+		}
+		v.Field(i).Set(value.Elem())
+	}
+
+	return errs
+}
+
+// resetField sets the field of cfg with the given key to its default
+func resetField(cfg *Config, key string) {
+	defaults := reflect.ValueOf(DefaultConfig()).Elem()
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("mapstructure") == key {
+			v.Field(i).Set(defaults.Field(i))
+		}
+	}
+}
+
 // Get returns the singleton config instance
 func Get() *Config {
 	mu.RLock()
@@ -99,58 +500,285 @@ func Set(key string, value interface{}) error {
 	mu.Lock()
 	defer mu.Unlock()
 
-	viper.Set(key, value)
-
-	// Update instance
+	// Apply the value to a copy, so an invalid one changes nothing
 	if instance == nil {
-		instance = &Config{}
+		instance = DefaultConfig()
 	}
+	updated := *instance
 
-	switch key {
-	case "max_retries", "max-retries":
-		if v, ok := value.(int); ok {
-			instance.MaxRetries = v
-		}
-	case "backoff_base", "backoff-base":
-		if v, ok := value.(float64); ok {
-			instance.BackoffBase = v
-		}
-	case "db_path", "db-path":
-		if v, ok := value.(string); ok {
-			instance.DBPath = v
-		}
-	case "worker_count", "worker-count":
-		if v, ok := value.(int); ok {
-			instance.WorkerCount = v
-		}
+	// A value of the wrong type is an error, not silently skipped
+	name := strings.ReplaceAll(key, "-", "_")
+	var err error
+	switch name {
+	case "max_retries":
+		updated.MaxRetries, err = intValue(value)
+	case "backoff_base":
+		updated.BackoffBase, err = floatValue(value)
+	case "db_path":
+		updated.DBPath, err = stringValue(value)
+	case "worker_count":
+		updated.WorkerCount, err = intValue(value)
+	case "time_format":
+		updated.TimeFormat, err = stringValue(value)
+	case "age_priority_boost":
+		updated.AgePriorityBoost, err = intValue(value)
+	case "log_max_size_mb":
+		updated.LogMaxSizeMB, err = intValue(value)
+	case "log_max_backups":
+		updated.LogMaxBackups, err = intValue(value)
+	case "max_consecutive_errors":
+		updated.MaxConsecutiveErrors, err = intValue(value)
+	case "notifier":
+		updated.Notifier, err = stringValue(value)
+	case "notify_on_success":
+		updated.NotifyOnSuccess, err = boolValue(value)
+	case "slack_webhook_url":
+		updated.SlackWebhookURL, err = stringValue(value)
+	case "smtp_host":
+		updated.SMTPHost, err = stringValue(value)
+	case "smtp_port":
+		updated.SMTPPort, err = intValue(value)
+	case "smtp_username":
+		updated.SMTPUsername, err = stringValue(value)
+	case "smtp_password":
+		updated.SMTPPassword, err = stringValue(value)
+	case "smtp_from":
+		updated.SMTPFrom, err = stringValue(value)
+	case "smtp_to":
+		updated.SMTPTo, err = stringValue(value)
+	case "poll_interval_ms":
+		updated.PollIntervalMS, err = intValue(value)
+	case "completed_retention":
+		updated.CompletedRetention, err = durationValue(value)
+	case "otel_endpoint":
+		updated.OTelEndpoint, err = stringValue(value)
+	case "list_output_truncate":
+		updated.ListOutputTruncate, err = intValue(value)
+	case "list_error_truncate":
+		updated.ListErrorTruncate, err = intValue(value)
+	case "stats_interval":
+		updated.StatsInterval, err = durationValue(value)
+	case "stats_retention":
+		updated.StatsRetention, err = durationValue(value)
+	case "job_schema_path":
+		updated.JobSchemaPath, err = stringValue(value)
+	case "output_tail_lines":
+		updated.OutputTailLines, err = intValue(value)
+	case "output_keep":
+		updated.OutputKeep, err = stringValue(value)
+	case "max_output_bytes":
+		updated.MaxOutputBytes, err = intValue(value)
+	case "audit_command":
+		updated.AuditCommand, err = stringValue(value)
+	case "audit_url":
+		updated.AuditURL, err = stringValue(value)
+	case "audit_required":
+		updated.AuditRequired, err = boolValue(value)
+	case "job_timeout_seconds":
+		updated.JobTimeoutSeconds, err = intValue(value)
+	case "backoff_jitter":
+		updated.BackoffJitter, err = floatValue(value)
+	case "max_backoff_seconds":
+		updated.MaxBackoffSeconds, err = intValue(value)
+	case "stale_job_threshold":
+		updated.StaleJobThreshold, err = durationValue(value)
+	case "kill_grace_seconds":
+		updated.KillGraceSeconds, err = intValue(value)
+	case "jobs_per_second":
+		updated.JobsPerSecond, err = floatValue(value)
+	case "idempotency_window":
+		updated.IdempotencyWindow, err = durationValue(value)
+	case "log_format":
+		updated.LogFormat, err = stringValue(value)
+	case "log_level":
+		updated.LogLevel, err = stringValue(value)
+	case "db_busy_retries":
+		updated.DBBusyRetries, err = intValue(value)
 	default:
 		return fmt.Errorf("unknown config key: %s", key)
 	}
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+
+	if err := updated.Validate(); err != nil {
+		return err
+	}
+	*instance = updated
+
+	// Persist under the same snake_case keys that Load reads
+	viper.Set(name, value)
+	overrides[name] = value
 
 	return Save()
 }
 
-// Save persists the current configuration to file
-func Save() error {
-	homeDir, err := os.UserHomeDir()
+// intValue returns value if it is an int
+func intValue(value interface{}) (int, error) {
+	v, ok := value.(int)
+	if !ok {
+		return 0, fmt.Errorf("want an integer, got %T", value)
+	}
+	return v, nil
+}
+
+// floatValue returns value if it is a float64
+func floatValue(value interface{}) (float64, error) {
+	v, ok := value.(float64)
+	if !ok {
+		return 0, fmt.Errorf("want a number, got %T", value)
+	}
+	return v, nil
+}
+
+// stringValue returns value if it is a string
+func stringValue(value interface{}) (string, error) {
+	v, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("want a string, got %T", value)
+	}
+	return v, nil
+}
+
+// boolValue returns value if it is a bool
+func boolValue(value interface{}) (bool, error) {
+	v, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("want true or false, got %T", value)
+	}
+	return v, nil
+}
+
+// durationValue parses value, which must be a string, as a duration.
+// Durations are persisted as strings such as "72h0m0s".
+func durationValue(value interface{}) (time.Duration, error) {
+	v, ok := value.(string)
+	if !ok {
+		return 0, fmt.Errorf("want a duration string, got %T", value)
+	}
+	return time.ParseDuration(v)
+}
+
+// Reset removes key from the active profile's config file, so that it
+// falls back to its default, and reloads the configuration. An empty key
+// removes the whole file, resetting every key.
+func Reset(key string) (*Config, error) {
+	key = strings.ReplaceAll(key, "-", "_")
+	if key != "" && !isKey(key) {
+		return nil, fmt.Errorf("unknown config key: %s", key)
+	}
+
+	mu.Lock()
+	err := removeFromFile(GetConfigPath(), key)
+	if key == "" {
+		overrides = map[string]interface{}{}
+	} else {
+		delete(overrides, key)
+	}
+	if err == nil {
+		// Viper cannot unset a key, so load everything again from scratch
+		viper.Reset()
+		once = sync.Once{}
+		instance = nil
+	}
+	mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+	return Load()
+}
+
+// removeFromFile rewrites the config file at path without key, or deletes
+// the file if key is empty. A missing file is left alone.
+func removeFromFile(path, key string) error {
+	if key == "" {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove config file: %w", err)
+		}
+		return nil
+	}
+
+	settings, err := fileSettings(path)
+	if err != nil || settings == nil {
+		return err
+	}
+	delete(settings, key)
+	return writeSettings(path, settings)
+}
+
+// fileSettings returns the settings stored in the config file at path, or
+// nil if there is no such file
+func fileSettings(path string) (map[string]interface{}, error) {
+	file := viper.New()
+	file.SetConfigFile(path)
+	if err := file.ReadInConfig(); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading config file %s: %w", path, err)
+	}
+	return file.AllSettings(), nil
+}
+
+// persistedSettings returns the config file's settings with the values
+// changed by Set applied on top
+func persistedSettings() (map[string]interface{}, error) {
+	settings, err := fileSettings(GetConfigPath())
 	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = make(map[string]interface{}, len(overrides))
+	}
+	for k, v := range overrides {
+		settings[k] = v
+	}
+	return settings, nil
+}
+
+// writeSettings writes settings as a YAML config file at path
+func writeSettings(path string, settings map[string]interface{}) error {
+	out := viper.New()
+	for k, v := range settings {
+		out.Set(k, v)
+	}
+	return out.WriteConfigAs(path)
+}
+
+// isKey reports whether key is the snake_case name of a config field
+func isKey(key string) bool {
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("mapstructure") == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Save persists the current configuration to the active profile's file
+func Save() error {
+	if _, err := os.UserHomeDir(); err != nil {
 		return fmt.Errorf("failed to get home directory: %w", err)
 	}
 
-	configDir := filepath.Join(homeDir, ".queuectl")
-	if err := os.MkdirAll(configDir, 0755); err != nil {
+	dir := Dir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	configFile := filepath.Join(configDir, "config.yaml")
-	return viper.WriteConfigAs(configFile)
+	settings, err := persistedSettings()
+	if err != nil {
+		return err
+	}
+	return writeSettings(filepath.Join(dir, "config.yaml"), settings)
 }
 
-// GetConfigPath returns the path to the config file
+// GetConfigPath returns the path to the active profile's config file
 func GetConfigPath() string {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
+	if _, err := os.UserHomeDir(); err != nil {
 		return "./config.yaml"
 	}
-	return filepath.Join(homeDir, ".queuectl", "config.yaml")
+	return filepath.Join(Dir(), "config.yaml")
 }
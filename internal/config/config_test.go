@@ -0,0 +1,330 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// resetForTest points the home directory at a fresh temporary directory,
+// optionally holding a config.yaml with the given contents, and clears
+// the state Load keeps so it reads the file and environment again
+func resetForTest(t *testing.T, file string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	if file != "" {
+		dir := filepath.Join(home, ".queuectl")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(file), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reset := func() {
+		viper.Reset()
+		once = sync.Once{}
+		instance = nil
+		overrides = map[string]interface{}{}
+		profile = ""
+	}
+	reset()
+	t.Cleanup(reset)
+	return home
+}
+
+func TestLoadEnvOverridesDefaults(t *testing.T) {
+	resetForTest(t, "")
+	t.Setenv("QUEUECTL_MAX_RETRIES", "7")
+	t.Setenv("QUEUECTL_DB_PATH", "/tmp/env.db")
+	t.Setenv("QUEUECTL_WORKER_COUNT", "4")
+	t.Setenv("QUEUECTL_BACKOFF_BASE", "1.5")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.MaxRetries != 7 {
+		t.Errorf("MaxRetries = %d, want 7", cfg.MaxRetries)
+	}
+	if cfg.DBPath != "/tmp/env.db" {
+		t.Errorf("DBPath = %q, want /tmp/env.db", cfg.DBPath)
+	}
+	if cfg.WorkerCount != 4 {
+		t.Errorf("WorkerCount = %d, want 4", cfg.WorkerCount)
+	}
+	if cfg.BackoffBase != 1.5 {
+		t.Errorf("BackoffBase = %g, want 1.5", cfg.BackoffBase)
+	}
+}
+
+func TestLoadEnvOverridesFile(t *testing.T) {
+	resetForTest(t, "max_retries: 2\nworker_count: 3\nbackoff_base: 3\n")
+	t.Setenv("QUEUECTL_MAX_RETRIES", "9")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.MaxRetries != 9 {
+		t.Errorf("MaxRetries = %d, want 9 from the environment", cfg.MaxRetries)
+	}
+	if cfg.WorkerCount != 3 || cfg.BackoffBase != 3 {
+		t.Errorf("WorkerCount, BackoffBase = %d, %g, want 3, 3 from the file", cfg.WorkerCount, cfg.BackoffBase)
+	}
+}
+
+func TestLoadEnvOtherKeys(t *testing.T) {
+	resetForTest(t, "")
+	t.Setenv("QUEUECTL_COMPLETED_RETENTION", "24h")
+	t.Setenv("QUEUECTL_NOTIFY_ON_SUCCESS", "true")
+	t.Setenv("QUEUECTL_TIME_FORMAT", "utc")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.CompletedRetention != 24*time.Hour {
+		t.Errorf("CompletedRetention = %s, want 24h", cfg.CompletedRetention)
+	}
+	if !cfg.NotifyOnSuccess {
+		t.Error("NotifyOnSuccess = false, want true")
+	}
+	if cfg.TimeFormat != TimeFormatUTC {
+		t.Errorf("TimeFormat = %q, want utc", cfg.TimeFormat)
+	}
+}
+
+func TestLoadInvalidEnvKeepsDefault(t *testing.T) {
+	resetForTest(t, "")
+	t.Setenv("QUEUECTL_MAX_RETRIES", "lots")
+	t.Setenv("QUEUECTL_WORKER_COUNT", "0")
+	t.Setenv("QUEUECTL_BACKOFF_BASE", "1.5")
+
+	cfg, err := Load()
+	var invalid *InvalidKeysError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("got error %v, want an InvalidKeysError", err)
+	}
+	if len(invalid.Errors) != 2 {
+		t.Errorf("got %d invalid keys (%v), want 2", len(invalid.Errors), err)
+	}
+	for _, key := range []string{"max_retries", "worker_count"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("error %q does not name %s", err, key)
+		}
+	}
+
+	defaults := DefaultConfig()
+	if cfg.MaxRetries != defaults.MaxRetries || cfg.WorkerCount != defaults.WorkerCount {
+		t.Errorf("MaxRetries, WorkerCount = %d, %d, want the defaults %d, %d",
+			cfg.MaxRetries, cfg.WorkerCount, defaults.MaxRetries, defaults.WorkerCount)
+	}
+	if cfg.BackoffBase != 1.5 {
+		t.Errorf("BackoffBase = %g, want 1.5 from the valid variable", cfg.BackoffBase)
+	}
+}
+
+func TestSaveDoesNotPersistEnv(t *testing.T) {
+	home := resetForTest(t, "")
+	t.Setenv("QUEUECTL_MAX_RETRIES", "9")
+
+	if _, err := Load(); err != nil {
+		t.Fatal(err)
+	}
+	if err := Set("worker_count", 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(home, ".queuectl", "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "worker_count: 2") {
+		t.Errorf("saved file lacks the value set:\n%s", data)
+	}
+	if strings.Contains(string(data), "max_retries") {
+		t.Errorf("saved file persists the environment value:\n%s", data)
+	}
+}
+
+func TestValidateBoundaries(t *testing.T) {
+	tests := []struct {
+		key    string
+		modify func(c *Config)
+		valid  bool
+	}{
+		{"max_retries", func(c *Config) { c.MaxRetries = -1 }, false},
+		{"max_retries", func(c *Config) { c.MaxRetries = 0 }, true},
+		{"worker_count", func(c *Config) { c.WorkerCount = 0 }, false},
+		{"worker_count", func(c *Config) { c.WorkerCount = 1 }, true},
+		{"backoff_base", func(c *Config) { c.BackoffBase = 0.99 }, false},
+		{"backoff_base", func(c *Config) { c.BackoffBase = 1 }, true},
+		{"db_path", func(c *Config) { c.DBPath = "" }, false},
+		{"db_path", func(c *Config) { c.DBPath = "q.db" }, true},
+		{"time_format", func(c *Config) { c.TimeFormat = "" }, false},
+		{"time_format", func(c *Config) { c.TimeFormat = "iso" }, false},
+		{"age_priority_boost", func(c *Config) { c.AgePriorityBoost = -1 }, false},
+		{"log_max_size_mb", func(c *Config) { c.LogMaxSizeMB = 0 }, false},
+		{"log_max_size_mb", func(c *Config) { c.LogMaxSizeMB = 1 }, true},
+		{"notifier", func(c *Config) { c.Notifier = "pager" }, false},
+		{"notifier", func(c *Config) { c.Notifier = "slack" }, true},
+		{"smtp_port", func(c *Config) { c.SMTPPort = 0 }, false},
+		{"poll_interval_ms", func(c *Config) { c.PollIntervalMS = 9 }, false},
+		{"poll_interval_ms", func(c *Config) { c.PollIntervalMS = 10 }, true},
+		{"completed_retention", func(c *Config) { c.CompletedRetention = -time.Second }, false},
+		{"otel_endpoint", func(c *Config) { c.OTelEndpoint = "localhost:4318" }, false},
+		{"otel_endpoint", func(c *Config) { c.OTelEndpoint = "http://localhost:4318" }, true},
+		{"output_keep", func(c *Config) { c.OutputKeep = "middle" }, false},
+		{"output_keep", func(c *Config) { c.OutputKeep = OutputKeepFirst }, true},
+		{"audit_url", func(c *Config) { c.AuditURL = "ftp://audit" }, false},
+		{"job_timeout_seconds", func(c *Config) { c.JobTimeoutSeconds = 0 }, false},
+		{"job_timeout_seconds", func(c *Config) { c.JobTimeoutSeconds = 1 }, true},
+		{"backoff_jitter", func(c *Config) { c.BackoffJitter = -0.1 }, false},
+		{"backoff_jitter", func(c *Config) { c.BackoffJitter = 1.1 }, false},
+		{"backoff_jitter", func(c *Config) { c.BackoffJitter = 1 }, true},
+		{"max_backoff_seconds", func(c *Config) { c.MaxBackoffSeconds = 0 }, false},
+		{"stale_job_threshold", func(c *Config) { c.StaleJobThreshold = -time.Minute }, false},
+		{"kill_grace_seconds", func(c *Config) { c.KillGraceSeconds = -1 }, false},
+		{"jobs_per_second", func(c *Config) { c.JobsPerSecond = -0.5 }, false},
+		{"log_format", func(c *Config) { c.LogFormat = "xml" }, false},
+		{"log_format", func(c *Config) { c.LogFormat = LogFormatJSON }, true},
+		{"log_level", func(c *Config) { c.LogLevel = "trace" }, false},
+		{"log_level", func(c *Config) { c.LogLevel = "debug" }, true},
+		{"db_busy_retries", func(c *Config) { c.DBBusyRetries = -1 }, false},
+	}
+	for _, format := range ValidTimeFormats {
+		tests = append(tests, struct {
+			key    string
+			modify func(c *Config)
+			valid  bool
+		}{"time_format", func(c *Config) { c.TimeFormat = format }, true})
+	}
+
+	for _, tt := range tests {
+		cfg := DefaultConfig()
+		cfg.DBPath = "q.db"
+		tt.modify(cfg)
+		err := cfg.Validate()
+		if tt.valid && err != nil {
+			t.Errorf("%s: %+v: unexpected error %v", tt.key, cfg, err)
+		}
+		if !tt.valid {
+			if err == nil {
+				t.Errorf("%s: %+v: expected an error", tt.key, cfg)
+			} else if !strings.HasPrefix(err.Error(), tt.key+" ") {
+				t.Errorf("%s: error %q does not name the key", tt.key, err)
+			}
+		}
+	}
+}
+
+func TestValidateReportsEveryKey(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxRetries = -1
+	cfg.WorkerCount = 0
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, key := range []string{"max_retries", "worker_count"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("error %q does not name %s", err, key)
+		}
+	}
+}
+
+func TestSetBoundaries(t *testing.T) {
+	tests := []struct {
+		key   string
+		value interface{}
+		valid bool
+	}{
+		{"max-retries", -1, false},
+		{"max-retries", 0, true},
+		{"worker-count", 0, false},
+		{"worker-count", 1, true},
+		{"backoff-base", 0.5, false},
+		{"backoff-base", 1.0, true},
+		{"db-path", "", false},
+		{"db-path", "other.db", true},
+		{"time-format", "iso", false},
+		{"time-format", "utc", true},
+		{"poll-interval-ms", 9, false},
+		{"backoff-jitter", 1.5, false},
+		{"log-level", "loud", false},
+		// A value of the wrong type fails rather than being skipped
+		{"max-retries", "5", false},
+		{"backoff-base", 2, false},
+		{"notify-on-success", "yes", false},
+		{"stale-job-threshold", 10 * time.Minute, false},
+		{"stale-job-threshold", "10m", true},
+	}
+	for _, tt := range tests {
+		home := resetForTest(t, "")
+		if _, err := Load(); err != nil {
+			t.Fatal(err)
+		}
+		before := *Get()
+
+		err := Set(tt.key, tt.value)
+		path := filepath.Join(home, ".queuectl", "config.yaml")
+		_, statErr := os.Stat(path)
+		if tt.valid {
+			if err != nil {
+				t.Errorf("Set(%s, %v): unexpected error %v", tt.key, tt.value, err)
+			}
+			if statErr != nil {
+				t.Errorf("Set(%s, %v) did not save: %v", tt.key, tt.value, statErr)
+			}
+			continue
+		}
+
+		if err == nil {
+			t.Errorf("Set(%s, %v): expected an error", tt.key, tt.value)
+			continue
+		}
+		if !strings.Contains(err.Error(), strings.ReplaceAll(tt.key, "-", "_")) {
+			t.Errorf("Set(%s, %v): error %q does not name the key", tt.key, tt.value, err)
+		}
+		if got := *Get(); got.MaxRetries != before.MaxRetries || got.WorkerCount != before.WorkerCount ||
+			got.BackoffBase != before.BackoffBase || got.DBPath != before.DBPath {
+			t.Errorf("Set(%s, %v) changed the config although it failed", tt.key, tt.value)
+		}
+		if statErr == nil {
+			t.Errorf("Set(%s, %v) saved although it failed", tt.key, tt.value)
+		}
+	}
+}
+
+func TestLoadDefaultsOutOfRangeFileValues(t *testing.T) {
+	resetForTest(t, "max_retries: -1\nworker_count: 0\nbackoff_base: 0.5\ndb_path: \"\"\ntime_format: iso\n"+
+		"log_level: loud\nbackoff_jitter: 2\njob_timeout_seconds: 0\npoll_interval_ms: 250\n")
+
+	cfg, err := Load()
+	var invalid *InvalidKeysError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("got error %v, want an InvalidKeysError", err)
+	}
+	if len(invalid.Errors) != 8 {
+		t.Errorf("got %d invalid keys (%v), want 8", len(invalid.Errors), err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("loaded config is still invalid: %v", err)
+	}
+	if cfg.PollIntervalMS != 250 {
+		t.Errorf("PollIntervalMS = %d, want 250: valid keys are still applied", cfg.PollIntervalMS)
+	}
+}
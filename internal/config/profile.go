@@ -0,0 +1,170 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultProfile names the configuration kept directly in ~/.queuectl
+const DefaultProfile = "default"
+
+// activeProfileFile records the profile selected with UseProfile
+const activeProfileFile = "active_profile"
+
+var profileNameRe = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// profile is the profile chosen for this process with SetProfile
+var profile string
+
+// baseDir returns the queuectl home directory, ~/.queuectl
+func baseDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "."
+	}
+	return filepath.Join(homeDir, ".queuectl")
+}
+
+// profileDir returns the directory holding the named profile's config
+// file and default database
+func profileDir(name string) string {
+	if name == "" || name == DefaultProfile {
+		return baseDir()
+	}
+	return filepath.Join(baseDir(), "profiles", name)
+}
+
+// Dir returns the directory of the active profile. It holds the profile's
+// config file, default database, worker PID and heartbeat files and job
+// logs.
+func Dir() string {
+	return profileDir(ActiveProfile())
+}
+
+// validateProfileName checks that name can be used as a directory name
+func validateProfileName(name string) error {
+	if !profileNameRe.MatchString(name) {
+		return fmt.Errorf("invalid profile name: %q (use letters, digits, '-' and '_')", name)
+	}
+	return nil
+}
+
+// SetProfile selects the profile used by this process, overriding the one
+// chosen with UseProfile. It must be called before Load.
+func SetProfile(name string) error {
+	if err := validateProfileName(name); err != nil {
+		return err
+	}
+	if name != DefaultProfile {
+		if _, err := os.Stat(profileDir(name)); err != nil {
+			return fmt.Errorf("profile %s does not exist (create it with 'queuectl config profile create %s')", name, name)
+		}
+	}
+	profile = name
+	return nil
+}
+
+// ActiveProfile returns the profile in effect: the one set with
+// SetProfile, else the one chosen with UseProfile, else DefaultProfile
+func ActiveProfile() string {
+	if profile != "" {
+		return profile
+	}
+	data, err := os.ReadFile(filepath.Join(baseDir(), activeProfileFile))
+	if err != nil {
+		return DefaultProfile
+	}
+	name := strings.TrimSpace(string(data))
+	if validateProfileName(name) != nil {
+		return DefaultProfile
+	}
+	if _, err := os.Stat(profileDir(name)); err != nil {
+		return DefaultProfile
+	}
+	return name
+}
+
+// UseProfile makes name the active profile for later invocations
+func UseProfile(name string) error {
+	if err := validateProfileName(name); err != nil {
+		return err
+	}
+
+	path := filepath.Join(baseDir(), activeProfileFile)
+	if name == DefaultProfile {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to reset active profile: %w", err)
+		}
+		return nil
+	}
+
+	if _, err := os.Stat(profileDir(name)); err != nil {
+		return fmt.Errorf("profile %s does not exist", name)
+	}
+	if err := os.MkdirAll(baseDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return os.WriteFile(path, []byte(name+"\n"), 0644)
+}
+
+// CreateProfile creates a profile whose config file is a copy of the
+// current one, except that db_path points at a database inside the
+// profile's own directory. It returns the path of the new config file.
+func CreateProfile(name string) (string, error) {
+	if err := validateProfileName(name); err != nil {
+		return "", err
+	}
+	if name == DefaultProfile {
+		return "", fmt.Errorf("%s is reserved for the configuration in %s", DefaultProfile, baseDir())
+	}
+
+	dir := profileDir(name)
+	if _, err := os.Stat(dir); err == nil {
+		return "", fmt.Errorf("profile %s already exists", name)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create profile directory: %w", err)
+	}
+
+	mu.RLock()
+	settings, err := persistedSettings()
+	mu.RUnlock()
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	settings["db_path"] = filepath.Join(dir, "queuectl.db")
+
+	path := filepath.Join(dir, "config.yaml")
+	if err := writeSettings(path, settings); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("failed to write profile config: %w", err)
+	}
+	return path, nil
+}
+
+// ListProfiles returns the names of all profiles, including DefaultProfile
+func ListProfiles() ([]string, error) {
+	names := []string{DefaultProfile}
+
+	entries, err := os.ReadDir(filepath.Join(baseDir(), "profiles"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return names, nil
+		}
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+
+	var others []string
+	for _, e := range entries {
+		if e.IsDir() && e.Name() != DefaultProfile && validateProfileName(e.Name()) == nil {
+			others = append(others, e.Name())
+		}
+	}
+	sort.Strings(others)
+	return append(names, others...), nil
+}
@@ -1,13 +1,29 @@
 package job
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
 
+// DefaultQueue is the queue jobs belong to when none is given
+const DefaultQueue = "default"
+
+// MaxChainDepth limits how many next_job levels a job may nest
+const MaxChainDepth = 10
+
+// maxParentOutput caps the parent output handed to a follow-up job so it
+// fits in a single environment variable
+const maxParentOutput = 64 * 1024
+
 // State represents the current state of a job
 type State string
 
@@ -17,21 +33,117 @@ const (
 	StateCompleted  State = "completed"
 	StateFailed     State = "failed"
 	StateDead       State = "dead"
+	StateHeld       State = "held"      // Temporarily excluded from claims until HeldUntil
+	StateArchived   State = "archived"  // Dead job superseded by a new retry job
+	StateCancelled  State = "cancelled" // Stopped by an operator before it finished
 )
 
+// AllStates lists every job state, in lifecycle order
+var AllStates = []State{
+	StatePending,
+	StateProcessing,
+	StateCompleted,
+	StateFailed,
+	StateDead,
+	StateHeld,
+	StateArchived,
+	StateCancelled,
+}
+
+// ErrorType classifies why a job attempt failed
+type ErrorType string
+
+const (
+	ErrorTypeExit    ErrorType = "exit"    // Command ran and exited non-zero
+	ErrorTypeTimeout ErrorType = "timeout" // Command exceeded its execution timeout
+	ErrorTypeStart   ErrorType = "start"   // Command could not be started
+	ErrorTypeKilled  ErrorType = "killed"  // Job was killed by an operator
+	ErrorTypeOutput  ErrorType = "output"  // Output did not satisfy the success/failure patterns
+	ErrorTypeAudit   ErrorType = "audit"   // Start could not be audited and audit_required is set
+)
+
+// AttemptRecord records a single execution attempt of a job
+type AttemptRecord struct {
+	Attempt   int       `json:"attempt"`
+	Command   string    `json:"command"`
+	StartedAt time.Time `json:"started_at"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Duration is a time.Duration that encodes to JSON as a string like "5m".
+// It also decodes plain numbers as seconds.
+type Duration time.Duration
+
+// MarshalJSON encodes the duration as a Go duration string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON decodes a duration string such as "30s" or a number of seconds
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case float64:
+		*d = Duration(v * float64(time.Second))
+	case string:
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", v)
+		}
+		*d = Duration(parsed)
+	default:
+		return fmt.Errorf("invalid duration %s", data)
+	}
+	return nil
+}
+
 // Job represents a background job to be executed
 type Job struct {
-	ID         string    `json:"id"`
-	Command    string    `json:"command"`
-	State      State     `json:"state"`
-	Attempts   int       `json:"attempts"`
-	MaxRetries int       `json:"max_retries"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
-	NextRetryAt *time.Time `json:"next_retry_at,omitempty"`
-	WorkerID   string    `json:"worker_id,omitempty"`
-	Error      string    `json:"error,omitempty"`
-	Output     string    `json:"output,omitempty"`
+	ID                  string            `json:"id"`
+	Seq                 int64             `json:"seq,omitempty"`      // Enqueue order, assigned by storage
+	RetryOf             string            `json:"retry_of,omitempty"` // ID of the job this one retries
+	Queue               string            `json:"queue"`
+	Command             string            `json:"command"`
+	FallbackCommand     string            `json:"fallback_command,omitempty"`
+	State               State             `json:"state"`
+	Attempts            int               `json:"attempts"`
+	MaxRetries          int               `json:"max_retries"`
+	TimeoutSeconds      int               `json:"timeout_seconds,omitempty"` // 0 uses the worker default
+	BackoffBase         float64           `json:"backoff_base,omitempty"`    // 0 uses the configured backoff_base
+	RetrySchedule       []Duration        `json:"retry_schedule,omitempty"`  // Explicit retry delays; overrides backoff
+	Priority            int               `json:"priority"`
+	EnvFile             string            `json:"env_file,omitempty"`
+	Env                 map[string]string `json:"env,omitempty"`             // Added to the environment, over env_file
+	WorkDir             string            `json:"work_dir,omitempty"`        // Absolute directory the command runs in
+	Tags                []string          `json:"tags,omitempty"`            // Labels for grouping, matched by list --tag
+	IdempotencyKey      string            `json:"idempotency_key,omitempty"` // Enqueues with a key in use return the existing job
+	RetryOnTimeoutOnly  bool              `json:"retry_on_timeout_only,omitempty"`
+	Sandbox             bool              `json:"sandbox,omitempty"`
+	SuccessPattern      string            `json:"success_pattern,omitempty"`
+	FailurePattern      string            `json:"failure_pattern,omitempty"`
+	CreatedAt           time.Time         `json:"created_at"`
+	UpdatedAt           time.Time         `json:"updated_at"`
+	NextRetryAt         *time.Time        `json:"next_retry_at,omitempty"`
+	ScheduledAt         *time.Time        `json:"scheduled_at,omitempty"`
+	HeldUntil           *time.Time        `json:"held_until,omitempty"`
+	CompletedAt         *time.Time        `json:"completed_at,omitempty"`
+	WorkerID            string            `json:"worker_id,omitempty"`
+	Error               string            `json:"error,omitempty"`
+	ErrorType           ErrorType         `json:"error_type,omitempty"`
+	Output              string            `json:"output,omitempty"`
+	ExitCode            int               `json:"exit_code"`             // Of the last attempt; -1 if the command could not be started
+	CPUTimeMS           int64             `json:"cpu_time_ms,omitempty"` // User+system CPU time of the last attempt
+	MaxRSSKB            int64             `json:"max_rss_kb,omitempty"`  // Peak resident memory of the last attempt
+	DurationMS          int64             `json:"duration_ms,omitempty"` // Wall-clock run time of the last attempt
+	Progress            int               `json:"progress,omitempty"`    // Percent done last reported by the running attempt, 0-100
+	History             []AttemptRecord   `json:"history,omitempty"`
+	NextJob             *Job              `json:"next_job,omitempty"`              // Enqueued when this job succeeds
+	ParentID            string            `json:"parent_id,omitempty"`             // Job whose success enqueued this one
+	ParentOutput        string            `json:"parent_output,omitempty"`         // Exported as QUEUECTL_PARENT_OUTPUT
+	DependsDelaySeconds int               `json:"depends_delay_seconds,omitempty"` // next_job only: wait this long after the parent completes
 }
 
 // NewJob creates a new job with default values
@@ -39,6 +151,7 @@ func NewJob(command string, maxRetries int) *Job {
 	now := time.Now()
 	return &Job{
 		ID:         uuid.New().String(),
+		Queue:      DefaultQueue,
 		Command:    command,
 		State:      StatePending,
 		Attempts:   0,
@@ -59,10 +172,18 @@ func FromJSON(data string) (*Job, error) {
 	if job.ID == "" {
 		job.ID = uuid.New().String()
 	}
+	if job.Queue == "" {
+		job.Queue = DefaultQueue
+	}
 	if job.State == "" {
 		job.State = StatePending
 	}
-	if job.MaxRetries == 0 {
+	// Only an absent max_retries gets the default; an explicit 0 sends
+	// the job to the DLQ on its first failure
+	var present struct {
+		MaxRetries *int `json:"max_retries"`
+	}
+	if err := json.Unmarshal([]byte(data), &present); err == nil && present.MaxRetries == nil {
 		job.MaxRetries = 3 // default
 	}
 	now := time.Now()
@@ -73,9 +194,83 @@ func FromJSON(data string) (*Job, error) {
 		job.UpdatedAt = now
 	}
 
+	if err := job.validateExecution(); err != nil {
+		return nil, err
+	}
+
 	return &job, nil
 }
 
+// validateExecution checks the env and work_dir fields. FromJSON applies
+// it too, so a relative work_dir is never accepted.
+func (j *Job) validateExecution() error {
+	if j.WorkDir != "" {
+		if !filepath.IsAbs(j.WorkDir) {
+			return fmt.Errorf("work_dir must be an absolute path: %s", j.WorkDir)
+		}
+		if j.Sandbox {
+			return fmt.Errorf("work_dir cannot be combined with sandbox")
+		}
+	}
+	for name := range j.Env {
+		if name == "" || strings.ContainsAny(name, "=\x00") {
+			return fmt.Errorf("invalid env variable name: %q", name)
+		}
+	}
+	return nil
+}
+
+// IDFromCommand derives a deterministic job ID from a command. The command
+// is normalized by trimming it and collapsing runs of whitespace, so
+// commands that differ only in spacing share an ID.
+func IDFromCommand(command string) string {
+	normalized := strings.Join(strings.Fields(command), " ")
+	sum := sha256.Sum256([]byte(normalized))
+	return "cmd-" + hex.EncodeToString(sum[:8])
+}
+
+// SpawnNext builds the follow-up job enqueued when j succeeds with the
+// given output. It returns nil if j has no next_job.
+func (j *Job) SpawnNext(output string) *Job {
+	if j.NextJob == nil {
+		return nil
+	}
+
+	now := time.Now()
+	next := *j.NextJob
+	if next.ID == "" {
+		next.ID = uuid.New().String()
+	}
+	if next.Queue == "" {
+		next.Queue = DefaultQueue
+	}
+	next.State = StatePending
+	next.Attempts = 0
+	next.CreatedAt = now
+	next.UpdatedAt = now
+	next.ParentID = j.ID
+	if len(output) > maxParentOutput {
+		// Cut at the start of a character, so none is split in half
+		n := maxParentOutput
+		for n > 0 && !utf8.RuneStart(output[n]) {
+			n--
+		}
+		output = output[:n]
+	}
+	next.ParentOutput = output
+
+	// Hold the follow-up back until the cooldown after the parent's completion
+	if next.DependsDelaySeconds > 0 {
+		completed := now
+		if j.CompletedAt != nil {
+			completed = *j.CompletedAt
+		}
+		scheduled := completed.Add(time.Duration(next.DependsDelaySeconds) * time.Second)
+		next.ScheduledAt = &scheduled
+	}
+	return &next
+}
+
 // ToJSON converts job to JSON string
 func (j *Job) ToJSON() (string, error) {
 	data, err := json.MarshalIndent(j, "", "  ")
@@ -93,14 +288,134 @@ func (j *Job) Validate() error {
 	if j.MaxRetries < 0 {
 		return fmt.Errorf("max_retries cannot be negative")
 	}
+	if j.TimeoutSeconds < 0 {
+		return fmt.Errorf("timeout_seconds cannot be negative")
+	}
+	if j.BackoffBase < 0 {
+		return fmt.Errorf("backoff_base cannot be negative")
+	}
+	if j.DependsDelaySeconds < 0 {
+		return fmt.Errorf("depends_delay_seconds cannot be negative")
+	}
+	for i, d := range j.RetrySchedule {
+		if d <= 0 {
+			return fmt.Errorf("retry_schedule[%d] must be positive", i)
+		}
+	}
+	if _, err := regexp.Compile(j.SuccessPattern); err != nil {
+		return fmt.Errorf("invalid success_pattern: %w", err)
+	}
+	if _, err := regexp.Compile(j.FailurePattern); err != nil {
+		return fmt.Errorf("invalid failure_pattern: %w", err)
+	}
+	if err := j.validateExecution(); err != nil {
+		return err
+	}
+	if j.IdempotencyKey != "" && strings.TrimSpace(j.IdempotencyKey) == "" {
+		return fmt.Errorf("idempotency_key cannot be blank")
+	}
+	for i, tag := range j.Tags {
+		if strings.TrimSpace(tag) == "" {
+			return fmt.Errorf("tags[%d] cannot be empty", i)
+		}
+	}
+
+	depth := 0
+	for next := j.NextJob; next != nil; next = next.NextJob {
+		depth++
+		if depth > MaxChainDepth {
+			return fmt.Errorf("next_job chain is deeper than %d levels", MaxChainDepth)
+		}
+		if next.ID != "" && next.ID == j.ID {
+			return fmt.Errorf("next_job cannot reuse the id of its parent")
+		}
+	}
+	if j.NextJob != nil {
+		if err := j.NextJob.Validate(); err != nil {
+			return fmt.Errorf("invalid next_job: %w", err)
+		}
+	}
 	return nil
 }
 
+// CheckOutput applies the job's success/failure patterns to the output of a
+// command that exited successfully. It returns an error if the output
+// matches failure_pattern or does not match success_pattern.
+func (j *Job) CheckOutput(output string) error {
+	if j.FailurePattern != "" {
+		re, err := regexp.Compile(j.FailurePattern)
+		if err != nil {
+			return fmt.Errorf("invalid failure_pattern: %w", err)
+		}
+		// A pattern can match the empty string (e.g. ^$ for no output),
+		// so test for a match rather than for non-empty matched text
+		if re.MatchString(output) {
+			return fmt.Errorf("output matched failure_pattern %q: %q", j.FailurePattern, re.FindString(output))
+		}
+	}
+	if j.SuccessPattern != "" {
+		re, err := regexp.Compile(j.SuccessPattern)
+		if err != nil {
+			return fmt.Errorf("invalid success_pattern: %w", err)
+		}
+		if !re.MatchString(output) {
+			return fmt.Errorf("output did not match success_pattern %q", j.SuccessPattern)
+		}
+	}
+	return nil
+}
+
+// IsTerminal reports whether the job has reached a final state
+func (j *Job) IsTerminal() bool {
+	return j.State == StateCompleted || j.State == StateDead || j.State == StateArchived || j.State == StateCancelled
+}
+
+// CommandForAttempt returns the command to run for the current attempt.
+// Retries use the fallback command when one is configured.
+func (j *Job) CommandForAttempt() string {
+	if j.Attempts > 0 && j.FallbackCommand != "" {
+		return j.FallbackCommand
+	}
+	return j.Command
+}
+
+// RecordAttempt appends the current attempt to the job's history
+func (j *Job) RecordAttempt(startedAt time.Time, errMsg string) {
+	j.History = append(j.History, AttemptRecord{
+		Attempt:   j.Attempts + 1,
+		Command:   j.CommandForAttempt(),
+		StartedAt: startedAt,
+		Error:     errMsg,
+	})
+}
+
+// RetryDelay returns the delay before the next retry from the job's
+// retry_schedule, reusing the last entry once the schedule runs out.
+// It reports false if the job has no schedule.
+func (j *Job) RetryDelay() (time.Duration, bool) {
+	if len(j.RetrySchedule) == 0 {
+		return 0, false
+	}
+	i := j.Attempts
+	if i >= len(j.RetrySchedule) {
+		i = len(j.RetrySchedule) - 1
+	}
+	return time.Duration(j.RetrySchedule[i]), true
+}
+
 // CanRetry checks if the job can be retried
 func (j *Job) CanRetry() bool {
 	return j.Attempts < j.MaxRetries
 }
 
+// CanRetryAfter checks if the job can be retried after a failure of the given type
+func (j *Job) CanRetryAfter(errType ErrorType) bool {
+	if j.RetryOnTimeoutOnly && errType != ErrorTypeTimeout {
+		return false
+	}
+	return j.CanRetry()
+}
+
 // ShouldRetryNow checks if the job should be retried now
 func (j *Job) ShouldRetryNow() bool {
 	if j.NextRetryAt == nil {
@@ -113,14 +428,17 @@ func (j *Job) ShouldRetryNow() bool {
 func (j *Job) MarkAsProcessing(workerID string) {
 	j.State = StateProcessing
 	j.WorkerID = workerID
+	j.Progress = 0
 	j.UpdatedAt = time.Now()
 }
 
 // MarkAsCompleted marks the job as successfully completed
 func (j *Job) MarkAsCompleted(output string) {
+	now := time.Now()
 	j.State = StateCompleted
 	j.Output = output
-	j.UpdatedAt = time.Now()
+	j.CompletedAt = &now
+	j.UpdatedAt = now
 }
 
 // MarkAsFailed marks the job as failed and increments attempts
@@ -141,12 +459,164 @@ func (j *Job) MarkAsDead(errMsg string) {
 	j.WorkerID = ""
 }
 
+// MarkAsCancelled marks the job as cancelled by an operator. It is never
+// claimed or retried again.
+func (j *Job) MarkAsCancelled() {
+	j.State = StateCancelled
+	j.NextRetryAt = nil
+	j.ScheduledAt = nil
+	j.HeldUntil = nil
+	j.WorkerID = ""
+	j.UpdatedAt = time.Now()
+}
+
+// Requeue returns a processing job whose worker stopped responding to
+// pending, keeping its attempt count, so another worker can run it
+func (j *Job) Requeue(reason string) {
+	j.State = StatePending
+	j.Error = reason
+	j.WorkerID = ""
+	j.UpdatedAt = time.Now()
+}
+
+// Hold takes the job out of rotation until the given time, after which
+// storage returns it to pending
+func (j *Job) Hold(until time.Time) {
+	j.State = StateHeld
+	j.HeldUntil = &until
+	j.UpdatedAt = time.Now()
+}
+
+// Release returns a held job to pending
+func (j *Job) Release() {
+	j.State = StatePending
+	j.HeldUntil = nil
+	j.UpdatedAt = time.Now()
+}
+
+// NewRetry returns a fresh pending job with the same spec as j and a
+// RetryOf link back to it
+func (j *Job) NewRetry() *Job {
+	now := time.Now()
+	retry := *j
+	retry.ID = uuid.New().String()
+	retry.Seq = 0
+	retry.RetryOf = j.ID
+	retry.State = StatePending
+	retry.Attempts = 0
+	retry.CreatedAt = now
+	retry.UpdatedAt = now
+	retry.NextRetryAt = nil
+	retry.ScheduledAt = nil
+	retry.HeldUntil = nil
+	retry.CompletedAt = nil
+	retry.WorkerID = ""
+	retry.Error = ""
+	retry.ErrorType = ""
+	retry.Output = ""
+	retry.ExitCode = 0
+	retry.CPUTimeMS = 0
+	retry.MaxRSSKB = 0
+	retry.DurationMS = 0
+	retry.Progress = 0
+	retry.History = nil
+	return &retry
+}
+
+// HasTag reports whether the job carries tag
+func (j *Job) HasTag(tag string) bool {
+	for _, t := range j.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// RetryNow makes a failed job due for its next retry immediately instead
+// of after its backoff
+func (j *Job) RetryNow() {
+	now := time.Now()
+	j.NextRetryAt = &now
+	j.UpdatedAt = now
+}
+
+// Archive marks a dead job as superseded by a retry job
+func (j *Job) Archive() {
+	j.State = StateArchived
+	j.UpdatedAt = time.Now()
+}
+
 // ResetForRetry resets the job to pending state for retry from DLQ
 func (j *Job) ResetForRetry() {
 	j.State = StatePending
 	j.Attempts = 0
 	j.Error = ""
+	j.ErrorType = ""
+	j.ExitCode = 0
 	j.NextRetryAt = nil
+	j.ScheduledAt = nil
+	j.HeldUntil = nil
+	j.CompletedAt = nil
 	j.WorkerID = ""
 	j.UpdatedAt = time.Now()
-}
\ No newline at end of file
+}
+
+// Normalize applies defaulting and clears stale derived fields on a stored job.
+// It returns the names of the fields that were changed.
+func (j *Job) Normalize() []string {
+	var changed []string
+
+	if j.State == "" {
+		j.State = StatePending
+		changed = append(changed, "state")
+	}
+	if j.Queue == "" {
+		j.Queue = DefaultQueue
+		changed = append(changed, "queue")
+	}
+	if j.Attempts < 0 {
+		j.Attempts = 0
+		changed = append(changed, "attempts")
+	}
+	if j.CreatedAt.IsZero() {
+		j.CreatedAt = time.Now()
+		changed = append(changed, "created_at")
+	}
+	if j.UpdatedAt.IsZero() || j.UpdatedAt.Before(j.CreatedAt) {
+		j.UpdatedAt = j.CreatedAt
+		changed = append(changed, "updated_at")
+	}
+	// Only failed jobs wait for a retry, and only processing jobs have an owner
+	if j.NextRetryAt != nil && j.State != StateFailed {
+		j.NextRetryAt = nil
+		changed = append(changed, "next_retry_at")
+	}
+	if j.ScheduledAt != nil && j.State != StatePending {
+		j.ScheduledAt = nil
+		changed = append(changed, "scheduled_at")
+	}
+	if j.HeldUntil == nil && j.State == StateHeld {
+		j.State = StatePending
+		changed = append(changed, "state")
+	}
+	if j.HeldUntil != nil && j.State != StateHeld {
+		j.HeldUntil = nil
+		changed = append(changed, "held_until")
+	}
+	if j.CompletedAt == nil && j.State == StateCompleted {
+		completedAt := j.UpdatedAt
+		j.CompletedAt = &completedAt
+		changed = append(changed, "completed_at")
+	}
+	if j.CompletedAt != nil && j.State != StateCompleted {
+		j.CompletedAt = nil
+		changed = append(changed, "completed_at")
+	}
+	if j.WorkerID != "" && j.State != StateProcessing {
+		j.WorkerID = ""
+		changed = append(changed, "worker_id")
+	}
+
+	return changed
+}
@@ -0,0 +1,39 @@
+package job
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSpawnNextTruncatesOnCharacterBoundary(t *testing.T) {
+	parent := NewJob("echo parent", 0)
+	parent.NextJob = &Job{Command: "echo next"}
+
+	tests := []struct {
+		name   string
+		output string
+		want   int
+	}{
+		{"ascii at limit", strings.Repeat("a", maxParentOutput), maxParentOutput},
+		{"ascii over limit", strings.Repeat("a", maxParentOutput+1), maxParentOutput},
+		// "é" is 2 bytes; the last one would straddle the limit
+		{"two-byte across limit", strings.Repeat("a", maxParentOutput-1) + "é", maxParentOutput - 1},
+		// "日" is 3 bytes; the limit falls after its first byte
+		{"three-byte across limit", strings.Repeat("a", maxParentOutput-1) + "日本", maxParentOutput - 1},
+		// "😀" is 4 bytes and ends exactly at the limit
+		{"four-byte ending at limit", strings.Repeat("a", maxParentOutput-4) + "😀x", maxParentOutput},
+	}
+	for _, tt := range tests {
+		next := parent.SpawnNext(tt.output)
+		if got := len(next.ParentOutput); got != tt.want {
+			t.Errorf("%s: kept %d bytes, want %d", tt.name, got, tt.want)
+		}
+		if !utf8.ValidString(next.ParentOutput) {
+			t.Errorf("%s: parent output is not valid UTF-8", tt.name)
+		}
+		if !strings.HasPrefix(tt.output, next.ParentOutput) {
+			t.Errorf("%s: parent output is not a prefix of the output", tt.name)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package job
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Schema is a compiled JSON Schema that enqueued job JSON must conform to
+type Schema struct {
+	schema *jsonschema.Schema
+}
+
+// LoadSchema compiles the JSON Schema file at path. References to other
+// files are resolved relative to it.
+func LoadSchema(path string) (*Schema, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid job schema path: %w", err)
+	}
+
+	schema, err := jsonschema.Compile(abs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load job schema %s: %w", path, err)
+	}
+	return &Schema{schema: schema}, nil
+}
+
+// Validate checks the raw job JSON against the schema. The error lists
+// every violation with the location of the offending value.
+func (s *Schema) Validate(jsonStr string) error {
+	dec := json.NewDecoder(bytes.NewReader([]byte(jsonStr)))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Errorf("invalid job JSON: %w", err)
+	}
+
+	err := s.schema.Validate(v)
+	var verr *jsonschema.ValidationError
+	if !errors.As(err, &verr) {
+		return err
+	}
+
+	var lines []string
+	var collect func(*jsonschema.ValidationError)
+	collect = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			location := e.InstanceLocation
+			if location == "" {
+				location = "(root)"
+			}
+			lines = append(lines, fmt.Sprintf("  %s: %s", location, e.Message))
+		}
+		for _, cause := range e.Causes {
+			collect(cause)
+		}
+	}
+	collect(verr)
+
+	return fmt.Errorf("job does not match the job schema:\n%s", strings.Join(lines, "\n"))
+}
+
+// ValidateJob checks a stored job against the schema, as the JSON object
+// 'queuectl export' writes for it
+func (s *Schema) ValidateJob(j *Job) error {
+	data, err := json.Marshal(j)
+	if err != nil {
+		return fmt.Errorf("failed to encode job %s: %w", j.ID, err)
+	}
+	return s.Validate(string(data))
+}
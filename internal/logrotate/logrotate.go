@@ -0,0 +1,137 @@
+package logrotate
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Writer is an io.Writer that writes to a file and rotates it once it
+// grows past MaxSize bytes. Rotated segments are gzip-compressed and named
+// <path>.1.gz (newest) through <path>.<MaxBackups>.gz (oldest).
+type Writer struct {
+	Path       string
+	MaxSize    int64
+	MaxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// New creates a rotating writer for the given file
+func New(path string, maxSize int64, maxBackups int) *Writer {
+	return &Writer{
+		Path:       path,
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+	}
+}
+
+// Write appends p to the log file, rotating first if it would exceed MaxSize
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if w.MaxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.MaxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close closes the underlying file
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// open opens the log file for appending and records its current size
+func (w *Writer) open() error {
+	f, err := os.OpenFile(w.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	w.file = f
+	w.size = info.Size()
+	return nil
+}
+
+// rotate closes the current file, shifts the compressed backups and
+// starts a fresh log file
+func (w *Writer) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	w.file = nil
+
+	if w.MaxBackups > 0 {
+		// Drop the oldest backup and shift the rest up by one
+		os.Remove(w.backupName(w.MaxBackups))
+		for i := w.MaxBackups - 1; i >= 1; i-- {
+			os.Rename(w.backupName(i), w.backupName(i+1))
+		}
+		if err := compressFile(w.Path, w.backupName(1)); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Remove(w.Path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove rotated log file: %w", err)
+	}
+
+	return w.open()
+}
+
+// backupName returns the file name of the n-th compressed backup
+func (w *Writer) backupName(n int) string {
+	return fmt.Sprintf("%s.%d.gz", w.Path, n)
+}
+
+// compressFile gzips src into dst
+func compressFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open log file for compression: %w", err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("failed to create log backup: %w", err)
+	}
+	defer out.Close()
+
+	gz := gzip.NewWriter(out)
+	if _, err := io.Copy(gz, in); err != nil {
+		return fmt.Errorf("failed to compress log file: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to compress log file: %w", err)
+	}
+	return nil
+}
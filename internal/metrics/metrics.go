@@ -0,0 +1,5 @@
+package metrics
+
+// durationBounds are the histogram bucket upper bounds for job durations,
+// in seconds
+var durationBounds = []float64{0.1, 0.5, 1, 5, 10, 30, 60, 300, 900}
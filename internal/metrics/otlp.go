@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// ExportInterval is how often the OTLP exporter pushes metrics
+const ExportInterval = 15 * time.Second
+
+// exportTimeout bounds each push, retries included
+const exportTimeout = 10 * time.Second
+
+// OTLPExporter records job metrics with the OpenTelemetry SDK and pushes
+// them to a collector over OTLP/HTTP every ExportInterval. It is safe for
+// concurrent use by multiple workers.
+type OTLPExporter struct {
+	URL      string
+	provider *sdkmetric.MeterProvider
+	meter    metric.Meter
+
+	processed metric.Int64Counter
+	duration  metric.Float64Histogram
+}
+
+// NewOTLPExporter creates an exporter for the collector at endpoint. The
+// endpoint may be the collector's base URL (e.g. http://localhost:4318) or
+// the full /v1/metrics URL. Pushes start at once and run until Shutdown.
+func NewOTLPExporter(endpoint string) (*OTLPExporter, error) {
+	url := strings.TrimRight(endpoint, "/")
+	if !strings.HasSuffix(url, "/v1/metrics") {
+		url += "/v1/metrics"
+	}
+
+	exp, err := otlpmetrichttp.New(context.Background(),
+		otlpmetrichttp.WithEndpointURL(url),
+		otlpmetrichttp.WithTimeout(exportTimeout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithResource(resource.NewSchemaless(attribute.String("service.name", "queuectl"))),
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exp, sdkmetric.WithInterval(ExportInterval))),
+	)
+
+	e := &OTLPExporter{
+		URL:      url,
+		provider: provider,
+		meter:    provider.Meter("github.com/MithileshwaranS/queuectl"),
+	}
+	e.processed, err = e.meter.Int64Counter("queuectl.jobs.processed",
+		metric.WithDescription("Job attempts finished, by resulting state"),
+		metric.WithUnit("{job}"))
+	if err == nil {
+		e.duration, err = e.meter.Float64Histogram("queuectl.job.duration",
+			metric.WithDescription("Job attempt run time"),
+			metric.WithUnit("s"),
+			metric.WithExplicitBucketBoundaries(durationBounds...))
+	}
+	if err != nil {
+		provider.Shutdown(context.Background())
+		return nil, fmt.Errorf("failed to create metrics: %w", err)
+	}
+	return e, nil
+}
+
+// JobFinished records one attempt that ended in the given state
+// (completed, failed or dead) after running for duration
+func (e *OTLPExporter) JobFinished(state job.State, duration time.Duration) {
+	ctx := context.Background()
+	e.processed.Add(ctx, 1, metric.WithAttributes(attribute.String("state", string(state))))
+	e.duration.Record(ctx, duration.Seconds())
+}
+
+// ObserveDLQSize reports the DLQ size read by size with every push. A
+// failed read skips the gauge for that push.
+func (e *OTLPExporter) ObserveDLQSize(size func() (int, error)) error {
+	_, err := e.meter.Int64ObservableGauge("queuectl.dlq.size",
+		metric.WithDescription("Jobs in the dead letter queue"),
+		metric.WithUnit("{job}"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			n, err := size()
+			if err != nil {
+				return err
+			}
+			o.Observe(int64(n))
+			return nil
+		}))
+	return err
+}
+
+// OnError sets where errors of background pushes are reported. The
+// OpenTelemetry SDK has one error handler per process, so the last call
+// wins.
+func (e *OTLPExporter) OnError(f func(error)) {
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(f))
+}
+
+// Shutdown pushes the final measurements and stops the exporter
+func (e *OTLPExporter) Shutdown(ctx context.Context) error {
+	return e.provider.Shutdown(ctx)
+}
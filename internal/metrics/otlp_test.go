@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeCollector records the metrics pushed to it, by name
+type fakeCollector struct {
+	mu      sync.Mutex
+	paths   []string
+	metrics map[string]*metricspb.Metric
+}
+
+func (c *fakeCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var req collectorpb.ExportMetricsServiceRequest
+	if err := proto.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.paths = append(c.paths, r.URL.Path)
+	for _, rm := range req.ResourceMetrics {
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				c.metrics[m.Name] = m
+			}
+		}
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Write(nil)
+}
+
+func TestOTLPExporterPushesOnShutdown(t *testing.T) {
+	collector := &fakeCollector{metrics: make(map[string]*metricspb.Metric)}
+	srv := httptest.NewServer(collector)
+	defer srv.Close()
+
+	e, err := NewOTLPExporter(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.URL != srv.URL+"/v1/metrics" {
+		t.Errorf("URL = %s, want %s/v1/metrics", e.URL, srv.URL)
+	}
+	if err := e.ObserveDLQSize(func() (int, error) { return 2, nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	e.JobFinished(job.StateCompleted, 200*time.Millisecond)
+	e.JobFinished(job.StateCompleted, 2*time.Second)
+	e.JobFinished(job.StateDead, 50*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := e.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	if len(collector.paths) == 0 || collector.paths[0] != "/v1/metrics" {
+		t.Fatalf("pushes to %v, want /v1/metrics", collector.paths)
+	}
+
+	processed := collector.metrics["queuectl.jobs.processed"]
+	if processed == nil {
+		t.Fatal("queuectl.jobs.processed not pushed")
+	}
+	byState := make(map[string]int64)
+	for _, p := range processed.GetSum().GetDataPoints() {
+		for _, a := range p.Attributes {
+			if a.Key == "state" {
+				byState[a.Value.GetStringValue()] = p.GetAsInt()
+			}
+		}
+	}
+	if byState["completed"] != 2 || byState["dead"] != 1 {
+		t.Errorf("processed by state = %v, want completed=2 dead=1", byState)
+	}
+
+	duration := collector.metrics["queuectl.job.duration"]
+	if duration == nil {
+		t.Fatal("queuectl.job.duration not pushed")
+	}
+	points := duration.GetHistogram().GetDataPoints()
+	if len(points) != 1 || points[0].Count != 3 {
+		t.Fatalf("duration points = %v, want one with count 3", points)
+	}
+	if got := points[0].ExplicitBounds; len(got) != len(durationBounds) {
+		t.Errorf("duration bounds = %v, want %v", got, durationBounds)
+	}
+
+	dlq := collector.metrics["queuectl.dlq.size"]
+	if dlq == nil {
+		t.Fatal("queuectl.dlq.size not pushed")
+	}
+	if g := dlq.GetGauge().GetDataPoints(); len(g) != 1 || g[0].GetAsInt() != 2 {
+		t.Errorf("dlq size points = %v, want 2", g)
+	}
+}
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// PromRecorder counts finished job attempts in Prometheus collectors
+// registered with the default registry
+type PromRecorder struct {
+	processed prometheus.Counter
+	failures  prometheus.Counter
+	retries   prometheus.Counter
+	dlq       prometheus.Counter
+	duration  prometheus.Histogram
+}
+
+// NewPromRecorder creates the job attempt collectors and registers them
+// with the default Prometheus registry. It must only be called once.
+func NewPromRecorder() *PromRecorder {
+	r := &PromRecorder{
+		processed: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "queuectl_jobs_processed_total",
+			Help: "Job attempts finished by this process, successful or not.",
+		}),
+		failures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "queuectl_job_failures_total",
+			Help: "Job attempts that failed.",
+		}),
+		retries: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "queuectl_job_retries_total",
+			Help: "Failed job attempts that were scheduled for a retry.",
+		}),
+		dlq: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "queuectl_dlq_entries_total",
+			Help: "Jobs moved to the dead letter queue.",
+		}),
+		duration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "queuectl_job_duration_seconds",
+			Help:    "Run time of finished job attempts.",
+			Buckets: durationBounds,
+		}),
+	}
+	prometheus.MustRegister(r.processed, r.failures, r.retries, r.dlq, r.duration)
+	return r
+}
+
+// JobFinished records one attempt that ended in the given state
+// (completed, failed or dead) after running for duration
+func (r *PromRecorder) JobFinished(state job.State, duration time.Duration) {
+	r.processed.Inc()
+	r.duration.Observe(duration.Seconds())
+
+	switch state {
+	case job.StateFailed:
+		r.failures.Inc()
+		r.retries.Inc()
+	case job.StateDead:
+		r.failures.Inc()
+		r.dlq.Inc()
+	}
+}
+
+// jobStatesCollector reports the number of jobs in each state, read from
+// storage at scrape time
+type jobStatesCollector struct {
+	stats func() (map[job.State]int, error)
+	desc  *prometheus.Desc
+}
+
+func (c *jobStatesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *jobStatesCollector) Collect(ch chan<- prometheus.Metric) {
+	stats, err := c.stats()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+	for _, state := range job.AllStates {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(stats[state]), string(state))
+	}
+}
+
+// RegisterJobStates registers a queuectl_jobs gauge, labelled by state,
+// whose values are fetched with stats (e.g. Storage.GetJobStats) on every
+// scrape
+func RegisterJobStates(stats func() (map[job.State]int, error)) {
+	prometheus.MustRegister(&jobStatesCollector{
+		stats: stats,
+		desc:  prometheus.NewDesc("queuectl_jobs", "Jobs in the queue by state.", []string{"state"}, nil),
+	})
+}
+
+// ServePrometheus serves the default registry at /metrics on addr in the
+// background. Close the returned server to stop it.
+func ServePrometheus(addr string) (*http.Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(ln)
+
+	return srv, nil
+}
@@ -0,0 +1,115 @@
+package notify
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+)
+
+// EmailNotifier sends plain-text notification emails over SMTP
+type EmailNotifier struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// Notify emails a description of the job transition to every recipient
+func (e *EmailNotifier) Notify(j *job.Job, event Event) error {
+	addr := net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
+
+	var auth smtp.Auth
+	if e.Username != "" {
+		auth = smtp.PlainAuth("", e.Username, e.Password, e.Host)
+	}
+
+	if err := sendMail(addr, e.Host, auth, e.From, e.To, e.message(j, event)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// emailTimeout bounds the whole SMTP exchange, so an unresponsive server
+// cannot stall the worker reporting the job
+const emailTimeout = 10 * time.Second
+
+// sendMail does what smtp.SendMail does, with the connection and every
+// step after it bounded by emailTimeout
+func sendMail(addr, host string, auth smtp.Auth, from string, to []string, msg []byte) error {
+	conn, err := net.DialTimeout("tcp", addr, emailTimeout)
+	if err != nil {
+		return err
+	}
+	if err := conn.SetDeadline(time.Now().Add(emailTimeout)); err != nil {
+		conn.Close()
+		return err
+	}
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if ok, _ := c.Extension("AUTH"); ok {
+			if err := c.Auth(auth); err != nil {
+				return err
+			}
+		}
+	}
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := c.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// message builds the RFC 5322 message for the job transition
+func (e *EmailNotifier) message(j *job.Job, event Event) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", e.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", subject(j, event))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+
+	fmt.Fprintf(&b, "Job ID:   %s\r\n", j.ID)
+	fmt.Fprintf(&b, "Command:  %s\r\n", j.Command)
+	fmt.Fprintf(&b, "State:    %s\r\n", j.State)
+	fmt.Fprintf(&b, "Attempts: %d/%d\r\n", j.Attempts, j.MaxRetries)
+	if j.Error != "" {
+		fmt.Fprintf(&b, "\r\nError:\r\n%s\r\n", strings.ReplaceAll(truncate(j.Error, 4000), "\n", "\r\n"))
+	}
+	return []byte(b.String())
+}
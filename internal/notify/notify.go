@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MithileshwaranS/queuectl/internal/config"
+	"github.com/MithileshwaranS/queuectl/internal/job"
+)
+
+// Event identifies the job transition a notification is sent for
+type Event string
+
+const (
+	EventCompleted Event = "completed" // Job finished successfully
+	EventDead      Event = "dead"      // Job was moved to the dead letter queue
+)
+
+// Supported values for the notifier config key
+const (
+	BackendNone  = "none"
+	BackendSlack = "slack"
+	BackendEmail = "email"
+)
+
+// Notifier delivers notifications about job transitions
+type Notifier interface {
+	Notify(j *job.Job, event Event) error
+}
+
+// ValidateBackend checks that name is a supported notifier backend
+func ValidateBackend(name string) error {
+	switch name {
+	case "", BackendNone, BackendSlack, BackendEmail:
+		return nil
+	default:
+		return fmt.Errorf("invalid notifier: %s (valid: none, slack, email)", name)
+	}
+}
+
+// New creates the notifier selected by the configuration. It returns nil
+// when notifications are disabled.
+func New(cfg *config.Config) (Notifier, error) {
+	switch cfg.Notifier {
+	case "", BackendNone:
+		return nil, nil
+	case BackendSlack:
+		if cfg.SlackWebhookURL == "" {
+			return nil, fmt.Errorf("notifier is slack but slack-webhook-url is not set")
+		}
+		return NewSlackNotifier(cfg.SlackWebhookURL), nil
+	case BackendEmail:
+		if cfg.SMTPHost == "" || cfg.SMTPFrom == "" || cfg.SMTPTo == "" {
+			return nil, fmt.Errorf("notifier is email but smtp-host, smtp-from and smtp-to must all be set")
+		}
+		var to []string
+		for _, addr := range strings.Split(cfg.SMTPTo, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				to = append(to, addr)
+			}
+		}
+		return &EmailNotifier{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+			To:       to,
+		}, nil
+	default:
+		return nil, ValidateBackend(cfg.Notifier)
+	}
+}
+
+// subject returns a one-line summary of the event
+func subject(j *job.Job, event Event) string {
+	switch event {
+	case EventDead:
+		return fmt.Sprintf("queuectl: job %s moved to DLQ", j.ID)
+	case EventCompleted:
+		return fmt.Sprintf("queuectl: job %s completed", j.ID)
+	default:
+		return fmt.Sprintf("queuectl: job %s %s", j.ID, event)
+	}
+}
+
+// truncate shortens s to at most n bytes for inclusion in a message
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
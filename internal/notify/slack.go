@@ -0,0 +1,63 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+)
+
+// SlackNotifier posts formatted messages to a Slack incoming webhook
+type SlackNotifier struct {
+	WebhookURL string
+	client     *http.Client
+}
+
+// NewSlackNotifier creates a notifier for the given incoming webhook URL
+func NewSlackNotifier(webhookURL string) *SlackNotifier {
+	return &SlackNotifier{
+		WebhookURL: webhookURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Notify posts a message describing the job transition
+func (s *SlackNotifier) Notify(j *job.Job, event Event) error {
+	payload, err := json.Marshal(map[string]string{"text": slackMessage(j, event)})
+	if err != nil {
+		return fmt.Errorf("failed to encode slack message: %w", err)
+	}
+
+	resp, err := s.client.Post(s.WebhookURL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to post to slack: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// slackMessage renders the job as Slack mrkdwn
+func slackMessage(j *job.Job, event Event) string {
+	icon := ":white_check_mark:"
+	if event == EventDead {
+		icon = ":x:"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s *%s*\n", icon, subject(j, event))
+	fmt.Fprintf(&b, "*Job ID:* `%s`\n", j.ID)
+	fmt.Fprintf(&b, "*Command:* `%s`\n", j.Command)
+	fmt.Fprintf(&b, "*Attempts:* %d/%d", j.Attempts, j.MaxRetries)
+	if j.Error != "" {
+		fmt.Fprintf(&b, "\n*Error:*\n```%s```", truncate(j.Error, 1000))
+	}
+	return b.String()
+}
@@ -2,12 +2,16 @@ package retry
 
 import (
 	"math"
+	"math/rand"
 	"time"
 )
 
+// DefaultMaxDelay caps the backoff delay when no positive cap is given
+const DefaultMaxDelay = time.Hour
+
 // CalculateBackoff calculates the exponential backoff delay
-// Formula: delay = base^attempts seconds
-func CalculateBackoff(attempts int, base float64) time.Duration {
+// Formula: delay = base^attempts seconds, capped at maxDelay
+func CalculateBackoff(attempts int, base float64, maxDelay time.Duration) time.Duration {
 	if attempts < 0 {
 		attempts = 0
 	}
@@ -18,23 +22,40 @@ func CalculateBackoff(attempts int, base float64) time.Duration {
 	// Calculate delay in seconds
 	delaySeconds := math.Pow(base, float64(attempts))
 
-	// Cap at reasonable maximum (e.g., 1 hour)
-	const maxDelaySeconds = 3600
-	if delaySeconds > maxDelaySeconds {
-		delaySeconds = maxDelaySeconds
+	// Cap at the configured maximum, compared in seconds so huge
+	// exponents cannot overflow the Duration
+	if maxDelay <= 0 {
+		maxDelay = DefaultMaxDelay
+	}
+	if delaySeconds > maxDelay.Seconds() {
+		return maxDelay
 	}
 
 	return time.Duration(delaySeconds) * time.Second
 }
 
+// ApplyJitter randomly shortens delay by up to the given fraction of it,
+// so jobs that failed together do not all retry at the same instant.
+// The result lies in [delay*(1-jitter), delay]: 1 gives full jitter, 0.5
+// equal jitter, and 0 (or less) returns delay unchanged.
+func ApplyJitter(delay time.Duration, jitter float64) time.Duration {
+	if jitter <= 0 || delay <= 0 {
+		return delay
+	}
+	if jitter > 1 {
+		jitter = 1
+	}
+	return delay - time.Duration(rand.Float64()*jitter*float64(delay))
+}
+
 // NextRetryTime calculates when the next retry should occur
-func NextRetryTime(attempts int, base float64) time.Time {
-	delay := CalculateBackoff(attempts, base)
+func NextRetryTime(attempts int, base, jitter float64, maxDelay time.Duration) time.Time {
+	delay := ApplyJitter(CalculateBackoff(attempts, base, maxDelay), jitter)
 	return time.Now().Add(delay)
 }
 
 // GetNextRetryAt returns a pointer to the next retry time
-func GetNextRetryAt(attempts int, base float64) *time.Time {
-	t := NextRetryTime(attempts, base)
+func GetNextRetryAt(attempts int, base, jitter float64, maxDelay time.Duration) *time.Time {
+	t := NextRetryTime(attempts, base, jitter, maxDelay)
 	return &t
 }
@@ -0,0 +1,96 @@
+package retry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestApplyJitterBounds(t *testing.T) {
+	delay := 8 * time.Second
+	for _, jitter := range []float64{0.1, 0.5, 1} {
+		lowest := delay - time.Duration(jitter*float64(delay))
+		for i := 0; i < 1000; i++ {
+			got := ApplyJitter(delay, jitter)
+			if got < lowest || got > delay {
+				t.Fatalf("jitter %g: got %s, want within [%s, %s]", jitter, got, lowest, delay)
+			}
+		}
+	}
+}
+
+func TestApplyJitterAboveOneIsFull(t *testing.T) {
+	delay := 8 * time.Second
+	for i := 0; i < 1000; i++ {
+		if got := ApplyJitter(delay, 3); got < 0 || got > delay {
+			t.Fatalf("got %s, want within [0, %s]", got, delay)
+		}
+	}
+}
+
+func TestApplyJitterDisabled(t *testing.T) {
+	delay := 8 * time.Second
+	for _, jitter := range []float64{0, -0.5} {
+		if got := ApplyJitter(delay, jitter); got != delay {
+			t.Errorf("jitter %g: got %s, want %s unchanged", jitter, got, delay)
+		}
+	}
+	if got := ApplyJitter(0, 1); got != 0 {
+		t.Errorf("zero delay: got %s, want 0", got)
+	}
+}
+
+func TestNextRetryTimeWithoutJitter(t *testing.T) {
+	before := time.Now()
+	got := NextRetryTime(3, 2, 0, 0)
+	after := time.Now()
+
+	// Without jitter the delay is exactly base^attempts
+	want := 8 * time.Second
+	if got.Before(before.Add(want)) || got.After(after.Add(want)) {
+		t.Errorf("got %s after now, want %s", got.Sub(before), want)
+	}
+}
+
+func TestCalculateBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempts int
+		base     float64
+		maxDelay time.Duration
+		want     time.Duration
+	}{
+		{"first attempt", 0, 2, time.Hour, time.Second},
+		{"grows with attempts", 3, 2, time.Hour, 8 * time.Second},
+		{"other base", 2, 3, time.Hour, 9 * time.Second},
+		{"base below 1 uses 2", 3, 0.5, time.Hour, 8 * time.Second},
+		{"negative attempts", -4, 2, time.Hour, time.Second},
+		{"just under the cap", 5, 2, 33 * time.Second, 32 * time.Second},
+		{"exactly the cap", 5, 2, 32 * time.Second, 32 * time.Second},
+		{"just over the cap", 6, 2, 32 * time.Second, 32 * time.Second},
+		{"far over the cap", 40, 2, time.Minute, time.Minute},
+		{"exponent overflows", 5000, 2, time.Minute, time.Minute},
+		{"no cap uses the default", 20, 2, 0, DefaultMaxDelay},
+		{"negative cap uses the default", 20, 2, -time.Second, DefaultMaxDelay},
+	}
+	for _, tt := range tests {
+		if got := CalculateBackoff(tt.attempts, tt.base, tt.maxDelay); got != tt.want {
+			t.Errorf("%s: CalculateBackoff(%d, %g, %s) = %s, want %s",
+				tt.name, tt.attempts, tt.base, tt.maxDelay, got, tt.want)
+		}
+	}
+}
+
+func TestCalculateBackoffCapIsMonotonic(t *testing.T) {
+	maxDelay := 10 * time.Minute
+	prev := time.Duration(0)
+	for attempts := 0; attempts < 100; attempts++ {
+		got := CalculateBackoff(attempts, 2, maxDelay)
+		if got < prev || got > maxDelay {
+			t.Fatalf("attempt %d: got %s after %s, cap %s", attempts, got, prev, maxDelay)
+		}
+		prev = got
+	}
+	if prev != maxDelay {
+		t.Errorf("got %s after 100 attempts, want the cap %s", prev, maxDelay)
+	}
+}
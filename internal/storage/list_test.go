@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+)
+
+// testBackends returns an empty, initialized store of each backend that
+// runs without a server
+func testBackends(t *testing.T) map[string]Storage {
+	t.Helper()
+	sqlite, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "queuectl.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlite.Close() })
+
+	backends := map[string]Storage{
+		"memory": NewMemoryStorage(),
+		"sqlite": sqlite,
+	}
+	for name, s := range backends {
+		if err := s.Initialize(); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+	return backends
+}
+
+// jobIDs returns the sorted IDs of jobs
+func jobIDs(jobs []*job.Job) []string {
+	ids := make([]string, 0, len(jobs))
+	for _, j := range jobs {
+		ids = append(ids, j.ID)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func TestListJobsUpdatedRange(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local)
+	seed := []struct {
+		id       string
+		updated  time.Time
+		state    job.State
+		queue    string
+		workerID string
+	}{
+		{"a", base.Add(-2 * time.Hour), job.StatePending, "default", ""},
+		{"b", base.Add(-time.Hour), job.StateCompleted, "reports", "w1"},
+		{"c", base, job.StateFailed, "default", "w1"},
+		{"d", base.Add(time.Hour), job.StateCompleted, "default", "w2"},
+	}
+
+	// The same instant as base, written in a zone other than the stored one
+	otherZone := base.In(time.FixedZone("UTC+5:30", 5*3600+1800))
+
+	tests := []struct {
+		name  string
+		state job.State
+		opts  ListOptions
+		want  []string
+	}{
+		{"no filter", "", ListOptions{}, []string{"a", "b", "c", "d"}},
+		{"since", "", ListOptions{UpdatedSince: base.Add(-time.Hour)}, []string{"b", "c", "d"}},
+		{"until", "", ListOptions{UpdatedUntil: base}, []string{"a", "b", "c"}},
+		{"since and until", "", ListOptions{UpdatedSince: base.Add(-time.Hour), UpdatedUntil: base}, []string{"b", "c"}},
+		{"same instant", "", ListOptions{UpdatedSince: base, UpdatedUntil: base}, []string{"c"}},
+		{"since in another zone", "", ListOptions{UpdatedSince: otherZone}, []string{"c", "d"}},
+		{"until in another zone", "", ListOptions{UpdatedUntil: otherZone}, []string{"a", "b", "c"}},
+		{"since and state", job.StateCompleted, ListOptions{UpdatedSince: base.Add(-time.Hour)}, []string{"b", "d"}},
+		{"until and state", job.StateCompleted, ListOptions{UpdatedUntil: base}, []string{"b"}},
+		{"since and worker", "", ListOptions{UpdatedSince: base, WorkerID: "w1"}, []string{"c"}},
+		{"until and worker", "", ListOptions{UpdatedUntil: base, WorkerID: "w1"}, []string{"b", "c"}},
+		{"since and queue", "", ListOptions{UpdatedSince: base.Add(-time.Hour), Queue: "default"}, []string{"c", "d"}},
+		{"until and queue", "", ListOptions{UpdatedUntil: base, Queue: "reports"}, []string{"b"}},
+		{"range, state and worker", job.StateCompleted, ListOptions{UpdatedSince: base.Add(-time.Hour), UpdatedUntil: base.Add(time.Hour), WorkerID: "w2"}, []string{"d"}},
+		{"range and queue", "", ListOptions{UpdatedSince: base.Add(-2 * time.Hour), UpdatedUntil: base, Queue: "default"}, []string{"a", "c"}},
+		{"empty range", "", ListOptions{UpdatedSince: base.Add(2 * time.Hour)}, []string{}},
+	}
+
+	for name, s := range testBackends(t) {
+		for _, sj := range seed {
+			j := job.NewJob("true", 3)
+			j.ID = sj.id
+			j.Queue = sj.queue
+			j.State = sj.state
+			j.WorkerID = sj.workerID
+			j.CreatedAt = sj.updated
+			j.UpdatedAt = sj.updated
+			if err := s.SaveJob(j); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+		}
+
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				jobs, err := s.ListJobs(tt.state, tt.opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := jobIDs(jobs); !slices.Equal(got, tt.want) {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestListOptionsValidateRange(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local)
+	if err := (ListOptions{UpdatedSince: base, UpdatedUntil: base}).Validate(); err != nil {
+		t.Errorf("equal bounds: %v", err)
+	}
+	if err := (ListOptions{UpdatedSince: base, UpdatedUntil: base.Add(-time.Second)}).Validate(); err == nil {
+		t.Error("until before since: expected an error")
+	}
+}
@@ -0,0 +1,958 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+)
+
+// MemoryStorage implements Storage in memory. It mirrors the behaviour of
+// SQLiteStorage, including comparing timestamps at the one second
+// precision they are persisted with, so it can stand in for it in tests.
+// Jobs are copied on the way in and out, so callers never share state
+// with the store.
+type MemoryStorage struct {
+	mu     sync.Mutex
+	jobs   map[string]*job.Job
+	seq    int64
+	paused []pausedQueue
+	stats  []StatsSample
+	// cancels holds the IDs of processing jobs with a cancel request
+	cancels   map[string]bool
+	schedules map[string]*Schedule
+
+	// ageBoostMinutes and scheduler match the SQLiteStorage settings
+	ageBoostMinutes int
+	scheduler       queueScheduler
+}
+
+var _ Storage = (*MemoryStorage)(nil)
+
+// pausedQueue records when a queue was paused
+type pausedQueue struct {
+	name     string
+	pausedAt time.Time
+}
+
+// NewMemoryStorage creates an empty in-memory storage
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		jobs:      make(map[string]*job.Job),
+		cancels:   make(map[string]bool),
+		schedules: make(map[string]*Schedule),
+	}
+}
+
+// Initialize is a no-op; the store is ready once created
+func (m *MemoryStorage) Initialize() error {
+	return nil
+}
+
+// Close is a no-op; the jobs stay available
+func (m *MemoryStorage) Close() error {
+	return nil
+}
+
+// SetAgePriorityBoost configures anti-starvation aging for job claims.
+// A waiting job gains one priority level for every interval it has waited.
+func (m *MemoryStorage) SetAgePriorityBoost(interval time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ageBoostMinutes = int(interval / time.Minute)
+}
+
+// SetQueueWeights enables weighted fair scheduling between queues, as for
+// SQLiteStorage.SetQueueWeights
+func (m *MemoryStorage) SetQueueWeights(weights map[string]int) {
+	m.scheduler.set(weights)
+}
+
+// SaveJob inserts or updates a job. New jobs are assigned the next seq;
+// updates keep the one assigned at insert.
+func (m *MemoryStorage) SaveJob(j *job.Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.checkIdempotencyKey(j, nil); err != nil {
+		return err
+	}
+	m.save(j)
+	return nil
+}
+
+// SaveJobIfState updates a job only if it is still in state
+func (m *MemoryStorage) SaveJobIfState(j *job.Job, state job.State) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	existing, ok := m.jobs[j.ID]
+	if !ok {
+		return fmt.Errorf("failed to get job: %w", sql.ErrNoRows)
+	}
+	if existing.State != state {
+		return &StateError{ID: j.ID, Want: state, State: existing.State}
+	}
+	if err := m.checkIdempotencyKey(j, nil); err != nil {
+		return err
+	}
+	m.save(j)
+	return nil
+}
+
+// InsertJob saves a new job, failing with ErrJobExists if its ID is taken
+func (m *MemoryStorage) InsertJob(j *job.Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.jobs[j.ID]; ok {
+		return fmt.Errorf("job %s: %w", j.ID, ErrJobExists)
+	}
+	if err := m.checkIdempotencyKey(j, nil); err != nil {
+		return err
+	}
+	m.save(j)
+	return nil
+}
+
+// SaveJobs saves every job as SaveJob would, or none of them if any fails
+func (m *MemoryStorage) SaveJobs(jobs []*job.Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, j := range jobs {
+		if err := m.checkIdempotencyKey(j, jobs[:i]); err != nil {
+			return fmt.Errorf("job %s: %w", j.ID, err)
+		}
+	}
+	for _, j := range jobs {
+		m.save(j)
+	}
+	return nil
+}
+
+// InsertJobs saves the jobs whose ID is free, or none of them if any
+// fails, and returns the ones whose ID was taken
+func (m *MemoryStorage) InsertJobs(jobs []*job.Job) ([]*job.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var fresh, taken []*job.Job
+	ids := make(map[string]bool)
+	for _, j := range jobs {
+		if _, ok := m.jobs[j.ID]; ok || ids[j.ID] {
+			taken = append(taken, j)
+			continue
+		}
+		if err := m.checkIdempotencyKey(j, fresh); err != nil {
+			return nil, fmt.Errorf("job %s: %w", j.ID, err)
+		}
+		ids[j.ID] = true
+		fresh = append(fresh, j)
+	}
+	for _, j := range fresh {
+		m.save(j)
+	}
+	return taken, nil
+}
+
+// ReplaceJobs saves the jobs not being processed, or none of them if any
+// fails, and returns the ones that are
+func (m *MemoryStorage) ReplaceJobs(jobs []*job.Job) ([]*job.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var idle, busy []*job.Job
+	for _, j := range jobs {
+		if existing, ok := m.jobs[j.ID]; ok && existing.State == job.StateProcessing {
+			busy = append(busy, j)
+			continue
+		}
+		if err := m.checkIdempotencyKey(j, idle); err != nil {
+			return nil, fmt.Errorf("job %s: %w", j.ID, err)
+		}
+		idle = append(idle, j)
+	}
+	for _, j := range idle {
+		m.save(j)
+	}
+	return busy, nil
+}
+
+// SaveJobIfOwner updates a job only if it is still processing under
+// workerID
+func (m *MemoryStorage) SaveJobIfOwner(j *job.Job, workerID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	existing, ok := m.jobs[j.ID]
+	if !ok || existing.State != job.StateProcessing || existing.WorkerID != workerID {
+		return fmt.Errorf("job %s: %w", j.ID, ErrNotClaimed)
+	}
+	if err := m.checkIdempotencyKey(j, nil); err != nil {
+		return err
+	}
+	m.save(j)
+	return nil
+}
+
+// save stores a copy of j; the caller must hold m.mu
+func (m *MemoryStorage) save(j *job.Job) {
+	saved := cloneJob(j)
+	if existing, ok := m.jobs[j.ID]; ok {
+		saved.Seq = existing.Seq
+	} else {
+		m.seq++
+		saved.Seq = m.seq
+	}
+	m.jobs[j.ID] = saved
+	if saved.State != job.StateProcessing {
+		delete(m.cancels, j.ID)
+	}
+}
+
+// checkIdempotencyKey mirrors the unique index on idempotency_key: an
+// unfinished job cannot share its key with another unfinished job, stored
+// or saved earlier in the same batch. m.mu must be held.
+func (m *MemoryStorage) checkIdempotencyKey(j *job.Job, batch []*job.Job) error {
+	if j.IdempotencyKey == "" || j.IsTerminal() {
+		return nil
+	}
+	holds := func(other *job.Job) bool {
+		return other.ID != j.ID && other.IdempotencyKey == j.IdempotencyKey && !other.IsTerminal()
+	}
+	for _, other := range m.jobs {
+		if holds(other) {
+			return fmt.Errorf("idempotency key %q: %w", j.IdempotencyKey, ErrIdempotencyKeyInUse)
+		}
+	}
+	for _, other := range batch {
+		if holds(other) {
+			return fmt.Errorf("idempotency key %q: %w", j.IdempotencyKey, ErrIdempotencyKeyInUse)
+		}
+	}
+	return nil
+}
+
+// FindByIdempotencyKey returns the newest job holding key, as for
+// SQLiteStorage.FindByIdempotencyKey
+func (m *MemoryStorage) FindByIdempotencyKey(key string, reuseAfter time.Duration) (*job.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cutoff := time.Now().Add(-reuseAfter)
+	var found *job.Job
+	for _, j := range m.jobs {
+		if j.IdempotencyKey != key {
+			continue
+		}
+		recent := j.State == job.StateCompleted && j.CompletedAt != nil && j.CompletedAt.Unix() > cutoff.Unix()
+		if (!j.IsTerminal() || recent) && (found == nil || j.Seq > found.Seq) {
+			found = j
+		}
+	}
+	if found == nil {
+		return nil, nil
+	}
+	return cloneJob(found), nil
+}
+
+// GetJob retrieves a job by ID. Like SQLiteStorage it returns
+// sql.ErrNoRows if there is no such job.
+func (m *MemoryStorage) GetJob(id string) (*job.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return cloneJob(j), nil
+}
+
+// GetNextPendingJob gets the next available job and locks it
+func (m *MemoryStorage) GetNextPendingJob(workerID string, filter ClaimFilter) (*job.Job, error) {
+	return firstClaim(m.GetNextPendingJobs(workerID, filter, 1))
+}
+
+// GetNextPendingJobs claims up to n available jobs. The whole claim
+// happens under the store's lock, so concurrent callers never get the
+// same job. As with SQLiteStorage, the returned jobs are the state they
+// were claimed from.
+func (m *MemoryStorage) GetNextPendingJobs(workerID string, filter ClaimFilter, n int) ([]*job.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+
+	// Return held jobs whose lease has expired to pending
+	for _, j := range m.jobs {
+		if j.State == job.StateHeld && j.HeldUntil != nil && notAfter(*j.HeldUntil, now) {
+			j.State = job.StatePending
+			j.HeldUntil = nil
+			j.UpdatedAt = now
+		}
+	}
+
+	var candidates []*job.Job
+	for _, j := range m.jobs {
+		if m.claimable(j, now) && matchesFilter(j, filter) {
+			candidates = append(candidates, j)
+		}
+	}
+
+	var claimed []*job.Job
+	for _, next := range takeClaims(candidates, n, now, m.ageBoostMinutes, &m.scheduler) {
+		claimed = append(claimed, cloneJob(next))
+		next.State = job.StateProcessing
+		next.WorkerID = workerID
+		next.UpdatedAt = now
+	}
+	return claimed, nil
+}
+
+// PreviewClaimOrder returns up to limit jobs in the order GetNextPendingJob
+// would claim them, without claiming anything. Held jobs whose hold has
+// expired are included since the next claim releases them first. A
+// negative limit returns every candidate.
+func (m *MemoryStorage) PreviewClaimOrder(limit int) ([]ClaimCandidate, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var jobs []*job.Job
+	for _, j := range m.jobs {
+		expiredHold := j.State == job.StateHeld && j.HeldUntil != nil &&
+			notAfter(*j.HeldUntil, now) && !m.isPaused(j.Queue)
+		if m.claimable(j, now) || expiredHold {
+			jobs = append(jobs, j)
+		}
+	}
+	sortClaimOrder(jobs, now, m.ageBoostMinutes)
+
+	if limit >= 0 && len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	candidates := make([]ClaimCandidate, len(jobs))
+	for i, j := range jobs {
+		candidates[i] = ClaimCandidate{Job: cloneJob(j), EffectivePriority: agedPriority(j, now, m.ageBoostMinutes)}
+	}
+	return candidates, nil
+}
+
+// ListJobs returns jobs filtered by state, ordered and paged by opts
+func (m *MemoryStorage) ListJobs(state job.State, opts ListOptions) ([]*job.Job, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var jobs []*job.Job
+	for _, j := range m.jobs {
+		if (state == "" || j.State == state) && matchesListOptions(j, opts) {
+			jobs = append(jobs, cloneJob(j))
+		}
+	}
+	return sortAndPage(jobs, opts), nil
+}
+
+// sortAndPage orders jobs as listSortColumns does, breaking ties by seq,
+// and returns the page opts selects
+func sortAndPage(jobs []*job.Job, opts ListOptions) []*job.Job {
+	sort.Slice(jobs, func(a, b int) bool {
+		ka, kb := listSortKey(jobs[a], opts.SortBy), listSortKey(jobs[b], opts.SortBy)
+		if ka == kb {
+			ka, kb = jobs[a].Seq, jobs[b].Seq
+		}
+		if opts.Ascending {
+			return ka < kb
+		}
+		return ka > kb
+	})
+
+	if opts.Offset >= len(jobs) {
+		return nil
+	}
+	jobs = jobs[opts.Offset:]
+	if opts.Limit > 0 && len(jobs) > opts.Limit {
+		jobs = jobs[:opts.Limit]
+	}
+	return jobs
+}
+
+// matchesListOptions mirrors the filters SQLiteStorage.ListJobs applies
+// besides state. Times compare at second precision.
+func matchesListOptions(j *job.Job, opts ListOptions) bool {
+	if opts.Tag != "" && !j.HasTag(opts.Tag) {
+		return false
+	}
+	if opts.Queue != "" && j.Queue != opts.Queue {
+		return false
+	}
+	if !opts.UpdatedSince.IsZero() && j.UpdatedAt.Unix() < opts.UpdatedSince.Unix() {
+		return false
+	}
+	if !opts.UpdatedUntil.IsZero() && j.UpdatedAt.Unix() > opts.UpdatedUntil.Unix() {
+		return false
+	}
+	return opts.WorkerID == "" || j.WorkerID == opts.WorkerID
+}
+
+// listSortKey returns the value ListJobs sorts j by, mirroring
+// listSortColumns. Times compare at second precision.
+func listSortKey(j *job.Job, sortBy string) int64 {
+	switch sortBy {
+	case SortUpdated:
+		return j.UpdatedAt.Unix()
+	case SortPriority:
+		return int64(j.Priority)
+	case SortAttempts:
+		return int64(j.Attempts)
+	default:
+		return j.CreatedAt.Unix()
+	}
+}
+
+// GetJobStats returns job counts by state
+func (m *MemoryStorage) GetJobStats() (map[job.State]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.countStates(), nil
+}
+
+// GetQueueStats returns job counts by state for each queue
+func (m *MemoryStorage) GetQueueStats() (map[string]map[job.State]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make(map[string]map[job.State]int)
+	for _, j := range m.jobs {
+		if stats[j.Queue] == nil {
+			stats[j.Queue] = make(map[job.State]int)
+		}
+		stats[j.Queue][j.State]++
+	}
+	return stats, nil
+}
+
+// CountFailedAttempts returns the sum of every stored job's attempts
+func (m *MemoryStorage) CountFailedAttempts() (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n := 0
+	for _, j := range m.jobs {
+		n += j.Attempts
+	}
+	return n, nil
+}
+
+// DeleteJob removes a job. Deleting a missing job is not an error.
+func (m *MemoryStorage) DeleteJob(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.jobs, id)
+	return nil
+}
+
+// DeleteJobIfState removes a job only if it is in state
+func (m *MemoryStorage) DeleteJobIfState(id string, state job.State) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[id]
+	if !ok {
+		return fmt.Errorf("failed to get job: %w", sql.ErrNoRows)
+	}
+	if j.State != state {
+		return &StateError{ID: id, Want: state, State: j.State}
+	}
+	delete(m.jobs, id)
+	return nil
+}
+
+// CancelJob cancels a waiting job, or records a cancel request for a
+// processing one. It reports whether the job was cancelled immediately.
+func (m *MemoryStorage) CancelJob(id string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return false, fmt.Errorf("failed to get job: %w", sql.ErrNoRows)
+	}
+	switch j.State {
+	case job.StatePending, job.StateHeld, job.StateFailed:
+		j.MarkAsCancelled()
+		return true, nil
+	case job.StateProcessing:
+		m.cancels[id] = true
+		return false, nil
+	default:
+		return false, fmt.Errorf("job %s cannot be cancelled (state: %s)", id, j.State)
+	}
+}
+
+// Heartbeat refreshes the updated_at of a processing job
+func (m *MemoryStorage) Heartbeat(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if j, ok := m.jobs[id]; ok && j.State == job.StateProcessing {
+		j.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+// SetProgress records the percent done of a processing job
+func (m *MemoryStorage) SetProgress(id string, progress int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if j, ok := m.jobs[id]; ok && j.State == job.StateProcessing {
+		j.Progress = progress
+	}
+	return nil
+}
+
+// RecoverStaleJobs requeues processing jobs without a heartbeat since
+// threshold ago
+func (m *MemoryStorage) RecoverStaleJobs(threshold time.Duration) ([]*job.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cutoff := time.Now().Add(-threshold)
+	var recovered []*job.Job
+	for _, j := range m.jobs {
+		if j.State != job.StateProcessing || !notAfter(j.UpdatedAt, cutoff) {
+			continue
+		}
+		recovered = append(recovered, cloneJob(j))
+		j.Requeue(staleReason(j, threshold))
+		delete(m.cancels, j.ID)
+	}
+	return recovered, nil
+}
+
+// IsCancelRequested reports whether the processing job has been cancelled
+func (m *MemoryStorage) IsCancelRequested(id string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.cancels[id], nil
+}
+
+// DeleteCompletedBefore removes completed jobs that finished before cutoff
+func (m *MemoryStorage) DeleteCompletedBefore(cutoff time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deleted := 0
+	for id, j := range m.jobs {
+		if j.State == job.StateCompleted && j.CompletedAt != nil && j.CompletedAt.Unix() < cutoff.Unix() {
+			delete(m.jobs, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// DeleteJobsByState removes jobs in state last updated before olderThan
+func (m *MemoryStorage) DeleteJobsByState(state job.State, olderThan time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deleted := 0
+	for id, j := range m.jobs {
+		if j.State == state && j.UpdatedAt.Unix() < olderThan.Unix() {
+			delete(m.jobs, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// GetRetryableJobs returns failed jobs ready to retry, soonest first
+func (m *MemoryStorage) GetRetryableJobs() ([]*job.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var jobs []*job.Job
+	for _, j := range m.jobs {
+		if j.State == job.StateFailed && j.NextRetryAt != nil && notAfter(*j.NextRetryAt, now) {
+			jobs = append(jobs, cloneJob(j))
+		}
+	}
+	sort.SliceStable(jobs, func(a, b int) bool {
+		return jobs[a].NextRetryAt.Unix() < jobs[b].NextRetryAt.Unix()
+	})
+	return jobs, nil
+}
+
+// GetDLQJobs returns all dead jobs
+func (m *MemoryStorage) GetDLQJobs() ([]*job.Job, error) {
+	return m.ListJobs(job.StateDead, ListOptions{})
+}
+
+// GetThroughput counts completed jobs per bucket, starting from since.
+// Buckets are aligned to since and every bucket up to now is returned,
+// including empty ones.
+func (m *MemoryStorage) GetThroughput(since time.Time, bucket time.Duration) ([]ThroughputBucket, error) {
+	size := int64(bucket / time.Second)
+	if size <= 0 {
+		return nil, fmt.Errorf("bucket size must be at least one second")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	start := since.Unix()
+	counts := make(map[int64]int)
+	for _, j := range m.jobs {
+		if j.State != job.StateCompleted || j.CompletedAt == nil {
+			continue
+		}
+		if completed := j.CompletedAt.Unix(); completed >= start {
+			counts[(completed-start)/size]++
+		}
+	}
+
+	n := (time.Now().Unix()-start)/size + 1
+	buckets := make([]ThroughputBucket, n)
+	for i := range buckets {
+		buckets[i] = ThroughputBucket{
+			Start: time.Unix(start+int64(i)*size, 0),
+			Count: counts[int64(i)],
+		}
+	}
+	return buckets, nil
+}
+
+// PauseQueue marks a queue as paused. Pausing a paused queue keeps its
+// original pause time.
+func (m *MemoryStorage) PauseQueue(queue string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, p := range m.paused {
+		if p.name == queue {
+			return nil
+		}
+	}
+	m.paused = append(m.paused, pausedQueue{name: queue, pausedAt: time.Now().Truncate(time.Second)})
+	return nil
+}
+
+// ResumeQueue clears a queue's paused flag
+func (m *MemoryStorage) ResumeQueue(queue string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, p := range m.paused {
+		if p.name == queue {
+			m.paused = append(m.paused[:i], m.paused[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// ListPausedQueues returns paused queue names in the order they were paused
+func (m *MemoryStorage) ListPausedQueues() ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	paused := append([]pausedQueue(nil), m.paused...)
+	sort.SliceStable(paused, func(a, b int) bool {
+		if !paused[a].pausedAt.Equal(paused[b].pausedAt) {
+			return paused[a].pausedAt.Before(paused[b].pausedAt)
+		}
+		return paused[a].name < paused[b].name
+	})
+
+	var queues []string
+	for _, p := range paused {
+		queues = append(queues, p.name)
+	}
+	return queues, nil
+}
+
+// AddSchedule stores a copy of a new schedule, failing with
+// ErrScheduleExists if the ID is taken
+func (m *MemoryStorage) AddSchedule(sc *Schedule) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.schedules[sc.ID]; ok {
+		return fmt.Errorf("schedule %s: %w", sc.ID, ErrScheduleExists)
+	}
+	stored := *sc
+	stored.CreatedAt = sc.CreatedAt.Truncate(time.Second)
+	m.schedules[sc.ID] = &stored
+	return nil
+}
+
+// ListSchedules returns copies of every schedule, oldest first
+func (m *MemoryStorage) ListSchedules() ([]*Schedule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var schedules []*Schedule
+	for _, sc := range m.schedules {
+		c := *sc
+		schedules = append(schedules, &c)
+	}
+	sort.Slice(schedules, func(a, b int) bool {
+		if !schedules[a].CreatedAt.Equal(schedules[b].CreatedAt) {
+			return schedules[a].CreatedAt.Before(schedules[b].CreatedAt)
+		}
+		return schedules[a].ID < schedules[b].ID
+	})
+	return schedules, nil
+}
+
+// DeleteSchedule removes a schedule, returning sql.ErrNoRows if there is
+// none with the ID
+func (m *MemoryStorage) DeleteSchedule(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.schedules[id]; !ok {
+		return sql.ErrNoRows
+	}
+	delete(m.schedules, id)
+	return nil
+}
+
+// MarkScheduleFired records the latest fire time a schedule enqueued its
+// job for, ignoring times before the recorded one
+func (m *MemoryStorage) MarkScheduleFired(id string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sc, ok := m.schedules[id]
+	if !ok {
+		return nil
+	}
+	at = at.Truncate(time.Second)
+	if sc.LastFiredAt == nil || sc.LastFiredAt.Before(at) {
+		sc.LastFiredAt = &at
+	}
+	return nil
+}
+
+// RecordStats stores the current job count of every state as a sample
+// taken at the given time, and deletes the samples older than retention
+// (0 keeps them all). Nothing is recorded while there are no jobs.
+func (m *MemoryStorage) RecordStats(at time.Time, retention time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if retention > 0 {
+		cutoff := at.Add(-retention)
+		kept := m.stats[:0]
+		for _, sample := range m.stats {
+			if !sample.Time.Before(cutoff) {
+				kept = append(kept, sample)
+			}
+		}
+		m.stats = kept
+	}
+
+	counts := m.countStates()
+	if len(counts) == 0 {
+		return nil
+	}
+
+	at = at.Truncate(time.Second)
+	if n := len(m.stats); n > 0 && m.stats[n-1].Time.Equal(at) {
+		for state, count := range counts {
+			m.stats[n-1].Counts[state] = count
+		}
+		return nil
+	}
+	m.stats = append(m.stats, StatsSample{Time: at, Counts: counts})
+	return nil
+}
+
+// GetStatsHistory returns the samples recorded since the given time,
+// oldest first
+func (m *MemoryStorage) GetStatsHistory(since time.Time) ([]StatsSample, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var samples []StatsSample
+	for _, sample := range m.stats {
+		if sample.Time.Unix() < since.Unix() {
+			continue
+		}
+		counts := make(map[job.State]int, len(sample.Counts))
+		for state, count := range sample.Counts {
+			counts[state] = count
+		}
+		samples = append(samples, StatsSample{Time: sample.Time, Counts: counts})
+	}
+	sort.SliceStable(samples, func(a, b int) bool {
+		return samples[a].Time.Before(samples[b].Time)
+	})
+	return samples, nil
+}
+
+// Optimize has nothing to do for in-memory storage
+func (m *MemoryStorage) Optimize() ([]string, error) {
+	return []string{"nothing to optimize for in-memory storage"}, nil
+}
+
+// claimable mirrors claimWhere: due jobs outside paused queues. m.mu must
+// be held.
+func (m *MemoryStorage) claimable(j *job.Job, now time.Time) bool {
+	return dueForClaim(j, now) && !m.isPaused(j.Queue)
+}
+
+// dueForClaim reports whether j is a pending job whose scheduled time has
+// come or a failed job due for retry
+func dueForClaim(j *job.Job, now time.Time) bool {
+	switch {
+	case j.State == job.StatePending && (j.ScheduledAt == nil || notAfter(*j.ScheduledAt, now)):
+		return true
+	case j.State == job.StateFailed && j.NextRetryAt != nil && notAfter(*j.NextRetryAt, now):
+		return true
+	default:
+		return false
+	}
+}
+
+// isPaused reports whether the queue, or every queue, is paused. m.mu
+// must be held.
+func (m *MemoryStorage) isPaused(queue string) bool {
+	for _, p := range m.paused {
+		if p.name == queue || p.name == AllQueues {
+			return true
+		}
+	}
+	return false
+}
+
+// agedPriority mirrors SQLiteStorage.effectivePriority: j's priority plus
+// one for every ageBoostMinutes it has waited (0 disables aging)
+func agedPriority(j *job.Job, now time.Time, ageBoostMinutes int) int {
+	if ageBoostMinutes <= 0 {
+		return j.Priority
+	}
+	waited := now.Sub(j.CreatedAt.Truncate(time.Second)).Minutes()
+	return j.Priority + int(waited/float64(ageBoostMinutes))
+}
+
+// sortClaimOrder orders jobs as claimOrder does: by effective priority,
+// then creation time, then enqueue order
+func sortClaimOrder(jobs []*job.Job, now time.Time, ageBoostMinutes int) {
+	sort.Slice(jobs, func(a, b int) bool {
+		pa, pb := agedPriority(jobs[a], now, ageBoostMinutes), agedPriority(jobs[b], now, ageBoostMinutes)
+		if pa != pb {
+			return pa > pb
+		}
+		ca, cb := jobs[a].CreatedAt.Unix(), jobs[b].CreatedAt.Unix()
+		if ca != cb {
+			return ca < cb
+		}
+		return jobs[a].Seq < jobs[b].Seq
+	})
+}
+
+// takeClaims returns the first n candidates in claim order. With queue
+// weights set, the queue of each job is picked in turn, as it would be by
+// n separate claims.
+func takeClaims(candidates []*job.Job, n int, now time.Time, ageBoostMinutes int, scheduler *queueScheduler) []*job.Job {
+	sortClaimOrder(candidates, now, ageBoostMinutes)
+	if !scheduler.enabled() {
+		if len(candidates) > n {
+			candidates = candidates[:n]
+		}
+		return candidates
+	}
+
+	var taken []*job.Job
+	for len(taken) < n && len(candidates) > 0 {
+		// narrow keeps claim order, so the queue's first job is next
+		next := scheduler.narrow(candidates)[0]
+		taken = append(taken, next)
+		candidates = slices.DeleteFunc(candidates, func(j *job.Job) bool { return j == next })
+	}
+	return taken
+}
+
+// firstClaim adapts the result of GetNextPendingJobs(..., 1) to
+// GetNextPendingJob, returning nil if no job was claimed
+func firstClaim(jobs []*job.Job, err error) (*job.Job, error) {
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+	return jobs[0], nil
+}
+
+// countStates returns the number of jobs in each state. m.mu must be held.
+func (m *MemoryStorage) countStates() map[job.State]int {
+	counts := make(map[job.State]int)
+	for _, j := range m.jobs {
+		counts[j.State]++
+	}
+	return counts
+}
+
+// matchesFilter mirrors filterWhere
+func matchesFilter(j *job.Job, filter ClaimFilter) bool {
+	if len(filter.Queues) > 0 && !slices.Contains(filter.Queues, j.Queue) {
+		return false
+	}
+	if len(filter.CommandPrefixes) == 0 {
+		return true
+	}
+	for _, prefix := range filter.CommandPrefixes {
+		if strings.HasPrefix(j.Command, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// notAfter reports whether t is at or before now at the one second
+// precision timestamps are stored with
+func notAfter(t, now time.Time) bool {
+	return t.Unix() <= now.Unix()
+}
+
+// cloneJob returns a deep copy of j
+func cloneJob(j *job.Job) *job.Job {
+	c := *j
+	c.NextRetryAt = cloneTime(j.NextRetryAt)
+	c.ScheduledAt = cloneTime(j.ScheduledAt)
+	c.HeldUntil = cloneTime(j.HeldUntil)
+	c.CompletedAt = cloneTime(j.CompletedAt)
+	if j.History != nil {
+		c.History = append([]job.AttemptRecord(nil), j.History...)
+	}
+	if j.RetrySchedule != nil {
+		c.RetrySchedule = append([]job.Duration(nil), j.RetrySchedule...)
+	}
+	if j.Tags != nil {
+		c.Tags = append([]string(nil), j.Tags...)
+	}
+	if j.Env != nil {
+		c.Env = make(map[string]string, len(j.Env))
+		for k, v := range j.Env {
+			c.Env[k] = v
+		}
+	}
+	if j.NextJob != nil {
+		c.NextJob = cloneJob(j.NextJob)
+	}
+	return &c
+}
+
+// cloneTime copies an optional timestamp
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+)
+
+func TestMemoryConcurrentClaims(t *testing.T) {
+	const jobs, workers = 200, 8
+	m := NewMemoryStorage()
+	for i := 0; i < jobs; i++ {
+		if err := m.SaveJob(job.NewJob(fmt.Sprintf("echo %d", i), 3)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Every worker claims until nothing is left, in batches of 1 to 3
+	var mu sync.Mutex
+	claimedBy := make(map[string]string)
+	var errs []error
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		workerID := fmt.Sprintf("w%d", w)
+		n := w%3 + 1
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				claimed, err := m.GetNextPendingJobs(workerID, ClaimFilter{}, n)
+				if err != nil {
+					mu.Lock()
+					errs = append(errs, err)
+					mu.Unlock()
+					return
+				}
+				if len(claimed) == 0 {
+					return
+				}
+				mu.Lock()
+				for _, j := range claimed {
+					if other, ok := claimedBy[j.ID]; ok {
+						errs = append(errs, fmt.Errorf("job %s claimed by both %s and %s", j.ID, other, workerID))
+					}
+					claimedBy[j.ID] = workerID
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		t.Error(err)
+	}
+
+	if len(claimedBy) != jobs {
+		t.Errorf("%d of %d jobs claimed", len(claimedBy), jobs)
+	}
+	stored, err := m.ListJobs(job.StateProcessing, ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, j := range stored {
+		if claimedBy[j.ID] != j.WorkerID {
+			t.Errorf("job %s stored with worker %q, claimed by %q", j.ID, j.WorkerID, claimedBy[j.ID])
+		}
+	}
+	if len(stored) != jobs {
+		t.Errorf("%d jobs stored as processing, want %d", len(stored), jobs)
+	}
+}
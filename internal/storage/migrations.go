@@ -0,0 +1,299 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+)
+
+// schemaConn is the connection a migration runs on. It holds the write
+// lock for the migration's transaction, so migrations must not use s.db.
+type schemaConn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// schemaMigration is one step in the evolution of the database schema
+type schemaMigration struct {
+	version     int
+	description string
+	apply       func(ctx context.Context, conn schemaConn) error
+}
+
+// schemaMigrations lists every schema change in the order it is applied.
+// Released steps must never change; a schema change is a new step with
+// the next version.
+var schemaMigrations = []schemaMigration{
+	{1, "initial schema", migrateInitialSchema},
+	{2, "add jobs.idempotency_key", migrateIdempotencyKey},
+	{3, "add schedules table", migrateSchedules},
+	{4, "add jobs.progress", migrateProgress},
+}
+
+// migrate applies every migration newer than the database's schema
+// version, each in its own transaction
+func (s *SQLiteStorage) migrate() error {
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open connection for migrations: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		description TEXT NOT NULL,
+		applied_at DATETIME NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	version, err := currentSchemaVersion(ctx, conn)
+	if err != nil {
+		return err
+	}
+	for _, m := range schemaMigrations {
+		if m.version <= version {
+			continue
+		}
+		if err := applyMigration(ctx, conn, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyMigration runs one migration and records it. BEGIN IMMEDIATE takes
+// the write lock up front, so processes upgrading the same database at
+// once wait for each other; the version is re-read under the lock in case
+// another process applied the migration first.
+func applyMigration(ctx context.Context, conn *sql.Conn, m schemaMigration) (err error) {
+	if _, err := conn.ExecContext(ctx, `BEGIN IMMEDIATE`); err != nil {
+		return fmt.Errorf("failed to begin migration %d: %w", m.version, err)
+	}
+	defer func() {
+		if err != nil {
+			conn.ExecContext(ctx, `ROLLBACK`)
+		}
+	}()
+
+	version, err := currentSchemaVersion(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if version < m.version {
+		if err := m.apply(ctx, conn); err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", m.version, m.description, err)
+		}
+		if _, err := conn.ExecContext(ctx, `INSERT INTO schema_migrations (version, description, applied_at) VALUES (?, ?, ?)`,
+			m.version, m.description, dbTime(time.Now())); err != nil {
+			return fmt.Errorf("failed to record migration %d: %w", m.version, err)
+		}
+	}
+
+	if _, err := conn.ExecContext(ctx, `COMMIT`); err != nil {
+		return fmt.Errorf("failed to commit migration %d: %w", m.version, err)
+	}
+	return nil
+}
+
+// currentSchemaVersion returns the newest applied migration, or 0 for a
+// database that predates schema_migrations
+func currentSchemaVersion(ctx context.Context, conn schemaConn) (int, error) {
+	var version int
+	if err := conn.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// migrateInitialSchema creates the tables, or completes the jobs table of
+// a database created before schema versioning by adding the columns that
+// were introduced since its release
+func migrateInitialSchema(ctx context.Context, conn schemaConn) error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS jobs (
+		id TEXT PRIMARY KEY,
+		seq INTEGER,
+		retry_of TEXT,
+		queue TEXT NOT NULL DEFAULT 'default',
+		command TEXT NOT NULL,
+		fallback_command TEXT,
+		state TEXT NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		max_retries INTEGER NOT NULL DEFAULT 3,
+		timeout_seconds INTEGER NOT NULL DEFAULT 0,
+		backoff_base REAL NOT NULL DEFAULT 0,
+		priority INTEGER NOT NULL DEFAULT 0,
+		env_file TEXT,
+		retry_on_timeout_only INTEGER NOT NULL DEFAULT 0,
+		sandbox INTEGER NOT NULL DEFAULT 0,
+		success_pattern TEXT,
+		failure_pattern TEXT,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		next_retry_at DATETIME,
+		scheduled_at DATETIME,
+		held_until DATETIME,
+		completed_at DATETIME,
+		worker_id TEXT,
+		error TEXT,
+		error_type TEXT,
+		output TEXT,
+		history TEXT,
+		next_job TEXT,
+		parent_id TEXT,
+		parent_output TEXT,
+		retry_schedule TEXT,
+		cpu_time_ms INTEGER NOT NULL DEFAULT 0,
+		max_rss_kb INTEGER NOT NULL DEFAULT 0,
+		exit_code INTEGER NOT NULL DEFAULT 0,
+		env TEXT,
+		work_dir TEXT,
+		tags TEXT,
+		duration_ms INTEGER NOT NULL DEFAULT 0
+	);
+
+	CREATE TABLE IF NOT EXISTS paused_queues (
+		queue TEXT PRIMARY KEY,
+		paused_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS cancel_requests (
+		job_id TEXT PRIMARY KEY,
+		requested_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS stats_history (
+		timestamp DATETIME NOT NULL,
+		state TEXT NOT NULL,
+		count INTEGER NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_stats_history_timestamp ON stats_history(timestamp);
+
+	`
+
+	if _, err := conn.ExecContext(ctx, schema); err != nil {
+		return fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	columns := []struct{ name, definition string }{
+		{"scheduled_at", "DATETIME"},
+		{"seq", "INTEGER"},
+		{"error_type", "TEXT"},
+		{"env_file", "TEXT"},
+		{"retry_on_timeout_only", "INTEGER NOT NULL DEFAULT 0"},
+		{"priority", "INTEGER NOT NULL DEFAULT 0"},
+		{"sandbox", "INTEGER NOT NULL DEFAULT 0"},
+		{"success_pattern", "TEXT"},
+		{"failure_pattern", "TEXT"},
+		{"fallback_command", "TEXT"},
+		{"history", "TEXT"},
+		{"completed_at", "DATETIME"},
+		{"held_until", "DATETIME"},
+		{"queue", "TEXT NOT NULL DEFAULT 'default'"},
+		{"timeout_seconds", "INTEGER NOT NULL DEFAULT 0"},
+		{"backoff_base", "REAL NOT NULL DEFAULT 0"},
+		{"retry_of", "TEXT"},
+		{"next_job", "TEXT"},
+		{"parent_id", "TEXT"},
+		{"parent_output", "TEXT"},
+		{"retry_schedule", "TEXT"},
+		{"cpu_time_ms", "INTEGER NOT NULL DEFAULT 0"},
+		{"max_rss_kb", "INTEGER NOT NULL DEFAULT 0"},
+		{"exit_code", "INTEGER NOT NULL DEFAULT 0"},
+		{"env", "TEXT"},
+		{"work_dir", "TEXT"},
+		{"tags", "TEXT"},
+		{"duration_ms", "INTEGER NOT NULL DEFAULT 0"},
+	}
+	for _, c := range columns {
+		if err := addColumnIfMissing(ctx, conn, "jobs", c.name, c.definition); err != nil {
+			return err
+		}
+	}
+
+	// Backfill the enqueue sequence for rows created before it existed
+	if _, err := conn.ExecContext(ctx, `UPDATE jobs SET seq = rowid WHERE seq IS NULL`); err != nil {
+		return fmt.Errorf("failed to backfill job sequence: %w", err)
+	}
+
+	// Completed jobs from before completed_at existed finished at their last update
+	if _, err := conn.ExecContext(ctx, `UPDATE jobs SET completed_at = updated_at WHERE state = ? AND completed_at IS NULL`, job.StateCompleted); err != nil {
+		return fmt.Errorf("failed to backfill completion times: %w", err)
+	}
+
+	return nil
+}
+
+// migrateIdempotencyKey adds the caller-supplied key enqueue uses to
+// return an existing job instead of a duplicate. Its unique index is in
+// jobIndexes.
+func migrateIdempotencyKey(ctx context.Context, conn schemaConn) error {
+	if _, err := conn.ExecContext(ctx, `ALTER TABLE jobs ADD COLUMN idempotency_key TEXT`); err != nil {
+		return fmt.Errorf("failed to add column jobs.idempotency_key: %w", err)
+	}
+	return nil
+}
+
+// migrateSchedules adds the table of recurring job definitions
+func migrateSchedules(ctx context.Context, conn schemaConn) error {
+	schema := `
+	CREATE TABLE schedules (
+		id TEXT PRIMARY KEY,
+		cron TEXT NOT NULL,
+		command TEXT NOT NULL,
+		queue TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		last_fired_at DATETIME
+	)`
+	if _, err := conn.ExecContext(ctx, schema); err != nil {
+		return fmt.Errorf("failed to create table schedules: %w", err)
+	}
+	return nil
+}
+
+// migrateProgress adds the percent done reported by a running job
+func migrateProgress(ctx context.Context, conn schemaConn) error {
+	if _, err := conn.ExecContext(ctx, `ALTER TABLE jobs ADD COLUMN progress INTEGER NOT NULL DEFAULT 0`); err != nil {
+		return fmt.Errorf("failed to add column jobs.progress: %w", err)
+	}
+	return nil
+}
+
+// addColumnIfMissing adds a column to a table unless it already exists
+func addColumnIfMissing(ctx context.Context, conn schemaConn, table, column, definition string) error {
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return fmt.Errorf("failed to inspect table %s: %w", table, err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+	rows.Close()
+
+	query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)
+	if _, err := conn.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to add column %s.%s: %w", table, column, err)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+)
+
+// openTestSQLite opens the database at path without initializing it
+func openTestSQLite(t *testing.T, path string) *SQLiteStorage {
+	t.Helper()
+	s, err := NewSQLiteStorage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// schemaVersion returns the newest migration recorded in s
+func schemaVersion(t *testing.T, s *SQLiteStorage) int {
+	t.Helper()
+	var version, count int
+	err := s.db.QueryRow(`SELECT COALESCE(MAX(version), 0), COUNT(*) FROM schema_migrations`).Scan(&version, &count)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != version {
+		t.Errorf("%d migrations recorded for version %d", count, version)
+	}
+	return version
+}
+
+// latestSchemaVersion is the version a fully migrated database has
+func latestSchemaVersion() int {
+	return schemaMigrations[len(schemaMigrations)-1].version
+}
+
+func TestMigrateUpgradesV1Database(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queuectl.db")
+
+	// Build a database as the release with only migration 1 left it
+	v1 := openTestSQLite(t, path)
+	all := schemaMigrations
+	schemaMigrations = all[:1]
+	err := v1.migrate()
+	schemaMigrations = all
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := schemaVersion(t, v1); got != 1 {
+		t.Fatalf("v1 database at version %d", got)
+	}
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local)
+	_, err = v1.db.Exec(`INSERT INTO jobs (id, seq, command, state, attempts, max_retries, priority, created_at, updated_at, tags)
+		VALUES ('old', 1, 'echo old', 'pending', 1, 5, 2, ?, ?, '["v1"]')`, dbTime(created), dbTime(created))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1.Close()
+
+	s := openTestSQLite(t, path)
+	if err := s.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := schemaVersion(t, s), latestSchemaVersion(); got != want {
+		t.Fatalf("upgraded database at version %d, want %d", got, want)
+	}
+
+	// The job written by v1 reads back unchanged
+	j, err := s.GetJob("old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j.Command != "echo old" || j.State != job.StatePending || j.Attempts != 1 || j.MaxRetries != 5 || j.Priority != 2 {
+		t.Errorf("old job read back as %+v", j)
+	}
+	if !j.CreatedAt.Equal(created) || !j.HasTag("v1") || j.Progress != 0 || j.IdempotencyKey != "" {
+		t.Errorf("old job read back as %+v", j)
+	}
+
+	// Columns and tables added after v1 are usable
+	j.Progress = 40
+	j.IdempotencyKey = "upgrade"
+	if err := s.SaveJob(j); err != nil {
+		t.Fatal(err)
+	}
+	found, err := s.FindByIdempotencyKey("upgrade", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found == nil || found.ID != "old" || found.Progress != 40 {
+		t.Errorf("FindByIdempotencyKey returned %+v", found)
+	}
+	if err := s.AddSchedule(&Schedule{ID: "nightly", Cron: "0 3 * * *", Command: "true", Queue: "default", CreatedAt: created}); err != nil {
+		t.Fatal(err)
+	}
+	schedules, err := s.ListSchedules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schedules) != 1 || schedules[0].ID != "nightly" {
+		t.Errorf("ListSchedules returned %v", schedules)
+	}
+
+	// Initializing again applies nothing
+	if err := s.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := schemaVersion(t, s), latestSchemaVersion(); got != want {
+		t.Errorf("database at version %d after a second Initialize, want %d", got, want)
+	}
+}
+
+func TestMigrateUpgradesUnversionedDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queuectl.db")
+
+	// A jobs table from before schema_migrations and most columns existed
+	old := openTestSQLite(t, path)
+	_, err := old.db.ExecContext(context.Background(), `
+	CREATE TABLE jobs (
+		id TEXT PRIMARY KEY,
+		command TEXT NOT NULL,
+		state TEXT NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		max_retries INTEGER NOT NULL DEFAULT 3,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		next_retry_at DATETIME,
+		worker_id TEXT,
+		error TEXT,
+		output TEXT
+	);
+	INSERT INTO jobs (id, command, state, attempts, max_retries, created_at, updated_at, output)
+		VALUES ('done', 'echo hi', 'completed', 1, 3, '2024-05-01T12:00:00Z', '2024-05-01T12:00:05Z', 'hi');`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old.Close()
+
+	s := openTestSQLite(t, path)
+	if err := s.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := schemaVersion(t, s), latestSchemaVersion(); got != want {
+		t.Fatalf("upgraded database at version %d, want %d", got, want)
+	}
+
+	j, err := s.GetJob("done")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j.State != job.StateCompleted || j.Output != "hi" || j.Queue != "default" {
+		t.Errorf("old job read back as %+v", j)
+	}
+	if j.Seq == 0 {
+		t.Error("seq was not backfilled")
+	}
+	if j.CompletedAt == nil || !j.CompletedAt.Equal(j.UpdatedAt) {
+		t.Errorf("completed_at = %v, want the last update %s", j.CompletedAt, j.UpdatedAt)
+	}
+}
@@ -0,0 +1,1523 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisKeyPrefix starts the name of every key RedisStorage uses
+const redisKeyPrefix = "queuectl:"
+
+// redisLayoutVersion is the version of the key layout this build writes,
+// stored under queuectl:version. Version 2 added queuectl:delayed.
+const redisLayoutVersion = 2
+
+// redisClaimPage is how many claim candidates are read per round trip
+// while looking for the next job
+const redisClaimPage = 100
+
+// redisClaimSpare is how many candidates a claim reads beyond the jobs it
+// wants, to fall back on when other workers claim some of them first
+const redisClaimSpare = 8
+
+// redisCASAttempts bounds how often an operation re-reads a job that
+// another client changed under it before giving up
+const redisCASAttempts = 5
+
+// errRedisConflict is returned when a compare-and-swap write finds that
+// the job changed since it was read
+var errRedisConflict = errors.New("job was changed by another client")
+
+// RedisStorage implements Storage on a Redis server, for queues whose
+// claim rate outgrows a single SQLite file. It uses these keys:
+//
+//	queuectl:jobs           hash of job ID to the job as JSON
+//	queuectl:seq            counter assigning each new job its seq
+//	queuectl:ready          sorted set of due pending and retrying jobs in claim order
+//	queuectl:delayed        sorted set of pending and retrying jobs not yet due, by due time
+//	queuectl:delayed_scores hash of queuectl:delayed member to its queuectl:ready score
+//	queuectl:held           sorted set of held job IDs by held_until
+//	queuectl:completed      sorted set of completed job IDs by completed_at
+//	queuectl:state:<state>  set of the job IDs in each state
+//	queuectl:queue_counts   hash of "<state>:<queue>" to its job count
+//	queuectl:attempts       sum of every job's attempts
+//	queuectl:idempotency    hash of idempotency key to the unfinished job holding it
+//	queuectl:idempotency_done  hash of idempotency key to the last job that completed with it
+//	queuectl:cancels        set of processing job IDs with a cancel request
+//	queuectl:paused         hash of paused queue to the Unix time it was paused
+//	queuectl:stats          hash of Unix time to the job counts recorded then
+//	queuectl:stats_times    sorted set of the times in queuectl:stats
+//	queuectl:schedules      hash of schedule ID to the schedule as JSON
+//	queuectl:schedule_fired hash of schedule ID to the Unix time it last fired for
+//
+// Every write goes through a Lua script that updates the job and all of
+// its indexes at once, so other clients never see them disagree. Writes
+// that depend on what was read (claims, cancels, heartbeats, recovery)
+// pass the JSON they read and fail if the stored job has changed since,
+// which is what makes each claim exclusive: of two workers that pick the
+// same job, only the first compare-and-swap succeeds.
+type RedisStorage struct {
+	client *redis.Client
+
+	// ageBoostMinutes and scheduler match the SQLiteStorage settings
+	ageBoostMinutes int
+	scheduler       queueScheduler
+}
+
+var _ Storage = (*RedisStorage)(nil)
+
+// IsRedisURL reports whether a db_path names a Redis server rather than
+// a SQLite file
+func IsRedisURL(path string) bool {
+	return strings.HasPrefix(path, "redis://") || strings.HasPrefix(path, "rediss://")
+}
+
+// NewRedisStorage connects to the Redis server at url, e.g.
+// redis://:password@localhost:6379/0
+func NewRedisStorage(url string) (*RedisStorage, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	client := redis.NewClient(opts)
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+	return &RedisStorage{client: client}, nil
+}
+
+// isRedisConnectionError reports whether err means the Redis server could
+// not be reached
+func isRedisConnectionError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, redis.ErrClosed)
+}
+
+// rkey returns the full name of a RedisStorage key
+func rkey(name string) string {
+	return redisKeyPrefix + name
+}
+
+// Initialize records the key layout version, refusing data written by a
+// newer layout. Data from an older layout is read as it is: version 1
+// kept jobs that are not due yet in queuectl:ready, which claims skip
+// until they are due, so only the version is raised.
+func (s *RedisStorage) Initialize() error {
+	ctx := context.Background()
+	version, err := s.client.Get(ctx, rkey("version")).Int()
+	if errors.Is(err, redis.Nil) {
+		return s.client.SetNX(ctx, rkey("version"), redisLayoutVersion, 0).Err()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read layout version: %w", err)
+	}
+	if version > redisLayoutVersion {
+		return fmt.Errorf("Redis data has layout version %d, newer than this build supports (%d)", version, redisLayoutVersion)
+	}
+	if version < redisLayoutVersion {
+		if err := s.client.Set(ctx, rkey("version"), redisLayoutVersion, 0).Err(); err != nil {
+			return fmt.Errorf("failed to record layout version: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close closes the connection pool
+func (s *RedisStorage) Close() error {
+	return s.client.Close()
+}
+
+// SetAgePriorityBoost configures anti-starvation aging for job claims.
+// A waiting job gains one priority level for every interval it has waited.
+// With aging on, each claim reads every candidate instead of stopping at
+// the first in score order.
+func (s *RedisStorage) SetAgePriorityBoost(interval time.Duration) {
+	s.ageBoostMinutes = int(interval / time.Minute)
+}
+
+// SetQueueWeights enables weighted fair scheduling between queues, as for
+// SQLiteStorage.SetQueueWeights
+func (s *RedisStorage) SetQueueWeights(weights map[string]int) {
+	s.scheduler.set(weights)
+}
+
+// redisLib holds the Lua helpers shared by the write scripts. ARGV[1] is
+// always the key prefix.
+const redisLib = `
+local prefix = ARGV[1]
+local function k(name) return prefix .. name end
+
+local function member(seq, id) return string.format('%020d:%s', seq, id) end
+
+-- unindex removes a stored job, given as its decoded JSON, from every index
+local function unindex(id, old)
+	redis.call('SREM', k('state:' .. old.state), id)
+	local field = old.state .. ':' .. old.queue
+	if redis.call('HINCRBY', k('queue_counts'), field, -1) <= 0 then
+		redis.call('HDEL', k('queue_counts'), field)
+	end
+	redis.call('DECRBY', k('attempts'), old.attempts or 0)
+	redis.call('ZREM', k('ready'), member(old.seq, id))
+	redis.call('ZREM', k('delayed'), member(old.seq, id))
+	redis.call('HDEL', k('delayed_scores'), member(old.seq, id))
+	redis.call('ZREM', k('held'), id)
+	redis.call('ZREM', k('completed'), id)
+	local key = old.idempotency_key
+	if key and redis.call('HGET', k('idempotency'), key) == id then
+		redis.call('HDEL', k('idempotency'), key)
+	end
+end
+`
+
+// redisSaveScript stores jobs described by redisWrite values (ARGV[3:])
+// with ARGV[2] as "save", "insert", "insert_new", which skips jobs whose
+// ID is taken instead of failing, "replace_idle", which skips jobs stored
+// as processing, or "claim", which skips jobs that changed since they were
+// read instead of failing. Every job is checked
+// before any is written, so a batch is saved whole or not at all. It returns {"ok"}
+// followed by the positions (from 0) of the jobs skipped, or the reason
+// and subject of the first failure.
+var redisSaveScript = redis.NewScript(redisLib + `
+local mode = ARGV[2]
+local writes, skipped, ids = {}, {}, {}
+for i = 3, #ARGV do
+	local w = cjson.decode(ARGV[i])
+	local old = redis.call('HGET', k('jobs'), w.id)
+	local skip = false
+	if w.expect ~= '' and old ~= w.expect then
+		if mode ~= 'claim' then
+			return {'conflict', w.id}
+		end
+		skip = true
+	end
+	if mode == 'insert' and old then
+		return {'exists', w.id}
+	end
+	if mode == 'insert_new' and (old or ids[w.id]) then
+		skip = true
+	end
+	if mode == 'replace_idle' and old and cjson.decode(old).state == 'processing' then
+		skip = true
+	end
+	if skip then
+		table.insert(skipped, tostring(i - 3))
+	else
+		if w.key ~= '' and w.unfinished then
+			local holder = redis.call('HGET', k('idempotency'), w.key)
+			if holder and holder ~= w.id then
+				return {'key', w.key}
+			end
+			for _, prev in ipairs(writes) do
+				if prev.w.key == w.key and prev.w.unfinished and prev.w.id ~= w.id then
+					return {'key', w.key}
+				end
+			end
+		end
+		ids[w.id] = true
+		table.insert(writes, {w = w, old = old})
+	end
+end
+
+for _, item in ipairs(writes) do
+	local w, seq = item.w, nil
+	if item.old then
+		local old = cjson.decode(item.old)
+		seq = old.seq
+		unindex(w.id, old)
+	else
+		seq = redis.call('INCR', k('seq'))
+	end
+
+	-- Bodies arrive without a seq; the stored one is spliced in
+	redis.call('HSET', k('jobs'), w.id, '{"seq":' .. seq .. ',' .. string.sub(w.body, 2))
+	redis.call('SADD', k('state:' .. w.state), w.id)
+	redis.call('HINCRBY', k('queue_counts'), w.state .. ':' .. w.queue, 1)
+	redis.call('INCRBY', k('attempts'), w.attempts)
+	if w.ready and w.due_at > 0 then
+		redis.call('ZADD', k('delayed'), w.due_at, member(seq, w.id))
+		redis.call('HSET', k('delayed_scores'), member(seq, w.id), w.score)
+	elseif w.ready then
+		redis.call('ZADD', k('ready'), w.score, member(seq, w.id))
+	end
+	if w.held_until > 0 then
+		redis.call('ZADD', k('held'), w.held_until, w.id)
+	end
+	if w.completed_at > 0 then
+		redis.call('ZADD', k('completed'), w.completed_at, w.id)
+	end
+	if w.key ~= '' then
+		if w.unfinished then
+			redis.call('HSET', k('idempotency'), w.key, w.id)
+		elseif w.state == 'completed' then
+			redis.call('HSET', k('idempotency_done'), w.key, w.id)
+		end
+	end
+	if w.state ~= 'processing' then
+		redis.call('SREM', k('cancels'), w.id)
+	end
+end
+table.insert(skipped, 1, 'ok')
+return skipped
+`)
+
+// redisDeleteScript removes jobs given as pairs of ID and expected JSON
+// (ARGV[2:]). A job whose expected JSON is not empty is only removed if
+// unchanged. It returns the number removed.
+var redisDeleteScript = redis.NewScript(redisLib + `
+local deleted = 0
+for i = 2, #ARGV, 2 do
+	local id, expect = ARGV[i], ARGV[i + 1]
+	local old = redis.call('HGET', k('jobs'), id)
+	if old and (expect == '' or old == expect) then
+		unindex(id, cjson.decode(old))
+		redis.call('HDEL', k('jobs'), id)
+		redis.call('SREM', k('cancels'), id)
+		deleted = deleted + 1
+	end
+end
+return deleted
+`)
+
+// redisPromoteScript moves the jobs in queuectl:delayed that are due by
+// Unix time ARGV[2] to queuectl:ready, returning how many it moved
+var redisPromoteScript = redis.NewScript(redisLib + `
+local due = redis.call('ZRANGEBYSCORE', k('delayed'), '-inf', ARGV[2])
+for _, m in ipairs(due) do
+	local score = redis.call('HGET', k('delayed_scores'), m)
+	if score then
+		redis.call('ZADD', k('ready'), score, m)
+	end
+	redis.call('ZREM', k('delayed'), m)
+	redis.call('HDEL', k('delayed_scores'), m)
+end
+return #due
+`)
+
+// redisCancelScript records a cancel request for job ARGV[2] if it is
+// processing, returning 1 if it was recorded
+var redisCancelScript = redis.NewScript(redisLib + `
+local body = redis.call('HGET', k('jobs'), ARGV[2])
+if body and cjson.decode(body).state == 'processing' then
+	redis.call('SADD', k('cancels'), ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// redisFireScript records Unix time ARGV[3] as the last fire time of
+// schedule ARGV[2] if it is later than the recorded one and the schedule
+// still exists
+var redisFireScript = redis.NewScript(redisLib + `
+if redis.call('HEXISTS', k('schedules'), ARGV[2]) == 0 then
+	return 0
+end
+local last = redis.call('HGET', k('schedule_fired'), ARGV[2])
+if last and tonumber(last) >= tonumber(ARGV[3]) then
+	return 0
+end
+redis.call('HSET', k('schedule_fired'), ARGV[2], ARGV[3])
+return 1
+`)
+
+// redisWrite describes one job to redisSaveScript: its JSON and the index
+// entries derived from it
+type redisWrite struct {
+	ID          string `json:"id"`
+	Body        string `json:"body"`   // Job JSON without seq
+	Expect      string `json:"expect"` // Stored JSON the write requires ("" = any)
+	State       string `json:"state"`
+	Queue       string `json:"queue"`
+	Attempts    int    `json:"attempts"`
+	Key         string `json:"key"`
+	Unfinished  bool   `json:"unfinished"`
+	Ready       bool   `json:"ready"`        // Belongs in queuectl:ready once due
+	Score       string `json:"score"`        // Claim order score, if Ready
+	DueAt       int64  `json:"due_at"`       // Unix time a Ready job not yet due becomes due, else 0
+	HeldUntil   int64  `json:"held_until"`   // Unix time, or 0 if not held
+	CompletedAt int64  `json:"completed_at"` // Unix time, or 0 if not completed
+}
+
+// newRedisWrite describes j for redisSaveScript, requiring the stored job
+// to still be expect unless expect is empty
+func newRedisWrite(j *job.Job, expect string) (redisWrite, error) {
+	c := *j
+	c.Seq = 0
+	body, err := json.Marshal(&c)
+	if err != nil {
+		return redisWrite{}, fmt.Errorf("failed to encode job %s: %w", j.ID, err)
+	}
+
+	w := redisWrite{
+		ID:         j.ID,
+		Body:       string(body),
+		Expect:     expect,
+		State:      string(j.State),
+		Queue:      j.Queue,
+		Attempts:   j.Attempts,
+		Key:        j.IdempotencyKey,
+		Unfinished: !j.IsTerminal(),
+	}
+	if j.State == job.StatePending || (j.State == job.StateFailed && j.NextRetryAt != nil) {
+		w.Ready = true
+		w.Score = claimScore(j)
+		// Jobs that are not due yet wait in queuectl:delayed, so claims
+		// do not read past them
+		due := j.ScheduledAt
+		if j.State == job.StateFailed {
+			due = j.NextRetryAt
+		}
+		if due != nil && due.After(time.Now()) {
+			w.DueAt = due.Unix()
+		}
+	}
+	if j.State == job.StateHeld && j.HeldUntil != nil {
+		w.HeldUntil = j.HeldUntil.Unix()
+	}
+	if j.State == job.StateCompleted && j.CompletedAt != nil {
+		w.CompletedAt = j.CompletedAt.Unix()
+	}
+	return w, nil
+}
+
+// claimScore orders queuectl:ready as claimOrder does without aging: lower
+// scores come first, so priority is negated and dominates creation time.
+// Redis orders equal scores by member, which starts with the padded seq.
+func claimScore(j *job.Job) string {
+	score := float64(-j.Priority)*1e11 + float64(j.CreatedAt.Unix())
+	return strconv.FormatFloat(score, 'f', -1, 64)
+}
+
+// write runs redisSaveScript, mapping its failures to errors
+func (s *RedisStorage) write(ctx context.Context, mode string, writes ...redisWrite) error {
+	_, err := s.writeSkipping(ctx, mode, writes...)
+	return err
+}
+
+// writeSkipping runs redisSaveScript as write does and also returns the
+// positions in writes of the jobs it skipped
+func (s *RedisStorage) writeSkipping(ctx context.Context, mode string, writes ...redisWrite) ([]int, error) {
+	args := []interface{}{redisKeyPrefix, mode}
+	for _, w := range writes {
+		data, err := json.Marshal(w)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, string(data))
+	}
+
+	res, err := redisSaveScript.Run(ctx, s.client, nil, args...).StringSlice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to save job: %w", err)
+	}
+	switch res[0] {
+	case "exists":
+		return nil, fmt.Errorf("job %s: %w", res[1], ErrJobExists)
+	case "key":
+		return nil, fmt.Errorf("idempotency key %q: %w", res[1], ErrIdempotencyKeyInUse)
+	case "conflict":
+		return nil, fmt.Errorf("job %s: %w", res[1], errRedisConflict)
+	}
+	skipped := make([]int, len(res)-1)
+	for i, pos := range res[1:] {
+		if skipped[i], err = strconv.Atoi(pos); err != nil {
+			return nil, fmt.Errorf("failed to save job: bad skipped position %q", pos)
+		}
+	}
+	return skipped, nil
+}
+
+// swap replaces the stored job raw with j, failing with errRedisConflict
+// if it has changed since it was read
+func (s *RedisStorage) swap(ctx context.Context, j *job.Job, raw string) error {
+	w, err := newRedisWrite(j, raw)
+	if err != nil {
+		return err
+	}
+	return s.write(ctx, "save", w)
+}
+
+// SaveJob inserts or updates a job. New jobs are assigned the next seq;
+// updates keep the one assigned at insert.
+func (s *RedisStorage) SaveJob(j *job.Job) error {
+	w, err := newRedisWrite(j, "")
+	if err != nil {
+		return err
+	}
+	return s.write(context.Background(), "save", w)
+}
+
+// InsertJob saves a new job, failing with ErrJobExists if its ID is taken
+func (s *RedisStorage) InsertJob(j *job.Job) error {
+	w, err := newRedisWrite(j, "")
+	if err != nil {
+		return err
+	}
+	return s.write(context.Background(), "insert", w)
+}
+
+// SaveJobs saves every job as SaveJob would, or none of them if any fails
+func (s *RedisStorage) SaveJobs(jobs []*job.Job) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+	writes := make([]redisWrite, len(jobs))
+	for i, j := range jobs {
+		w, err := newRedisWrite(j, "")
+		if err != nil {
+			return err
+		}
+		writes[i] = w
+	}
+	return s.write(context.Background(), "save", writes...)
+}
+
+// InsertJobs saves the jobs whose ID is free, or none of them if any
+// fails, and returns the ones whose ID was taken
+func (s *RedisStorage) InsertJobs(jobs []*job.Job) ([]*job.Job, error) {
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+	writes := make([]redisWrite, len(jobs))
+	for i, j := range jobs {
+		w, err := newRedisWrite(j, "")
+		if err != nil {
+			return nil, err
+		}
+		writes[i] = w
+	}
+	skipped, err := s.writeSkipping(context.Background(), "insert_new", writes...)
+	if err != nil {
+		return nil, err
+	}
+
+	return jobsAt(jobs, skipped), nil
+}
+
+// ReplaceJobs saves the jobs not being processed, or none of them if any
+// fails, and returns the ones that are
+func (s *RedisStorage) ReplaceJobs(jobs []*job.Job) ([]*job.Job, error) {
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+	writes := make([]redisWrite, len(jobs))
+	for i, j := range jobs {
+		w, err := newRedisWrite(j, "")
+		if err != nil {
+			return nil, err
+		}
+		writes[i] = w
+	}
+	skipped, err := s.writeSkipping(context.Background(), "replace_idle", writes...)
+	if err != nil {
+		return nil, err
+	}
+
+	return jobsAt(jobs, skipped), nil
+}
+
+// decodeRedisJob parses a stored job
+func decodeRedisJob(raw string) (*job.Job, error) {
+	var j job.Job
+	if err := json.Unmarshal([]byte(raw), &j); err != nil {
+		return nil, fmt.Errorf("failed to decode stored job: %w", err)
+	}
+	return &j, nil
+}
+
+// getRaw returns a job and the JSON it is stored as, or sql.ErrNoRows
+func (s *RedisStorage) getRaw(ctx context.Context, id string) (*job.Job, string, error) {
+	raw, err := s.client.HGet(ctx, rkey("jobs"), id).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, "", sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get job: %w", err)
+	}
+	j, err := decodeRedisJob(raw)
+	return j, raw, err
+}
+
+// loadJobs returns the stored jobs with the given IDs, skipping any that
+// no longer exist, and the JSON each was read as
+func (s *RedisStorage) loadJobs(ctx context.Context, ids []string) ([]*job.Job, map[string]string, error) {
+	raw := make(map[string]string, len(ids))
+	if len(ids) == 0 {
+		return nil, raw, nil
+	}
+	values, err := s.client.HMGet(ctx, rkey("jobs"), ids...).Result()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get jobs: %w", err)
+	}
+
+	jobs := make([]*job.Job, 0, len(values))
+	for _, v := range values {
+		body, ok := v.(string)
+		if !ok {
+			continue // Deleted since its ID was read
+		}
+		j, err := decodeRedisJob(body)
+		if err != nil {
+			return nil, nil, err
+		}
+		jobs = append(jobs, j)
+		raw[j.ID] = body
+	}
+	return jobs, raw, nil
+}
+
+// loadState returns every job in a state and the JSON each was read as
+func (s *RedisStorage) loadState(ctx context.Context, state job.State) ([]*job.Job, map[string]string, error) {
+	ids, err := s.client.SMembers(ctx, rkey("state:"+string(state))).Result()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list %s jobs: %w", state, err)
+	}
+	return s.loadJobs(ctx, ids)
+}
+
+// GetJob retrieves a job by ID. Like SQLiteStorage it returns
+// sql.ErrNoRows if there is no such job.
+func (s *RedisStorage) GetJob(id string) (*job.Job, error) {
+	j, _, err := s.getRaw(context.Background(), id)
+	return j, err
+}
+
+// FindByIdempotencyKey returns the job holding key: the unfinished job
+// with it, or the last job to complete with it if that was less than
+// reuseAfter ago. It returns nil if the key is free.
+func (s *RedisStorage) FindByIdempotencyKey(key string, reuseAfter time.Duration) (*job.Job, error) {
+	ctx := context.Background()
+	cutoff := time.Now().Add(-reuseAfter)
+
+	for _, index := range []string{"idempotency", "idempotency_done"} {
+		id, err := s.client.HGet(ctx, rkey(index), key).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up idempotency key: %w", err)
+		}
+
+		j, _, err := s.getRaw(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		recent := j.State == job.StateCompleted && j.CompletedAt != nil && j.CompletedAt.Unix() > cutoff.Unix()
+		if j.IdempotencyKey == key && (!j.IsTerminal() || recent) {
+			return j, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetNextPendingJob gets the next available job and locks it
+func (s *RedisStorage) GetNextPendingJob(workerID string, filter ClaimFilter) (*job.Job, error) {
+	return firstClaim(s.GetNextPendingJobs(workerID, filter, 1))
+}
+
+// GetNextPendingJobs claims up to n available jobs. Candidates are read
+// in claim order and taken by one compare-and-swap per batch; jobs that
+// another worker took first are skipped and the next candidates tried
+// instead, so concurrent workers do not all come away empty.
+func (s *RedisStorage) GetNextPendingJobs(workerID string, filter ClaimFilter, n int) ([]*job.Job, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	// Return held jobs whose lease has expired to pending, and move jobs
+	// that have come due into the ready set
+	if err := s.releaseHolds(ctx, now); err != nil {
+		return nil, err
+	}
+	if err := s.promoteDue(ctx, now); err != nil {
+		return nil, err
+	}
+
+	paused, err := s.pausedQueues(ctx)
+	if err != nil {
+		return nil, err
+	}
+	match := func(j *job.Job) bool {
+		return matchesFilter(j, filter)
+	}
+	// Jobs claimed by an earlier round are locked, so they are returned
+	// even if a later round fails; the error will recur on the next claim
+	var claimed []*job.Job
+	fail := func(err error) ([]*job.Job, error) {
+		if len(claimed) > 0 {
+			return claimed, nil
+		}
+		return nil, err
+	}
+
+	// Each round reads the candidates afresh and ends early unless other
+	// workers took some of the jobs it picked
+	for round := 0; round < redisCASAttempts && len(claimed) < n; round++ {
+		// Score order is claim order unless aging or weights reorder it. A
+		// few spare candidates are read for the jobs other workers win.
+		limit := 0
+		if s.ageBoostMinutes <= 0 && !s.scheduler.enabled() {
+			limit = n - len(claimed) + redisClaimSpare
+		}
+		candidates, raw, err := s.claimCandidates(ctx, now, paused, match, limit)
+		if err != nil {
+			return fail(err)
+		}
+
+		lostAny := false
+		for len(claimed) < n && len(candidates) > 0 {
+			picked := takeClaims(candidates, n-len(claimed), now, s.ageBoostMinutes, &s.scheduler)
+			candidates = withoutJobs(candidates, picked)
+
+			writes := make([]redisWrite, len(picked))
+			for i, j := range picked {
+				j.State = job.StateProcessing
+				j.WorkerID = workerID
+				j.UpdatedAt = now
+				if writes[i], err = newRedisWrite(j, raw[j.ID]); err != nil {
+					return fail(err)
+				}
+			}
+			lost, err := s.writeSkipping(ctx, "claim", writes...)
+			if err != nil {
+				return fail(fmt.Errorf("failed to lock job: %w", err))
+			}
+			claimed = append(claimed, withoutJobs(picked, jobsAt(picked, lost))...)
+			lostAny = lostAny || len(lost) > 0
+		}
+		if !lostAny {
+			break
+		}
+	}
+	return claimed, nil
+}
+
+// promoteDue moves jobs whose scheduled or retry time has come from
+// queuectl:delayed to queuectl:ready
+func (s *RedisStorage) promoteDue(ctx context.Context, now time.Time) error {
+	if err := redisPromoteScript.Run(ctx, s.client, nil, redisKeyPrefix, now.Unix()).Err(); err != nil {
+		return fmt.Errorf("failed to promote due jobs: %w", err)
+	}
+	return nil
+}
+
+// withoutJobs returns the jobs in jobs that are not in drop, as a new slice
+func withoutJobs(jobs, drop []*job.Job) []*job.Job {
+	kept := make([]*job.Job, 0, len(jobs))
+	for _, j := range jobs {
+		if !slices.Contains(drop, j) {
+			kept = append(kept, j)
+		}
+	}
+	return kept
+}
+
+// jobsAt returns the jobs at the given indexes
+func jobsAt(jobs []*job.Job, indexes []int) []*job.Job {
+	picked := make([]*job.Job, len(indexes))
+	for i, idx := range indexes {
+		picked[i] = jobs[idx]
+	}
+	return picked
+}
+
+// releaseHolds returns held jobs whose hold has expired to pending
+func (s *RedisStorage) releaseHolds(ctx context.Context, now time.Time) error {
+	ids, err := s.client.ZRangeByScore(ctx, rkey("held"), &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.Unix(), 10),
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("failed to release held jobs: %w", err)
+	}
+	jobs, raw, err := s.loadJobs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, j := range jobs {
+		if j.State != job.StateHeld {
+			continue
+		}
+		j.Release()
+		// A job changed meanwhile no longer needs releasing
+		if err := s.swap(ctx, j, raw[j.ID]); err != nil && !errors.Is(err, errRedisConflict) {
+			return fmt.Errorf("failed to release held jobs: %w", err)
+		}
+	}
+	return nil
+}
+
+// claimCandidates reads queuectl:ready in score order and returns the
+// jobs that are due, outside paused queues and accepted by match. With a
+// positive limit it stops after that many such jobs.
+func (s *RedisStorage) claimCandidates(ctx context.Context, now time.Time, paused map[string]time.Time, match func(*job.Job) bool, limit int) ([]*job.Job, map[string]string, error) {
+	raw := make(map[string]string)
+	if _, ok := paused[AllQueues]; ok {
+		return nil, raw, nil
+	}
+
+	var candidates []*job.Job
+	for start := int64(0); ; start += redisClaimPage {
+		members, err := s.client.ZRange(ctx, rkey("ready"), start, start+redisClaimPage-1).Result()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to query next job: %w", err)
+		}
+
+		ids := make([]string, len(members))
+		for i, m := range members {
+			_, ids[i], _ = strings.Cut(m, ":")
+		}
+		jobs, pageRaw, err := s.loadJobs(ctx, ids)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, j := range jobs {
+			if _, ok := paused[j.Queue]; ok || !dueForClaim(j, now) || !match(j) {
+				continue
+			}
+			candidates = append(candidates, j)
+			raw[j.ID] = pageRaw[j.ID]
+			if len(candidates) == limit {
+				return candidates, raw, nil
+			}
+		}
+
+		if len(members) < redisClaimPage {
+			return candidates, raw, nil
+		}
+	}
+}
+
+// PreviewClaimOrder returns up to limit jobs in the order GetNextPendingJob
+// would claim them, without claiming anything. Held jobs whose hold has
+// expired are included since the next claim releases them first. A
+// negative limit returns every candidate.
+func (s *RedisStorage) PreviewClaimOrder(limit int) ([]ClaimCandidate, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	// Due jobs still in queuectl:delayed are claimable, so they are moved
+	// as the next claim would
+	if err := s.promoteDue(ctx, now); err != nil {
+		return nil, err
+	}
+	paused, err := s.pausedQueues(ctx)
+	if err != nil {
+		return nil, err
+	}
+	jobs, _, err := s.claimCandidates(ctx, now, paused, func(*job.Job) bool { return true }, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	held, _, err := s.loadState(ctx, job.StateHeld)
+	if err != nil {
+		return nil, err
+	}
+	_, allPaused := paused[AllQueues]
+	for _, j := range held {
+		_, queuePaused := paused[j.Queue]
+		if j.HeldUntil != nil && notAfter(*j.HeldUntil, now) && !queuePaused && !allPaused {
+			jobs = append(jobs, j)
+		}
+	}
+	sortClaimOrder(jobs, now, s.ageBoostMinutes)
+
+	if limit >= 0 && len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	candidates := make([]ClaimCandidate, len(jobs))
+	for i, j := range jobs {
+		candidates[i] = ClaimCandidate{Job: j, EffectivePriority: agedPriority(j, now, s.ageBoostMinutes)}
+	}
+	return candidates, nil
+}
+
+// ListJobs returns jobs filtered by state, ordered and paged by opts. The
+// filters and order are applied after reading every job in the state.
+func (s *RedisStorage) ListJobs(state job.State, opts ListOptions) ([]*job.Job, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	var jobs []*job.Job
+	if state != "" {
+		var err error
+		if jobs, _, err = s.loadState(ctx, state); err != nil {
+			return nil, err
+		}
+	} else {
+		values, err := s.client.HVals(ctx, rkey("jobs")).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list jobs: %w", err)
+		}
+		for _, raw := range values {
+			j, err := decodeRedisJob(raw)
+			if err != nil {
+				return nil, err
+			}
+			jobs = append(jobs, j)
+		}
+	}
+
+	var matched []*job.Job
+	for _, j := range jobs {
+		if matchesListOptions(j, opts) {
+			matched = append(matched, j)
+		}
+	}
+	return sortAndPage(matched, opts), nil
+}
+
+// GetJobStats returns job counts by state
+func (s *RedisStorage) GetJobStats() (map[job.State]int, error) {
+	ctx := context.Background()
+	cmds := make(map[job.State]*redis.IntCmd, len(job.AllStates))
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for _, state := range job.AllStates {
+			cmds[state] = p.SCard(ctx, rkey("state:"+string(state)))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job stats: %w", err)
+	}
+
+	stats := make(map[job.State]int)
+	for state, cmd := range cmds {
+		if n := int(cmd.Val()); n > 0 {
+			stats[state] = n
+		}
+	}
+	return stats, nil
+}
+
+// GetQueueStats returns job counts by state for each queue
+func (s *RedisStorage) GetQueueStats() (map[string]map[job.State]int, error) {
+	counts, err := s.client.HGetAll(context.Background(), rkey("queue_counts")).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue stats: %w", err)
+	}
+
+	stats := make(map[string]map[job.State]int)
+	for field, value := range counts {
+		state, queue, _ := strings.Cut(field, ":")
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			continue
+		}
+		if stats[queue] == nil {
+			stats[queue] = make(map[job.State]int)
+		}
+		stats[queue][job.State(state)] = n
+	}
+	return stats, nil
+}
+
+// CountFailedAttempts returns the sum of every stored job's attempts
+func (s *RedisStorage) CountFailedAttempts() (int, error) {
+	n, err := s.client.Get(context.Background(), rkey("attempts")).Int()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to count failed attempts: %w", err)
+	}
+	return n, nil
+}
+
+// DeleteJob removes a job. Deleting a missing job is not an error.
+func (s *RedisStorage) DeleteJob(id string) error {
+	if err := redisDeleteScript.Run(context.Background(), s.client, nil, redisKeyPrefix, id, "").Err(); err != nil {
+		return fmt.Errorf("failed to delete job: %w", err)
+	}
+	return nil
+}
+
+// DeleteJobIfState removes a job only if it is in state, deleting it
+// against the copy whose state was checked
+func (s *RedisStorage) DeleteJobIfState(id string, state job.State) error {
+	ctx := context.Background()
+	for i := 0; i < redisCASAttempts; i++ {
+		j, raw, err := s.getRaw(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get job: %w", err)
+		}
+		if j.State != state {
+			return &StateError{ID: id, Want: state, State: j.State}
+		}
+
+		n, err := s.deleteUnchanged(ctx, []*job.Job{j}, map[string]string{id: raw})
+		if err != nil {
+			return err
+		}
+		if n == 1 {
+			return nil
+		}
+	}
+	return fmt.Errorf("job %s kept changing while being deleted, try again", id)
+}
+
+// deleteUnchanged removes the given jobs unless they changed since they
+// were read as raw, returning how many were removed
+func (s *RedisStorage) deleteUnchanged(ctx context.Context, jobs []*job.Job, raw map[string]string) (int, error) {
+	if len(jobs) == 0 {
+		return 0, nil
+	}
+	args := []interface{}{redisKeyPrefix}
+	for _, j := range jobs {
+		args = append(args, j.ID, raw[j.ID])
+	}
+	n, err := redisDeleteScript.Run(ctx, s.client, nil, args...).Int()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete jobs: %w", err)
+	}
+	return n, nil
+}
+
+// SaveJobIfState updates a job only if it is still in state, swapping it
+// against the copy whose state was checked
+func (s *RedisStorage) SaveJobIfState(j *job.Job, state job.State) error {
+	ctx := context.Background()
+	for i := 0; i < redisCASAttempts; i++ {
+		current, raw, err := s.getRaw(ctx, j.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get job: %w", err)
+		}
+		if current.State != state {
+			return &StateError{ID: j.ID, Want: state, State: current.State}
+		}
+
+		err = s.swap(ctx, j, raw)
+		if errors.Is(err, errRedisConflict) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save job: %w", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("job %s kept changing while being saved, try again", j.ID)
+}
+
+// SaveJobIfOwner updates a job only if it is still processing under
+// workerID, swapping it against the copy that was checked
+func (s *RedisStorage) SaveJobIfOwner(j *job.Job, workerID string) error {
+	ctx := context.Background()
+	for i := 0; i < redisCASAttempts; i++ {
+		current, raw, err := s.getRaw(ctx, j.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("job %s: %w", j.ID, ErrNotClaimed)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get job: %w", err)
+		}
+		if current.State != job.StateProcessing || current.WorkerID != workerID {
+			return fmt.Errorf("job %s: %w", j.ID, ErrNotClaimed)
+		}
+
+		err = s.swap(ctx, j, raw)
+		if errors.Is(err, errRedisConflict) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save job: %w", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("job %s kept changing while being saved, try again", j.ID)
+}
+
+// CancelJob cancels a waiting job, or records a cancel request for a
+// processing one. It reports whether the job was cancelled immediately.
+func (s *RedisStorage) CancelJob(id string) (bool, error) {
+	ctx := context.Background()
+	for i := 0; i < redisCASAttempts; i++ {
+		j, raw, err := s.getRaw(ctx, id)
+		if err != nil {
+			return false, fmt.Errorf("failed to get job: %w", err)
+		}
+
+		switch j.State {
+		case job.StatePending, job.StateHeld, job.StateFailed:
+			j.MarkAsCancelled()
+			err := s.swap(ctx, j, raw)
+			if errors.Is(err, errRedisConflict) {
+				continue
+			}
+			if err != nil {
+				return false, fmt.Errorf("failed to cancel job: %w", err)
+			}
+			return true, nil
+		case job.StateProcessing:
+			recorded, err := redisCancelScript.Run(ctx, s.client, nil, redisKeyPrefix, id).Int()
+			if err != nil {
+				return false, fmt.Errorf("failed to request cancel: %w", err)
+			}
+			if recorded == 1 {
+				return false, nil
+			}
+		default:
+			return false, fmt.Errorf("job %s cannot be cancelled (state: %s)", id, j.State)
+		}
+	}
+	return false, fmt.Errorf("job %s kept changing while being cancelled, try again", id)
+}
+
+// Heartbeat refreshes the updated_at of a processing job
+func (s *RedisStorage) Heartbeat(id string) error {
+	ctx := context.Background()
+	j, raw, err := s.getRaw(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if j.State != job.StateProcessing {
+		return nil
+	}
+
+	j.UpdatedAt = time.Now()
+	// A job changed meanwhile has been saved by its worker, which is
+	// fresher than a heartbeat
+	if err := s.swap(ctx, j, raw); err != nil && !errors.Is(err, errRedisConflict) {
+		return fmt.Errorf("failed to record heartbeat: %w", err)
+	}
+	return nil
+}
+
+// SetProgress records the percent done of a processing job. Heartbeats
+// rewrite the same job, so a conflicting write is retried.
+func (s *RedisStorage) SetProgress(id string, progress int) error {
+	ctx := context.Background()
+	for i := 0; i < redisCASAttempts; i++ {
+		j, raw, err := s.getRaw(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if j.State != job.StateProcessing {
+			return nil
+		}
+
+		j.Progress = progress
+		err = s.swap(ctx, j, raw)
+		if errors.Is(err, errRedisConflict) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to record progress: %w", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("job %s kept changing while recording progress", id)
+}
+
+// RecoverStaleJobs requeues processing jobs without a heartbeat since
+// threshold ago
+func (s *RedisStorage) RecoverStaleJobs(threshold time.Duration) ([]*job.Job, error) {
+	ctx := context.Background()
+	jobs, raw, err := s.loadState(ctx, job.StateProcessing)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-threshold)
+	var recovered []*job.Job
+	for _, j := range jobs {
+		if !notAfter(j.UpdatedAt, cutoff) {
+			continue
+		}
+		stale := cloneJob(j)
+		j.Requeue(staleReason(j, threshold))
+		err := s.swap(ctx, j, raw[j.ID])
+		if errors.Is(err, errRedisConflict) {
+			continue // Its worker saved it meanwhile, so it is not stale
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to requeue stale job: %w", err)
+		}
+		recovered = append(recovered, stale)
+	}
+	return recovered, nil
+}
+
+// IsCancelRequested reports whether the processing job has been cancelled
+func (s *RedisStorage) IsCancelRequested(id string) (bool, error) {
+	requested, err := s.client.SIsMember(context.Background(), rkey("cancels"), id).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check cancel request: %w", err)
+	}
+	return requested, nil
+}
+
+// DeleteCompletedBefore removes completed jobs that finished before cutoff
+func (s *RedisStorage) DeleteCompletedBefore(cutoff time.Time) (int, error) {
+	ctx := context.Background()
+	ids, err := s.client.ZRangeByScore(ctx, rkey("completed"), &redis.ZRangeBy{
+		Min: "-inf",
+		Max: "(" + strconv.FormatInt(cutoff.Unix(), 10),
+	}).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete completed jobs: %w", err)
+	}
+	jobs, raw, err := s.loadJobs(ctx, ids)
+	if err != nil {
+		return 0, err
+	}
+	return s.deleteUnchanged(ctx, jobs, raw)
+}
+
+// DeleteJobsByState removes jobs in state last updated before olderThan
+func (s *RedisStorage) DeleteJobsByState(state job.State, olderThan time.Time) (int, error) {
+	ctx := context.Background()
+	jobs, raw, err := s.loadState(ctx, state)
+	if err != nil {
+		return 0, err
+	}
+
+	var old []*job.Job
+	for _, j := range jobs {
+		if j.UpdatedAt.Unix() < olderThan.Unix() {
+			old = append(old, j)
+		}
+	}
+	return s.deleteUnchanged(ctx, old, raw)
+}
+
+// GetRetryableJobs returns failed jobs ready to retry, soonest first
+func (s *RedisStorage) GetRetryableJobs() ([]*job.Job, error) {
+	failed, _, err := s.loadState(context.Background(), job.StateFailed)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var jobs []*job.Job
+	for _, j := range failed {
+		if j.NextRetryAt != nil && notAfter(*j.NextRetryAt, now) {
+			jobs = append(jobs, j)
+		}
+	}
+	sort.Slice(jobs, func(a, b int) bool {
+		ra, rb := jobs[a].NextRetryAt.Unix(), jobs[b].NextRetryAt.Unix()
+		if ra != rb {
+			return ra < rb
+		}
+		return jobs[a].Seq < jobs[b].Seq
+	})
+	return jobs, nil
+}
+
+// GetDLQJobs returns all dead jobs
+func (s *RedisStorage) GetDLQJobs() ([]*job.Job, error) {
+	return s.ListJobs(job.StateDead, ListOptions{})
+}
+
+// GetThroughput counts completed jobs per bucket, starting from since.
+// Buckets are aligned to since and every bucket up to now is returned,
+// including empty ones.
+func (s *RedisStorage) GetThroughput(since time.Time, bucket time.Duration) ([]ThroughputBucket, error) {
+	size := int64(bucket / time.Second)
+	if size <= 0 {
+		return nil, fmt.Errorf("bucket size must be at least one second")
+	}
+
+	start := since.Unix()
+	completed, err := s.client.ZRangeByScoreWithScores(context.Background(), rkey("completed"), &redis.ZRangeBy{
+		Min: strconv.FormatInt(start, 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query throughput: %w", err)
+	}
+
+	counts := make(map[int64]int)
+	for _, z := range completed {
+		counts[(int64(z.Score)-start)/size]++
+	}
+
+	n := (time.Now().Unix()-start)/size + 1
+	buckets := make([]ThroughputBucket, n)
+	for i := range buckets {
+		buckets[i] = ThroughputBucket{
+			Start: time.Unix(start+int64(i)*size, 0),
+			Count: counts[int64(i)],
+		}
+	}
+	return buckets, nil
+}
+
+// PauseQueue marks a queue as paused. Pausing a paused queue keeps its
+// original pause time.
+func (s *RedisStorage) PauseQueue(queue string) error {
+	if err := s.client.HSetNX(context.Background(), rkey("paused"), queue, time.Now().Unix()).Err(); err != nil {
+		return fmt.Errorf("failed to pause queue: %w", err)
+	}
+	return nil
+}
+
+// ResumeQueue clears a queue's paused flag
+func (s *RedisStorage) ResumeQueue(queue string) error {
+	if err := s.client.HDel(context.Background(), rkey("paused"), queue).Err(); err != nil {
+		return fmt.Errorf("failed to resume queue: %w", err)
+	}
+	return nil
+}
+
+// pausedQueues returns each paused queue with the time it was paused
+func (s *RedisStorage) pausedQueues(ctx context.Context) (map[string]time.Time, error) {
+	fields, err := s.client.HGetAll(ctx, rkey("paused")).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list paused queues: %w", err)
+	}
+	paused := make(map[string]time.Time, len(fields))
+	for queue, value := range fields {
+		at, _ := strconv.ParseInt(value, 10, 64)
+		paused[queue] = time.Unix(at, 0)
+	}
+	return paused, nil
+}
+
+// ListPausedQueues returns paused queue names in the order they were paused
+func (s *RedisStorage) ListPausedQueues() ([]string, error) {
+	paused, err := s.pausedQueues(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	var queues []string
+	for queue := range paused {
+		queues = append(queues, queue)
+	}
+	sort.Slice(queues, func(a, b int) bool {
+		ta, tb := paused[queues[a]], paused[queues[b]]
+		if !ta.Equal(tb) {
+			return ta.Before(tb)
+		}
+		return queues[a] < queues[b]
+	})
+	return queues, nil
+}
+
+// redisSchedule is a schedule as stored in queuectl:schedules. Its last
+// fire time is kept apart in queuectl:schedule_fired.
+type redisSchedule struct {
+	ID        string `json:"id"`
+	Cron      string `json:"cron"`
+	Command   string `json:"command"`
+	Queue     string `json:"queue"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// AddSchedule stores a new schedule, failing with ErrScheduleExists if
+// the ID is taken
+func (s *RedisStorage) AddSchedule(sc *Schedule) error {
+	data, err := json.Marshal(redisSchedule{
+		ID:        sc.ID,
+		Cron:      sc.Cron,
+		Command:   sc.Command,
+		Queue:     sc.Queue,
+		CreatedAt: sc.CreatedAt.Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	added, err := s.client.HSetNX(ctx, rkey("schedules"), sc.ID, data).Result()
+	if err != nil {
+		return fmt.Errorf("failed to add schedule: %w", err)
+	}
+	if !added {
+		return fmt.Errorf("schedule %s: %w", sc.ID, ErrScheduleExists)
+	}
+	if sc.LastFiredAt != nil {
+		return s.MarkScheduleFired(sc.ID, *sc.LastFiredAt)
+	}
+	return nil
+}
+
+// ListSchedules returns every schedule, oldest first
+func (s *RedisStorage) ListSchedules() ([]*Schedule, error) {
+	ctx := context.Background()
+	stored, err := s.client.HGetAll(ctx, rkey("schedules")).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+	fired, err := s.client.HGetAll(ctx, rkey("schedule_fired")).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+
+	var schedules []*Schedule
+	for _, data := range stored {
+		var rs redisSchedule
+		if err := json.Unmarshal([]byte(data), &rs); err != nil {
+			return nil, fmt.Errorf("failed to decode stored schedule: %w", err)
+		}
+		sc := &Schedule{
+			ID:        rs.ID,
+			Cron:      rs.Cron,
+			Command:   rs.Command,
+			Queue:     rs.Queue,
+			CreatedAt: time.Unix(rs.CreatedAt, 0),
+		}
+		if v, ok := fired[rs.ID]; ok {
+			at, _ := strconv.ParseInt(v, 10, 64)
+			t := time.Unix(at, 0)
+			sc.LastFiredAt = &t
+		}
+		schedules = append(schedules, sc)
+	}
+	sort.Slice(schedules, func(a, b int) bool {
+		if !schedules[a].CreatedAt.Equal(schedules[b].CreatedAt) {
+			return schedules[a].CreatedAt.Before(schedules[b].CreatedAt)
+		}
+		return schedules[a].ID < schedules[b].ID
+	})
+	return schedules, nil
+}
+
+// DeleteSchedule removes a schedule, returning sql.ErrNoRows if there is
+// none with the ID
+func (s *RedisStorage) DeleteSchedule(id string) error {
+	ctx := context.Background()
+	var deleted *redis.IntCmd
+	_, err := s.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		deleted = p.HDel(ctx, rkey("schedules"), id)
+		p.HDel(ctx, rkey("schedule_fired"), id)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete schedule: %w", err)
+	}
+	if deleted.Val() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// MarkScheduleFired records the latest fire time a schedule enqueued its
+// job for, ignoring times before the recorded one
+func (s *RedisStorage) MarkScheduleFired(id string, at time.Time) error {
+	if err := redisFireScript.Run(context.Background(), s.client, nil, redisKeyPrefix, id, at.Unix()).Err(); err != nil {
+		return fmt.Errorf("failed to update schedule: %w", err)
+	}
+	return nil
+}
+
+// RecordStats stores the current job count of every state as a sample
+// taken at the given time, and deletes the samples older than retention
+// (0 keeps them all). Nothing is recorded while there are no jobs.
+func (s *RedisStorage) RecordStats(at time.Time, retention time.Duration) error {
+	counts, err := s.GetJobStats()
+	if err != nil {
+		return err
+	}
+	var data []byte
+	if len(counts) > 0 {
+		if data, err = json.Marshal(counts); err != nil {
+			return err
+		}
+	}
+
+	ctx := context.Background()
+	var expired []string
+	if retention > 0 {
+		// "(" excludes the cutoff itself
+		expired, err = s.client.ZRangeByScore(ctx, rkey("stats_times"), &redis.ZRangeBy{
+			Min: "-inf",
+			Max: "(" + strconv.FormatInt(at.Add(-retention).Unix(), 10),
+		}).Result()
+		if err != nil {
+			return fmt.Errorf("failed to prune stats history: %w", err)
+		}
+	}
+	if data == nil && len(expired) == 0 {
+		return nil
+	}
+
+	t := at.Unix()
+	_, err = s.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		if data != nil {
+			p.HSet(ctx, rkey("stats"), strconv.FormatInt(t, 10), data)
+			p.ZAdd(ctx, rkey("stats_times"), redis.Z{Score: float64(t), Member: t})
+		}
+		if len(expired) > 0 {
+			members := make([]interface{}, len(expired))
+			for i, m := range expired {
+				members[i] = m
+			}
+			p.HDel(ctx, rkey("stats"), expired...)
+			p.ZRem(ctx, rkey("stats_times"), members...)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record stats: %w", err)
+	}
+	return nil
+}
+
+// GetStatsHistory returns the samples recorded since the given time,
+// oldest first
+func (s *RedisStorage) GetStatsHistory(since time.Time) ([]StatsSample, error) {
+	ctx := context.Background()
+	times, err := s.client.ZRangeByScore(ctx, rkey("stats_times"), &redis.ZRangeBy{
+		Min: strconv.FormatInt(since.Unix(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stats history: %w", err)
+	}
+	if len(times) == 0 {
+		return nil, nil
+	}
+	values, err := s.client.HMGet(ctx, rkey("stats"), times...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stats history: %w", err)
+	}
+
+	var samples []StatsSample
+	for i, v := range values {
+		data, ok := v.(string)
+		if !ok {
+			continue
+		}
+		t, err := strconv.ParseInt(times[i], 10, 64)
+		if err != nil {
+			continue
+		}
+		var counts map[job.State]int
+		if err := json.Unmarshal([]byte(data), &counts); err != nil {
+			return nil, fmt.Errorf("failed to decode stats sample: %w", err)
+		}
+		samples = append(samples, StatsSample{Time: time.Unix(t, 0), Counts: counts})
+	}
+	return samples, nil
+}
+
+// Optimize has nothing to do for Redis storage, whose indexes are updated
+// with every write
+func (s *RedisStorage) Optimize() ([]string, error) {
+	return []string{"nothing to optimize for Redis storage"}, nil
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+)
+
+func TestReplaceJobsSkipsProcessing(t *testing.T) {
+	for name, s := range testBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			claimed := job.NewJob("echo claimed", 3)
+			idle := job.NewJob("echo idle", 3)
+			if err := s.SaveJobs([]*job.Job{claimed, idle}); err != nil {
+				t.Fatal(err)
+			}
+			got, err := s.GetNextPendingJob("w1", ClaimFilter{})
+			if err != nil || got == nil || got.ID != claimed.ID {
+				t.Fatalf("claim = %v, %v, want %s", got, err, claimed.ID)
+			}
+
+			replacements := make([]*job.Job, 0, 3)
+			for _, id := range []string{claimed.ID, idle.ID, "new"} {
+				j := job.NewJob("echo replaced", 3)
+				j.ID = id
+				replacements = append(replacements, j)
+			}
+			busy, err := s.ReplaceJobs(replacements)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ids := jobIDs(busy); !slices.Equal(ids, []string{claimed.ID}) {
+				t.Errorf("skipped %v, want [%s]", ids, claimed.ID)
+			}
+
+			for id, want := range map[string]string{claimed.ID: "echo claimed", idle.ID: "echo replaced", "new": "echo replaced"} {
+				j, err := s.GetJob(id)
+				if err != nil {
+					t.Fatalf("%s: %v", id, err)
+				}
+				if j.Command != want {
+					t.Errorf("%s: command = %q, want %q", id, j.Command, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSaveJobIfOwner(t *testing.T) {
+	for name, s := range testBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			j := job.NewJob("echo owned", 3)
+			if err := s.SaveJob(j); err != nil {
+				t.Fatal(err)
+			}
+			claimed, err := s.GetNextPendingJob("w1", ClaimFilter{})
+			if err != nil || claimed == nil {
+				t.Fatalf("claim = %v, %v", claimed, err)
+			}
+			claimed.MarkAsProcessing("w1")
+
+			if err := s.SaveJobIfOwner(claimed, "w2"); !errors.Is(err, ErrNotClaimed) {
+				t.Errorf("save by another worker = %v, want ErrNotClaimed", err)
+			}
+			claimed.MarkAsCompleted("done")
+			if err := s.SaveJobIfOwner(claimed, "w1"); err != nil {
+				t.Fatalf("save by owner = %v", err)
+			}
+			// Completed, the job is no longer any worker's
+			if err := s.SaveJobIfOwner(claimed, "w1"); !errors.Is(err, ErrNotClaimed) {
+				t.Errorf("second save = %v, want ErrNotClaimed", err)
+			}
+
+			if err := s.DeleteJob(j.ID); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.SaveJobIfOwner(claimed, "w1"); !errors.Is(err, ErrNotClaimed) {
+				t.Errorf("save of deleted job = %v, want ErrNotClaimed", err)
+			}
+			if _, err := s.GetJob(j.ID); err == nil {
+				t.Error("deleted job was saved again")
+			}
+		})
+	}
+}
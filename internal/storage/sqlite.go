@@ -2,18 +2,92 @@ package storage
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/MithileshwaranS/queuectl/internal/job"
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// jobColumns is the column list shared by every job SELECT
+const jobColumns = `id, seq, retry_of, queue, command, fallback_command, state, attempts, max_retries, timeout_seconds, backoff_base, priority, env_file, retry_on_timeout_only, sandbox, success_pattern, failure_pattern, created_at, updated_at, next_retry_at, scheduled_at, held_until, completed_at, worker_id, error, error_type, output, history, next_job, parent_id, parent_output, retry_schedule, cpu_time_ms, max_rss_kb, exit_code, env, work_dir, tags, duration_ms, idempotency_key, progress`
+
+// jobIndex describes an index on the jobs table. A unique index with a
+// where clause only constrains the rows matching it.
+type jobIndex struct {
+	name    string
+	columns string
+	unique  bool
+	where   string
+}
+
+// jobIndexes lists every index on the jobs table
+var jobIndexes = []jobIndex{
+	{name: "idx_jobs_state", columns: "state"},
+	{name: "idx_jobs_next_retry", columns: "next_retry_at"},
+	{name: "idx_jobs_worker", columns: "worker_id"},
+	{name: "idx_jobs_scheduled", columns: "scheduled_at"},
+	{name: "idx_jobs_completed", columns: "completed_at"},
+	{name: "idx_jobs_claim_order", columns: "state, priority DESC, created_at"},
+	{name: "idx_jobs_queue", columns: "queue, state"},
+	// At most one unfinished job per idempotency key; finished jobs keep
+	// their key so FindByIdempotencyKey can still return them
+	{name: "idx_jobs_idempotency_key", columns: "idempotency_key", unique: true,
+		where: "idempotency_key IS NOT NULL AND " + unfinishedWhere},
+}
+
+// unfinishedWhere matches jobs that have not reached a terminal state, as
+// job.IsTerminal defines them
+const unfinishedWhere = "state NOT IN ('completed', 'dead', 'archived', 'cancelled')"
+
+// createSQL returns the statement that creates the index if it is missing
+func (idx jobIndex) createSQL() string {
+	create := "CREATE INDEX"
+	if idx.unique {
+		create = "CREATE UNIQUE INDEX"
+	}
+	query := fmt.Sprintf("%s IF NOT EXISTS %s ON jobs(%s)", create, idx.name, idx.columns)
+	if idx.where != "" {
+		query += " WHERE " + idx.where
+	}
+	return query
+}
+
 // SQLiteStorage implements Storage interface using SQLite
 type SQLiteStorage struct {
 	db *sql.DB
+
+	// ageBoostMinutes raises a waiting job's effective priority by one for
+	// every interval of this many minutes since it was created (0 disables)
+	ageBoostMinutes int
+
+	// scheduler enables weighted fair scheduling between queues (without
+	// weights, claims span all queues by priority)
+	scheduler queueScheduler
+
+	// busyRetries is how many times SaveJob and job claims are retried
+	// when the database stays locked past busy_timeout
+	busyRetries int
+
+	// releasedAt is when claims last returned expired holds to pending,
+	// in Unix nanoseconds
+	releasedAt atomic.Int64
 }
 
+var _ Storage = (*SQLiteStorage)(nil)
+
+// releaseInterval is how often claims return held jobs whose hold has
+// expired to pending
+const releaseInterval = time.Second
+
+// busyBackoff is the delay before the first retry of a busy operation; it
+// doubles with every further retry
+const busyBackoff = 50 * time.Millisecond
+
 // NewSQLiteStorage creates a new SQLite storage instance
 func NewSQLiteStorage(dbPath string) (*SQLiteStorage, error) {
 	db, err := sql.Open("sqlite3", dbPath+"?_journal_mode=WAL&_busy_timeout=5000")
@@ -29,285 +103,1455 @@ func NewSQLiteStorage(dbPath string) (*SQLiteStorage, error) {
 	return &SQLiteStorage{db: db}, nil
 }
 
-// Initialize creates the necessary tables
+// IsConnectionError reports whether err means the database file or Redis
+// server could not be reached (e.g. a network mount went away), as opposed
+// to a problem with the query or the data. Such errors may clear after
+// re-opening.
+func IsConnectionError(err error) bool {
+	if errors.Is(err, sql.ErrConnDone) || isRedisConnectionError(err) {
+		return true
+	}
+	// Match SQLite's messages for SQLITE_CANTOPEN and SQLITE_IOERR rather
+	// than the driver's error type, which only exists in cgo builds
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "unable to open database file") || strings.Contains(msg, "disk I/O error")
+}
+
+// IsBusyError reports whether err means the SQLite database was locked by
+// another connection (SQLITE_BUSY or SQLITE_LOCKED), so the same operation
+// may succeed if tried again. busy_timeout does not cover every case: in
+// WAL mode a transaction that read a snapshot older than the last write
+// fails at once when it tries to write.
+func IsBusyError(err error) bool {
+	if err == nil {
+		return false
+	}
+	// Matched by message for the same reason as in IsConnectionError
+	msg := err.Error()
+	return strings.Contains(msg, "database is locked") || strings.Contains(msg, "database table is locked")
+}
+
+// retryBusy runs op, running it again with exponential backoff up to
+// busyRetries times while it fails with a busy error. Other errors are
+// returned at once.
+func (s *SQLiteStorage) retryBusy(op func() error) error {
+	delay := busyBackoff
+	for attempt := 0; ; attempt++ {
+		err := op()
+		if !IsBusyError(err) || attempt >= s.busyRetries {
+			return err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// Initialize brings the database schema up to date, creating it if the
+// database is new
 func (s *SQLiteStorage) Initialize() error {
-	schema := `
-	CREATE TABLE IF NOT EXISTS jobs (
-		id TEXT PRIMARY KEY,
-		command TEXT NOT NULL,
-		state TEXT NOT NULL,
-		attempts INTEGER NOT NULL DEFAULT 0,
-		max_retries INTEGER NOT NULL DEFAULT 3,
-		created_at DATETIME NOT NULL,
-		updated_at DATETIME NOT NULL,
-		next_retry_at DATETIME,
-		worker_id TEXT,
-		error TEXT,
-		output TEXT
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_jobs_state ON jobs(state);
-	CREATE INDEX IF NOT EXISTS idx_jobs_next_retry ON jobs(next_retry_at);
-	CREATE INDEX IF NOT EXISTS idx_jobs_worker ON jobs(worker_id);
-	`
+	if err := s.migrate(); err != nil {
+		return err
+	}
 
-	_, err := s.db.Exec(schema)
-	if err != nil {
-		return fmt.Errorf("failed to create schema: %w", err)
+	// Indexes come last since some cover columns added by migrations
+	for _, idx := range jobIndexes {
+		if _, err := s.db.Exec(idx.createSQL()); err != nil {
+			return fmt.Errorf("failed to create schema: %w", err)
+		}
 	}
 
 	return nil
 }
 
+// Optimize recreates any missing indexes and refreshes the query planner
+// statistics. It returns a description of each step taken.
+func (s *SQLiteStorage) Optimize() ([]string, error) {
+	var steps []string
+
+	for _, idx := range jobIndexes {
+		var count int
+		err := s.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = ?`, idx.name).Scan(&count)
+		if err != nil {
+			return steps, fmt.Errorf("failed to inspect index %s: %w", idx.name, err)
+		}
+		if count > 0 {
+			continue
+		}
+		if _, err := s.db.Exec(idx.createSQL()); err != nil {
+			return steps, fmt.Errorf("failed to create index %s: %w", idx.name, err)
+		}
+		steps = append(steps, fmt.Sprintf("recreated missing index %s on jobs(%s)", idx.name, idx.columns))
+	}
+
+	if _, err := s.db.Exec(`ANALYZE`); err != nil {
+		return steps, fmt.Errorf("failed to analyze database: %w", err)
+	}
+	steps = append(steps, "analyzed tables and indexes (ANALYZE)")
+
+	if _, err := s.db.Exec(`PRAGMA optimize`); err != nil {
+		return steps, fmt.Errorf("failed to optimize database: %w", err)
+	}
+	steps = append(steps, "ran PRAGMA optimize")
+
+	return steps, nil
+}
+
+// SetAgePriorityBoost configures anti-starvation aging for job claims.
+// A waiting job gains one priority level for every interval it has waited.
+func (s *SQLiteStorage) SetAgePriorityBoost(interval time.Duration) {
+	s.ageBoostMinutes = int(interval / time.Minute)
+}
+
+// SetQueueWeights enables weighted fair scheduling: each claim first picks
+// a queue with claimable jobs by smooth weighted round-robin, then claims
+// the top job of that queue. Queues without a weight count as 1.
+func (s *SQLiteStorage) SetQueueWeights(weights map[string]int) {
+	s.scheduler.set(weights)
+}
+
+// SetBusyRetries sets how many times SaveJob and job claims are retried
+// when they fail because the database is locked (0 disables retries)
+func (s *SQLiteStorage) SetBusyRetries(n int) {
+	s.busyRetries = n
+}
+
+// pickQueue chooses the queue to claim from among those with claimable
+// jobs. It returns "" if no queue has any.
+func (s *SQLiteStorage) pickQueue(tx *sql.Tx, now, filterSQL string, filterArgs []interface{}) (string, error) {
+	query := `SELECT DISTINCT queue FROM jobs WHERE ` + claimWhere + ` AND ` + filterSQL
+	rows, err := tx.Query(query, append(claimArgs(now), filterArgs...)...)
+	if err != nil {
+		return "", fmt.Errorf("failed to query claimable queues: %w", err)
+	}
+	defer rows.Close()
+
+	var queues []string
+	for rows.Next() {
+		var q string
+		if err := rows.Scan(&q); err != nil {
+			return "", err
+		}
+		queues = append(queues, q)
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	return s.scheduler.pick(queues), nil
+}
+
+// claimWhere selects jobs that can be claimed now. Its arguments are
+// returned by claimArgs.
+const claimWhere = `((state = ? AND (scheduled_at IS NULL OR scheduled_at <= ?)) OR (state = ? AND next_retry_at <= ?))
+	AND NOT EXISTS (SELECT 1 FROM paused_queues p WHERE p.queue = jobs.queue OR p.queue = ?)`
+
+// claimArgs returns the arguments for claimWhere
+func claimArgs(now string) []interface{} {
+	return []interface{}{job.StatePending, now, job.StateFailed, now, AllQueues}
+}
+
+// filterWhere returns the SQL condition and arguments for a claim filter.
+// Prefixes are compared with substr rather than LIKE so they match
+// case-sensitively and need no escaping.
+func filterWhere(filter ClaimFilter) (string, []interface{}) {
+	var where []string
+	var args []interface{}
+
+	if len(filter.CommandPrefixes) > 0 {
+		conds := make([]string, len(filter.CommandPrefixes))
+		for i, prefix := range filter.CommandPrefixes {
+			conds[i] = "substr(command, 1, length(?)) = ?"
+			args = append(args, prefix, prefix)
+		}
+		where = append(where, "("+strings.Join(conds, " OR ")+")")
+	}
+
+	if len(filter.Queues) > 0 {
+		marks := make([]string, len(filter.Queues))
+		for i, queue := range filter.Queues {
+			marks[i] = "?"
+			args = append(args, queue)
+		}
+		where = append(where, "queue IN ("+strings.Join(marks, ", ")+")")
+	}
+
+	if len(where) == 0 {
+		return "1 = 1", nil
+	}
+	return strings.Join(where, " AND "), args
+}
+
+// effectivePriority returns the SQL expression for a job's claim priority,
+// including any aging boost
+func (s *SQLiteStorage) effectivePriority() string {
+	if s.ageBoostMinutes > 0 {
+		return fmt.Sprintf("priority + CAST((julianday('now') - julianday(created_at)) * 1440 / %d AS INTEGER)", s.ageBoostMinutes)
+	}
+	return "priority"
+}
+
+// claimOrder returns the ORDER BY expression used to pick the next job
+func (s *SQLiteStorage) claimOrder() string {
+	return s.effectivePriority() + " DESC, created_at ASC, seq ASC"
+}
+
+// ClaimQuery renders the SELECT that GetNextPendingJob runs to pick the
+// next job at now (an RFC3339 timestamp). With queue weights set the claim
+// is restricted to queue, which GetNextPendingJob chooses per claim.
+func (s *SQLiteStorage) ClaimQuery(now string, filter ClaimFilter, queue string) (string, []interface{}) {
+	filterSQL, filterArgs := filterWhere(filter)
+	if s.scheduler.enabled() {
+		filterSQL += " AND queue = ?"
+		filterArgs = append(filterArgs, queue)
+	}
+
+	query := `
+	SELECT ` + jobColumns + `
+	FROM jobs 
+	WHERE ` + claimWhere + ` AND ` + filterSQL + `
+	ORDER BY ` + s.claimOrder() + `
+	LIMIT 1
+	`
+	return query, append(claimArgs(now), filterArgs...)
+}
+
 // Close closes the database connection
 func (s *SQLiteStorage) Close() error {
 	return s.db.Close()
 }
 
-// SaveJob inserts or updates a job
+// SaveJob inserts or updates a job, retrying while the database is locked
 func (s *SQLiteStorage) SaveJob(j *job.Job) error {
-	query := `
-	INSERT INTO jobs (id, command, state, attempts, max_retries, created_at, updated_at, next_retry_at, worker_id, error, output)
-	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	ON CONFLICT(id) DO UPDATE SET
+	return s.retryBusy(func() error {
+		return saveJob(s.db, j)
+	})
+}
+
+// SaveJobIfState updates a job only if it is still in state. The check
+// and the write share a transaction, so a claim in between fails the
+// write rather than being overwritten.
+func (s *SQLiteStorage) SaveJobIfState(j *job.Job, state job.State) error {
+	return s.retryBusy(func() error {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		if err := checkState(tx, j.ID, state); err != nil {
+			return err
+		}
+		if err := saveJob(tx, j); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+}
+
+// InsertJobs creates every job whose ID is free in one transaction and
+// returns the ones whose ID was taken
+func (s *SQLiteStorage) InsertJobs(jobs []*job.Job) ([]*job.Job, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var taken []*job.Job
+	for _, j := range jobs {
+		err := insertJob(tx, j)
+		if errors.Is(err, ErrJobExists) {
+			taken = append(taken, j)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("job %s: %w", j.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return taken, nil
+}
+
+// ReplaceJobs creates or replaces, in one transaction, every job not
+// being processed and returns the ones that are. Reading the state inside
+// the transaction keeps a worker's claim from being overwritten.
+func (s *SQLiteStorage) ReplaceJobs(jobs []*job.Job) ([]*job.Job, error) {
+	var busy []*job.Job
+	err := s.retryBusy(func() error {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		busy = nil
+		for _, j := range jobs {
+			var current job.State
+			err := tx.QueryRow(`SELECT state FROM jobs WHERE id = ?`, j.ID).Scan(&current)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("job %s: failed to get job: %w", j.ID, err)
+			}
+			if current == job.StateProcessing {
+				busy = append(busy, j)
+				continue
+			}
+			if err := saveJob(tx, j); err != nil {
+				return fmt.Errorf("job %s: %w", j.ID, err)
+			}
+		}
+		return tx.Commit()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return busy, nil
+}
+
+// SaveJobIfOwner updates a job only if it is still processing under
+// workerID, checking and writing in one transaction
+func (s *SQLiteStorage) SaveJobIfOwner(j *job.Job, workerID string) error {
+	return s.retryBusy(func() error {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		var state job.State
+		var owner sql.NullString
+		err = tx.QueryRow(`SELECT state, worker_id FROM jobs WHERE id = ?`, j.ID).Scan(&state, &owner)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("job %s: %w", j.ID, ErrNotClaimed)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get job: %w", err)
+		}
+		if state != job.StateProcessing || owner.String != workerID {
+			return fmt.Errorf("job %s: %w", j.ID, ErrNotClaimed)
+		}
+		if err := saveJob(tx, j); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+}
+
+// rowQuerier is satisfied by both *sql.DB and *sql.Tx
+type rowQuerier interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// checkState returns a *StateError unless job id is stored in state
+func checkState(db rowQuerier, id string, state job.State) error {
+	var current job.State
+	if err := db.QueryRow(`SELECT state FROM jobs WHERE id = ?`, id).Scan(&current); err != nil {
+		return fmt.Errorf("failed to get job: %w", err)
+	}
+	if current != state {
+		return &StateError{ID: id, Want: state, State: current}
+	}
+	return nil
+}
+
+// InsertJob adds a new job, failing with ErrJobExists if the ID is taken
+func (s *SQLiteStorage) InsertJob(j *job.Job) error {
+	return insertJob(s.db, j)
+}
+
+// SaveJobs creates or updates every job in one transaction, so either all
+// of them are saved or none are
+func (s *SQLiteStorage) SaveJobs(jobs []*job.Job) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, j := range jobs {
+		if err := saveJob(tx, j); err != nil {
+			return fmt.Errorf("job %s: %w", j.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// saveJob upserts a job through db
+func saveJob(db execer, j *job.Job) error {
+	if _, err := writeJob(db, j, jobUpsertClause); err != nil {
+		return fmt.Errorf("failed to save job: %w", err)
+	}
+
+	// A pending cancellation is moot once the job stops processing
+	if j.State != job.StateProcessing {
+		if _, err := db.Exec(`DELETE FROM cancel_requests WHERE job_id = ?`, j.ID); err != nil {
+			return fmt.Errorf("failed to clear cancel request: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// insertJob adds a new job through db, returning ErrJobExists instead of
+// replacing a stored job with the same ID
+func insertJob(db execer, j *job.Job) error {
+	result, err := writeJob(db, j, `ON CONFLICT(id) DO NOTHING`)
+	if err != nil {
+		return fmt.Errorf("failed to insert job: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to insert job: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("job %s: %w", j.ID, ErrJobExists)
+	}
+	return nil
+}
+
+// jobUpsertClause makes writeJob update every column of a stored job
+// except seq and created_at
+const jobUpsertClause = `ON CONFLICT(id) DO UPDATE SET
+		retry_of = excluded.retry_of,
+		queue = excluded.queue,
 		command = excluded.command,
+		fallback_command = excluded.fallback_command,
 		state = excluded.state,
 		attempts = excluded.attempts,
 		max_retries = excluded.max_retries,
+		timeout_seconds = excluded.timeout_seconds,
+		backoff_base = excluded.backoff_base,
+		priority = excluded.priority,
+		env_file = excluded.env_file,
+		retry_on_timeout_only = excluded.retry_on_timeout_only,
+		sandbox = excluded.sandbox,
+		success_pattern = excluded.success_pattern,
+		failure_pattern = excluded.failure_pattern,
 		updated_at = excluded.updated_at,
 		next_retry_at = excluded.next_retry_at,
+		scheduled_at = excluded.scheduled_at,
+		held_until = excluded.held_until,
+		completed_at = excluded.completed_at,
 		worker_id = excluded.worker_id,
 		error = excluded.error,
-		output = excluded.output
-	`
+		error_type = excluded.error_type,
+		output = excluded.output,
+		history = excluded.history,
+		next_job = excluded.next_job,
+		parent_id = excluded.parent_id,
+		parent_output = excluded.parent_output,
+		retry_schedule = excluded.retry_schedule,
+		cpu_time_ms = excluded.cpu_time_ms,
+		max_rss_kb = excluded.max_rss_kb,
+		exit_code = excluded.exit_code,
+		env = excluded.env,
+		work_dir = excluded.work_dir,
+		tags = excluded.tags,
+		duration_ms = excluded.duration_ms,
+		idempotency_key = excluded.idempotency_key,
+		progress = excluded.progress`
+
+// writeJob inserts a job through db, resolving an ID conflict with
+// onConflict
+func writeJob(db execer, j *job.Job, onConflict string) (sql.Result, error) {
+	query := `
+	INSERT INTO jobs (id, seq, retry_of, queue, command, fallback_command, state, attempts, max_retries, timeout_seconds, backoff_base, priority, env_file, retry_on_timeout_only, sandbox, success_pattern, failure_pattern, created_at, updated_at, next_retry_at, scheduled_at, held_until, completed_at, worker_id, error, error_type, output, history, next_job, parent_id, parent_output, retry_schedule, cpu_time_ms, max_rss_kb, exit_code, env, work_dir, tags, duration_ms, idempotency_key, progress)
+	VALUES (?, (SELECT COALESCE(MAX(seq), 0) + 1 FROM jobs), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	` + onConflict
 
-	var nextRetryAt interface{}
-	if j.NextRetryAt != nil {
-		nextRetryAt = j.NextRetryAt.Format(time.RFC3339)
+	history, err := marshalHistory(j.History)
+	if err != nil {
+		return nil, err
+	}
+	nextJob, err := marshalNextJob(j.NextJob)
+	if err != nil {
+		return nil, err
+	}
+	retrySchedule, err := marshalRetrySchedule(j.RetrySchedule)
+	if err != nil {
+		return nil, err
+	}
+	env, err := marshalEnv(j.Env)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := marshalTags(j.Tags)
+	if err != nil {
+		return nil, err
 	}
 
-	_, err := s.db.Exec(query,
+	result, err := db.Exec(query,
 		j.ID,
+		j.RetryOf,
+		j.Queue,
 		j.Command,
+		j.FallbackCommand,
 		j.State,
 		j.Attempts,
 		j.MaxRetries,
-		j.CreatedAt.Format(time.RFC3339),
-		j.UpdatedAt.Format(time.RFC3339),
-		nextRetryAt,
+		j.TimeoutSeconds,
+		j.BackoffBase,
+		j.Priority,
+		j.EnvFile,
+		j.RetryOnTimeoutOnly,
+		j.Sandbox,
+		j.SuccessPattern,
+		j.FailurePattern,
+		dbTime(j.CreatedAt),
+		dbTime(j.UpdatedAt),
+		formatNullTime(j.NextRetryAt),
+		formatNullTime(j.ScheduledAt),
+		formatNullTime(j.HeldUntil),
+		formatNullTime(j.CompletedAt),
 		j.WorkerID,
 		j.Error,
+		j.ErrorType,
 		j.Output,
+		history,
+		nextJob,
+		j.ParentID,
+		j.ParentOutput,
+		retrySchedule,
+		j.CPUTimeMS,
+		j.MaxRSSKB,
+		j.ExitCode,
+		env,
+		j.WorkDir,
+		tags,
+		j.DurationMS,
+		nullIfEmpty(j.IdempotencyKey),
+		j.Progress,
 	)
+	if err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed: jobs.idempotency_key") {
+		return nil, fmt.Errorf("idempotency key %q: %w", j.IdempotencyKey, ErrIdempotencyKeyInUse)
+	}
+	return result, err
+}
+
+// GetJob retrieves a job by ID
+func (s *SQLiteStorage) GetJob(id string) (*job.Job, error) {
+	query := `
+	SELECT ` + jobColumns + `
+	FROM jobs WHERE id = ?
+	`
 
+	return s.scanJob(s.db.QueryRow(query, id))
+}
+
+// FindByIdempotencyKey returns the newest job holding key: one that has
+// not finished, or one that completed less than reuseAfter ago. It
+// returns nil if the key is free.
+func (s *SQLiteStorage) FindByIdempotencyKey(key string, reuseAfter time.Duration) (*job.Job, error) {
+	query := `
+	SELECT ` + jobColumns + `
+	FROM jobs
+	WHERE idempotency_key = ? AND (` + unfinishedWhere + ` OR (state = ? AND completed_at > ?))
+	ORDER BY seq DESC
+	LIMIT 1
+	`
+	cutoff := dbTime(time.Now().Add(-reuseAfter))
+	j, err := s.scanJob(s.db.QueryRow(query, key, job.StateCompleted, cutoff))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
 	if err != nil {
-		return fmt.Errorf("failed to save job: %w", err)
+		return nil, fmt.Errorf("failed to find job by idempotency key: %w", err)
+	}
+	return j, nil
+}
+
+// GetNextPendingJob gets the next available job and locks it
+func (s *SQLiteStorage) GetNextPendingJob(workerID string, filter ClaimFilter) (*job.Job, error) {
+	return firstClaim(s.GetNextPendingJobs(workerID, filter, 1))
+}
+
+// GetNextPendingJobs locks up to n available jobs in one transaction, so
+// a worker with free slots takes the database lock once rather than once
+// per job. If any statement fails the transaction is rolled back and no
+// job stays claimed; if the database was locked, the whole transaction is
+// retried.
+func (s *SQLiteStorage) GetNextPendingJobs(workerID string, filter ClaimFilter, n int) ([]*job.Job, error) {
+	if err := s.releaseHolds(); err != nil {
+		return nil, err
+	}
+
+	var claimed []*job.Job
+	err := s.retryBusy(func() error {
+		var err error
+		claimed, err = s.claimJobs(workerID, filter, n)
+		return err
+	})
+	return claimed, err
+}
+
+// releaseHolds returns held jobs whose hold has expired to pending, at
+// most once per releaseInterval. It commits on its own rather than in the
+// claim transaction, which is rolled back when nothing is claimed, and so
+// that polls finding nothing do not take the write lock.
+func (s *SQLiteStorage) releaseHolds() error {
+	now := time.Now()
+	last := s.releasedAt.Load()
+	if now.UnixNano()-last < int64(releaseInterval) || !s.releasedAt.CompareAndSwap(last, now.UnixNano()) {
+		return nil
+	}
+
+	releaseQuery := `
+	UPDATE jobs
+	SET state = ?, held_until = NULL, updated_at = ?
+	WHERE state = ? AND held_until <= ?
+	`
+	err := s.retryBusy(func() error {
+		_, err := s.db.Exec(releaseQuery, job.StatePending, dbTime(now), job.StateHeld, dbTime(now))
+		return err
+	})
+	if err != nil {
+		// Try again on the next claim
+		s.releasedAt.Store(last)
+		return fmt.Errorf("failed to release held jobs: %w", err)
+	}
+	return nil
+}
+
+// claimJobs runs one attempt of GetNextPendingJobs
+func (s *SQLiteStorage) claimJobs(workerID string, filter ClaimFilter, n int) ([]*job.Job, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := dbTime(time.Now())
+
+	var claimed []*job.Job
+	for len(claimed) < n {
+		j, err := s.claimOne(tx, now, workerID, filter)
+		if err != nil {
+			return nil, err
+		}
+		if j == nil {
+			break
+		}
+		claimed = append(claimed, j)
+	}
+
+	if len(claimed) == 0 {
+		return nil, nil // No jobs available
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return claimed, nil
+}
+
+// claimOne locks the next available job within tx. It returns nil if
+// there is none or the job was taken by another worker, which ends the
+// batch: later candidates would have been claimed after it.
+func (s *SQLiteStorage) claimOne(tx *sql.Tx, now, workerID string, filter ClaimFilter) (*job.Job, error) {
+	// Find next pending job or failed job ready for retry
+	var queue string
+	if s.scheduler.enabled() {
+		filterSQL, filterArgs := filterWhere(filter)
+		var err error
+		queue, err = s.pickQueue(tx, now, filterSQL, filterArgs)
+		if err != nil {
+			return nil, err
+		}
+		if queue == "" {
+			return nil, nil // No jobs available
+		}
+	}
+	query, args := s.ClaimQuery(now, filter, queue)
+
+	j, err := s.scanJob(tx.QueryRow(query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No jobs available
+		}
+		return nil, fmt.Errorf("failed to query next job: %w", err)
+	}
+
+	// Lock the job by updating its state
+	updateQuery := `
+	UPDATE jobs 
+	SET state = ?, worker_id = ?, updated_at = ?
+	WHERE id = ? AND (state = ? OR state = ?)
+	`
+
+	result, err := tx.Exec(updateQuery,
+		job.StateProcessing,
+		workerID,
+		dbTime(time.Now()),
+		j.ID,
+		job.StatePending,
+		job.StateFailed,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock job: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		// Job was already taken by another worker
+		return nil, nil
+	}
+
+	j.State = job.StateProcessing
+	j.WorkerID = workerID
+	return j, nil
+}
+
+// PreviewClaimOrder returns up to limit jobs in the order GetNextPendingJob
+// would claim them, without claiming anything. Held jobs whose hold has
+// expired are included since the next claim releases them first.
+func (s *SQLiteStorage) PreviewClaimOrder(limit int) ([]ClaimCandidate, error) {
+	query := `
+	SELECT ` + jobColumns + `, ` + s.effectivePriority() + `
+	FROM jobs
+	WHERE (` + claimWhere + `)
+	OR (state = ? AND held_until <= ?
+		AND NOT EXISTS (SELECT 1 FROM paused_queues p WHERE p.queue = jobs.queue OR p.queue = ?))
+	ORDER BY ` + s.claimOrder() + `
+	LIMIT ?
+	`
+
+	now := dbTime(time.Now())
+	args := append(claimArgs(now), job.StateHeld, now, AllQueues, limit)
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to preview claim order: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []ClaimCandidate
+	for rows.Next() {
+		var c ClaimCandidate
+		j, err := scanJobColumns(extraScanner{rows, []interface{}{&c.EffectivePriority}})
+		if err != nil {
+			return nil, err
+		}
+		c.Job = j
+		candidates = append(candidates, c)
+	}
+
+	return candidates, rows.Err()
+}
+
+// listSortColumns maps ListOptions.SortBy values to columns
+var listSortColumns = map[string]string{
+	"":           "created_at",
+	SortCreated:  "created_at",
+	SortUpdated:  "updated_at",
+	SortPriority: "priority",
+	SortAttempts: "attempts",
+}
+
+// ListJobs returns jobs filtered by state, ordered and paged by opts
+func (s *SQLiteStorage) ListJobs(state job.State, opts ListOptions) ([]*job.Job, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	var where []string
+	var args []interface{}
+	if state != "" {
+		where = append(where, `state = ?`)
+		args = append(args, state)
+	}
+	if opts.Tag != "" {
+		// Tags are stored as a JSON array; json_each matches whole tags
+		where = append(where, `EXISTS (SELECT 1 FROM json_each(jobs.tags) WHERE value = ?)`)
+		args = append(args, opts.Tag)
+	}
+	if !opts.UpdatedSince.IsZero() {
+		where = append(where, `updated_at >= ?`)
+		args = append(args, dbTime(opts.UpdatedSince))
+	}
+	if !opts.UpdatedUntil.IsZero() {
+		where = append(where, `updated_at <= ?`)
+		args = append(args, dbTime(opts.UpdatedUntil))
+	}
+	if opts.WorkerID != "" {
+		where = append(where, `worker_id = ?`)
+		args = append(args, opts.WorkerID)
+	}
+	if opts.Queue != "" {
+		where = append(where, `queue = ?`)
+		args = append(args, opts.Queue)
+	}
+
+	query := `SELECT ` + jobColumns + ` FROM jobs`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, ` AND `)
+	}
+
+	// seq breaks ties so pages never overlap
+	dir := "DESC"
+	if opts.Ascending {
+		dir = "ASC"
+	}
+	query += fmt.Sprintf(` ORDER BY %s %s, seq %s`, listSortColumns[opts.SortBy], dir, dir)
+
+	if opts.Limit > 0 || opts.Offset > 0 {
+		limit := opts.Limit
+		if limit == 0 {
+			limit = -1 // SQLite needs a LIMIT for OFFSET; -1 means none
+		}
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, limit, opts.Offset)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*job.Job
+	for rows.Next() {
+		j, err := s.scanJobFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+
+	return jobs, rows.Err()
+}
+
+// GetJobStats returns job counts by state
+func (s *SQLiteStorage) GetJobStats() (map[job.State]int, error) {
+	query := `SELECT state, COUNT(*) FROM jobs GROUP BY state`
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make(map[job.State]int)
+	for rows.Next() {
+		var state job.State
+		var count int
+		if err := rows.Scan(&state, &count); err != nil {
+			return nil, err
+		}
+		stats[state] = count
+	}
+
+	return stats, rows.Err()
+}
+
+// GetQueueStats returns job counts by state for each queue
+func (s *SQLiteStorage) GetQueueStats() (map[string]map[job.State]int, error) {
+	rows, err := s.db.Query(`SELECT queue, state, COUNT(*) FROM jobs GROUP BY queue, state`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make(map[string]map[job.State]int)
+	for rows.Next() {
+		var queue string
+		var state job.State
+		var count int
+		if err := rows.Scan(&queue, &state, &count); err != nil {
+			return nil, err
+		}
+		if stats[queue] == nil {
+			stats[queue] = make(map[job.State]int)
+		}
+		stats[queue][state] = count
+	}
+
+	return stats, rows.Err()
+}
+
+// CountFailedAttempts returns the sum of every stored job's attempts
+func (s *SQLiteStorage) CountFailedAttempts() (int, error) {
+	var n int
+	if err := s.db.QueryRow(`SELECT COALESCE(SUM(attempts), 0) FROM jobs`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count failed attempts: %w", err)
+	}
+	return n, nil
+}
+
+// DeleteJob removes a job
+func (s *SQLiteStorage) DeleteJob(id string) error {
+	query := `DELETE FROM jobs WHERE id = ?`
+	_, err := s.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete job: %w", err)
+	}
+	return nil
+}
+
+// DeleteJobIfState removes a job only if it is in state
+func (s *SQLiteStorage) DeleteJobIfState(id string, state job.State) error {
+	result, err := s.db.Exec(`DELETE FROM jobs WHERE id = ? AND state = ?`, id, state)
+	if err != nil {
+		return fmt.Errorf("failed to delete job: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		if err := checkState(s.db, id, state); err != nil {
+			return err
+		}
+		return fmt.Errorf("job %s changed while being deleted, try again", id)
+	}
+	return nil
+}
+
+// CancelJob cancels a waiting job, or asks the worker processing it to
+// stop. It reports whether the job was cancelled immediately.
+func (s *SQLiteStorage) CancelJob(id string) (bool, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := dbTime(time.Now())
+
+	// Waiting jobs are cancelled in place, so a worker cannot claim one
+	// between the check and the update
+	cancelQuery := `
+	UPDATE jobs
+	SET state = ?, next_retry_at = NULL, scheduled_at = NULL, held_until = NULL, worker_id = '', updated_at = ?
+	WHERE id = ? AND state IN (?, ?, ?)
+	`
+	result, err := tx.Exec(cancelQuery, job.StateCancelled, now, id, job.StatePending, job.StateHeld, job.StateFailed)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel job: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	} else if rows == 1 {
+		return true, tx.Commit()
+	}
+
+	// Running jobs are stopped by their worker, which polls for requests
+	requestQuery := `
+	INSERT OR REPLACE INTO cancel_requests (job_id, requested_at)
+	SELECT id, ? FROM jobs WHERE id = ? AND state = ?
+	`
+	result, err = tx.Exec(requestQuery, now, id, job.StateProcessing)
+	if err != nil {
+		return false, fmt.Errorf("failed to request cancellation: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	} else if rows == 1 {
+		return false, tx.Commit()
+	}
+
+	var state job.State
+	if err := tx.QueryRow(`SELECT state FROM jobs WHERE id = ?`, id).Scan(&state); err != nil {
+		return false, fmt.Errorf("failed to get job: %w", err)
+	}
+	return false, fmt.Errorf("job %s cannot be cancelled (state: %s)", id, state)
+}
+
+// Heartbeat refreshes the updated_at of a processing job
+func (s *SQLiteStorage) Heartbeat(id string) error {
+	query := `UPDATE jobs SET updated_at = ? WHERE id = ? AND state = ?`
+	if _, err := s.db.Exec(query, dbTime(time.Now()), id, job.StateProcessing); err != nil {
+		return fmt.Errorf("failed to record heartbeat: %w", err)
+	}
+	return nil
+}
+
+// SetProgress records the percent done of a processing job
+func (s *SQLiteStorage) SetProgress(id string, progress int) error {
+	query := `UPDATE jobs SET progress = ? WHERE id = ? AND state = ?`
+	if _, err := s.db.Exec(query, progress, id, job.StateProcessing); err != nil {
+		return fmt.Errorf("failed to record progress: %w", err)
+	}
+	return nil
+}
+
+// RecoverStaleJobs requeues processing jobs without a heartbeat since
+// threshold ago
+func (s *SQLiteStorage) RecoverStaleJobs(threshold time.Duration) ([]*job.Job, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	cutoff := dbTime(time.Now().Add(-threshold))
+	rows, err := tx.Query(`SELECT `+jobColumns+` FROM jobs WHERE state = ? AND updated_at <= ?`, job.StateProcessing, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stale jobs: %w", err)
+	}
+	var stale []*job.Job
+	for rows.Next() {
+		j, err := s.scanJobFromRows(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		stale = append(stale, j)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Re-check the heartbeat so a job refreshed since the select is kept
+	updateQuery := `
+	UPDATE jobs
+	SET state = ?, worker_id = '', error = ?, updated_at = ?
+	WHERE id = ? AND state = ? AND updated_at <= ?
+	`
+	now := dbTime(time.Now())
+	var recovered []*job.Job
+	for _, j := range stale {
+		result, err := tx.Exec(updateQuery, job.StatePending, staleReason(j, threshold), now, j.ID, job.StateProcessing, cutoff)
+		if err != nil {
+			return nil, fmt.Errorf("failed to requeue stale job: %w", err)
+		}
+		if n, err := result.RowsAffected(); err == nil && n == 1 {
+			recovered = append(recovered, j)
+		}
+		if _, err := tx.Exec(`DELETE FROM cancel_requests WHERE job_id = ?`, j.ID); err != nil {
+			return nil, fmt.Errorf("failed to clear cancel request: %w", err)
+		}
 	}
 
-	return nil
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return recovered, nil
 }
 
-// GetJob retrieves a job by ID
-func (s *SQLiteStorage) GetJob(id string) (*job.Job, error) {
-	query := `
-	SELECT id, command, state, attempts, max_retries, created_at, updated_at, next_retry_at, worker_id, error, output
-	FROM jobs WHERE id = ?
-	`
+// IsCancelRequested reports whether the processing job has been cancelled
+func (s *SQLiteStorage) IsCancelRequested(id string) (bool, error) {
+	var requested bool
+	query := `SELECT EXISTS(SELECT 1 FROM cancel_requests WHERE job_id = ?)`
+	if err := s.db.QueryRow(query, id).Scan(&requested); err != nil {
+		return false, fmt.Errorf("failed to check cancel request: %w", err)
+	}
+	return requested, nil
+}
 
-	return s.scanJob(s.db.QueryRow(query, id))
+// DeleteCompletedBefore removes completed jobs that finished before cutoff
+func (s *SQLiteStorage) DeleteCompletedBefore(cutoff time.Time) (int, error) {
+	query := `DELETE FROM jobs WHERE state = ? AND completed_at < ?`
+	result, err := s.db.Exec(query, job.StateCompleted, dbTime(cutoff))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete completed jobs: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete completed jobs: %w", err)
+	}
+	return int(n), nil
 }
 
-// GetNextPendingJob gets the next available job and locks it
-func (s *SQLiteStorage) GetNextPendingJob(workerID string) (*job.Job, error) {
-	tx, err := s.db.Begin()
+// DeleteJobsByState removes jobs in state last updated before olderThan
+func (s *SQLiteStorage) DeleteJobsByState(state job.State, olderThan time.Time) (int, error) {
+	query := `DELETE FROM jobs WHERE state = ? AND updated_at < ?`
+	result, err := s.db.Exec(query, state, dbTime(olderThan))
 	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+		return 0, fmt.Errorf("failed to delete %s jobs: %w", state, err)
 	}
-	defer tx.Rollback()
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete %s jobs: %w", state, err)
+	}
+	return int(n), nil
+}
 
-	// Find next pending job or failed job ready for retry
+// GetRetryableJobs returns failed jobs ready to retry
+func (s *SQLiteStorage) GetRetryableJobs() ([]*job.Job, error) {
 	query := `
-	SELECT id, command, state, attempts, max_retries, created_at, updated_at, next_retry_at, worker_id, error, output
+	SELECT ` + jobColumns + `
 	FROM jobs 
-	WHERE (state = ? OR (state = ? AND next_retry_at <= ?))
-	ORDER BY created_at ASC
-	LIMIT 1
+	WHERE state = ? AND next_retry_at <= ?
+	ORDER BY next_retry_at ASC
 	`
 
-	now := time.Now().Format(time.RFC3339)
-	j, err := s.scanJob(tx.QueryRow(query, job.StatePending, job.StateFailed, now))
+	now := dbTime(time.Now())
+	rows, err := s.db.Query(query, job.StateFailed, now)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil // No jobs available
+		return nil, fmt.Errorf("failed to get retryable jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*job.Job
+	for rows.Next() {
+		j, err := s.scanJobFromRows(rows)
+		if err != nil {
+			return nil, err
 		}
-		return nil, fmt.Errorf("failed to query next job: %w", err)
+		jobs = append(jobs, j)
 	}
 
-	// Lock the job by updating its state
-	updateQuery := `
-	UPDATE jobs 
-	SET state = ?, worker_id = ?, updated_at = ?
-	WHERE id = ? AND (state = ? OR state = ?)
-	`
+	return jobs, rows.Err()
+}
 
-	result, err := tx.Exec(updateQuery,
-		job.StateProcessing,
-		workerID,
-		time.Now().Format(time.RFC3339),
-		j.ID,
-		job.StatePending,
-		job.StateFailed,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to lock job: %w", err)
+// GetThroughput counts completed jobs per bucket, starting from since.
+// Buckets are aligned to since and every bucket up to now is returned,
+// including empty ones.
+func (s *SQLiteStorage) GetThroughput(since time.Time, bucket time.Duration) ([]ThroughputBucket, error) {
+	size := int64(bucket / time.Second)
+	if size <= 0 {
+		return nil, fmt.Errorf("bucket size must be at least one second")
 	}
 
-	rows, err := result.RowsAffected()
+	query := `
+	SELECT (CAST(strftime('%s', completed_at) AS INTEGER) - ?) / ? AS bucket, COUNT(*)
+	FROM jobs
+	WHERE state = ? AND CAST(strftime('%s', completed_at) AS INTEGER) >= ?
+	GROUP BY bucket
+	`
+
+	start := since.Unix()
+	rows, err := s.db.Query(query, start, size, job.StateCompleted, start)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+		return nil, fmt.Errorf("failed to get throughput: %w", err)
 	}
+	defer rows.Close()
 
-	if rows == 0 {
-		// Job was already taken by another worker
-		return nil, nil
+	counts := make(map[int64]int)
+	for rows.Next() {
+		var index int64
+		var count int
+		if err := rows.Scan(&index, &count); err != nil {
+			return nil, err
+		}
+		counts[index] = count
 	}
-
-	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	j.State = job.StateProcessing
-	j.WorkerID = workerID
-	return j, nil
+	n := (time.Now().Unix()-start)/size + 1
+	buckets := make([]ThroughputBucket, n)
+	for i := range buckets {
+		buckets[i] = ThroughputBucket{
+			Start: time.Unix(start+int64(i)*size, 0),
+			Count: counts[int64(i)],
+		}
+	}
+	return buckets, nil
 }
 
-// ListJobs returns jobs filtered by state
-func (s *SQLiteStorage) ListJobs(state job.State) ([]*job.Job, error) {
-	var query string
-	var rows *sql.Rows
-	var err error
+// RecordStats stores the current job count of every state as a sample
+// taken at the given time, and deletes the samples older than retention
+// (0 keeps them all)
+func (s *SQLiteStorage) RecordStats(at time.Time, retention time.Duration) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to record stats: %w", err)
+	}
+	defer tx.Rollback()
 
-	if state == "" {
-		query = `SELECT id, command, state, attempts, max_retries, created_at, updated_at, next_retry_at, worker_id, error, output FROM jobs ORDER BY created_at DESC`
-		rows, err = s.db.Query(query)
-	} else {
-		query = `SELECT id, command, state, attempts, max_retries, created_at, updated_at, next_retry_at, worker_id, error, output FROM jobs WHERE state = ? ORDER BY created_at DESC`
-		rows, err = s.db.Query(query, state)
+	query := `
+	INSERT INTO stats_history (timestamp, state, count)
+	SELECT ?, state, COUNT(*) FROM jobs GROUP BY state
+	`
+	if _, err := tx.Exec(query, dbTime(at)); err != nil {
+		return fmt.Errorf("failed to record stats: %w", err)
+	}
+	if retention > 0 {
+		if _, err := tx.Exec(`DELETE FROM stats_history WHERE timestamp < ?`, dbTime(at.Add(-retention))); err != nil {
+			return fmt.Errorf("failed to prune stats history: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to record stats: %w", err)
 	}
+	return nil
+}
 
+// GetStatsHistory returns the samples recorded since the given time,
+// oldest first
+func (s *SQLiteStorage) GetStatsHistory(since time.Time) ([]StatsSample, error) {
+	query := `
+	SELECT timestamp, state, count
+	FROM stats_history
+	WHERE timestamp >= ?
+	ORDER BY timestamp ASC
+	`
+	rows, err := s.db.Query(query, dbTime(since))
 	if err != nil {
-		return nil, fmt.Errorf("failed to list jobs: %w", err)
+		return nil, fmt.Errorf("failed to get stats history: %w", err)
 	}
 	defer rows.Close()
 
-	var jobs []*job.Job
+	var samples []StatsSample
 	for rows.Next() {
-		j, err := s.scanJobFromRows(rows)
-		if err != nil {
+		var ts, state string
+		var count int
+		if err := rows.Scan(&ts, &state, &count); err != nil {
 			return nil, err
 		}
-		jobs = append(jobs, j)
+		at, err := time.Parse(time.RFC3339, ts)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stats timestamp %q: %w", ts, err)
+		}
+		if len(samples) == 0 || !samples[len(samples)-1].Time.Equal(at) {
+			samples = append(samples, StatsSample{Time: at, Counts: make(map[job.State]int)})
+		}
+		samples[len(samples)-1].Counts[job.State(state)] = count
 	}
+	return samples, rows.Err()
+}
 
-	return jobs, rows.Err()
+// PauseQueue marks a queue as paused
+func (s *SQLiteStorage) PauseQueue(queue string) error {
+	query := `INSERT OR IGNORE INTO paused_queues (queue, paused_at) VALUES (?, ?)`
+	if _, err := s.db.Exec(query, queue, dbTime(time.Now())); err != nil {
+		return fmt.Errorf("failed to pause queue: %w", err)
+	}
+	return nil
 }
 
-// GetJobStats returns job counts by state
-func (s *SQLiteStorage) GetJobStats() (map[job.State]int, error) {
-	query := `SELECT state, COUNT(*) FROM jobs GROUP BY state`
-	rows, err := s.db.Query(query)
+// ResumeQueue clears a queue's paused flag
+func (s *SQLiteStorage) ResumeQueue(queue string) error {
+	if _, err := s.db.Exec(`DELETE FROM paused_queues WHERE queue = ?`, queue); err != nil {
+		return fmt.Errorf("failed to resume queue: %w", err)
+	}
+	return nil
+}
+
+// ListPausedQueues returns paused queue names in the order they were paused
+func (s *SQLiteStorage) ListPausedQueues() ([]string, error) {
+	rows, err := s.db.Query(`SELECT queue FROM paused_queues ORDER BY paused_at, queue`)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get job stats: %w", err)
+		return nil, fmt.Errorf("failed to list paused queues: %w", err)
 	}
 	defer rows.Close()
 
-	stats := make(map[job.State]int)
+	var queues []string
 	for rows.Next() {
-		var state job.State
-		var count int
-		if err := rows.Scan(&state, &count); err != nil {
+		var queue string
+		if err := rows.Scan(&queue); err != nil {
 			return nil, err
 		}
-		stats[state] = count
+		queues = append(queues, queue)
 	}
 
-	return stats, rows.Err()
+	return queues, rows.Err()
 }
 
-// DeleteJob removes a job
-func (s *SQLiteStorage) DeleteJob(id string) error {
-	query := `DELETE FROM jobs WHERE id = ?`
-	_, err := s.db.Exec(query, id)
+// AddSchedule stores a new schedule, failing with ErrScheduleExists if
+// the ID is taken
+func (s *SQLiteStorage) AddSchedule(sc *Schedule) error {
+	query := `
+	INSERT INTO schedules (id, cron, command, queue, created_at, last_fired_at)
+	VALUES (?, ?, ?, ?, ?, ?)
+	ON CONFLICT(id) DO NOTHING
+	`
+	result, err := s.db.Exec(query, sc.ID, sc.Cron, sc.Command, sc.Queue,
+		dbTime(sc.CreatedAt), formatNullTime(sc.LastFiredAt))
 	if err != nil {
-		return fmt.Errorf("failed to delete job: %w", err)
+		return fmt.Errorf("failed to add schedule: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to add schedule: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("schedule %s: %w", sc.ID, ErrScheduleExists)
 	}
 	return nil
 }
 
-// GetRetryableJobs returns failed jobs ready to retry
-func (s *SQLiteStorage) GetRetryableJobs() ([]*job.Job, error) {
-	query := `
-	SELECT id, command, state, attempts, max_retries, created_at, updated_at, next_retry_at, worker_id, error, output
-	FROM jobs 
-	WHERE state = ? AND next_retry_at <= ?
-	ORDER BY next_retry_at ASC
-	`
-
-	now := time.Now().Format(time.RFC3339)
-	rows, err := s.db.Query(query, job.StateFailed, now)
+// ListSchedules returns every schedule, oldest first
+func (s *SQLiteStorage) ListSchedules() ([]*Schedule, error) {
+	rows, err := s.db.Query(`SELECT id, cron, command, queue, created_at, last_fired_at FROM schedules ORDER BY created_at, id`)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get retryable jobs: %w", err)
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
 	}
 	defer rows.Close()
 
-	var jobs []*job.Job
+	var schedules []*Schedule
 	for rows.Next() {
-		j, err := s.scanJobFromRows(rows)
-		if err != nil {
+		var sc Schedule
+		var createdAt string
+		var lastFiredAt sql.NullString
+		if err := rows.Scan(&sc.ID, &sc.Cron, &sc.Command, &sc.Queue, &createdAt, &lastFiredAt); err != nil {
 			return nil, err
 		}
-		jobs = append(jobs, j)
+		if sc.CreatedAt, err = time.Parse(time.RFC3339, createdAt); err != nil {
+			return nil, fmt.Errorf("invalid schedule timestamp %q: %w", createdAt, err)
+		}
+		sc.LastFiredAt = parseNullTime(lastFiredAt)
+		schedules = append(schedules, &sc)
 	}
+	return schedules, rows.Err()
+}
 
-	return jobs, rows.Err()
+// DeleteSchedule removes a schedule, returning sql.ErrNoRows if there is
+// none with the ID
+func (s *SQLiteStorage) DeleteSchedule(id string) error {
+	result, err := s.db.Exec(`DELETE FROM schedules WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete schedule: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete schedule: %w", err)
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// MarkScheduleFired records the latest fire time a schedule enqueued its
+// job for. The time only moves forward, so a pool that fell behind cannot
+// make another fire again.
+func (s *SQLiteStorage) MarkScheduleFired(id string, at time.Time) error {
+	query := `
+	UPDATE schedules SET last_fired_at = ?
+	WHERE id = ? AND (last_fired_at IS NULL OR last_fired_at < ?)
+	`
+	ts := dbTime(at)
+	if _, err := s.db.Exec(query, ts, id, ts); err != nil {
+		return fmt.Errorf("failed to update schedule: %w", err)
+	}
+	return nil
 }
 
 // GetDLQJobs returns all dead jobs
 func (s *SQLiteStorage) GetDLQJobs() ([]*job.Job, error) {
-	return s.ListJobs(job.StateDead)
+	return s.ListJobs(job.StateDead, ListOptions{})
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// extraScanner scans additional trailing columns after the job columns
+type extraScanner struct {
+	rowScanner
+	extra []interface{}
+}
+
+func (e extraScanner) Scan(dest ...interface{}) error {
+	return e.rowScanner.Scan(append(dest, e.extra...)...)
 }
 
 // Helper function to scan a single job from QueryRow
 func (s *SQLiteStorage) scanJob(row *sql.Row) (*job.Job, error) {
+	return scanJobColumns(row)
+}
+
+// Helper function to scan jobs from Rows
+func (s *SQLiteStorage) scanJobFromRows(rows *sql.Rows) (*job.Job, error) {
+	return scanJobColumns(rows)
+}
+
+// scanJobColumns scans the columns listed in jobColumns into a job
+func scanJobColumns(row rowScanner) (*job.Job, error) {
 	j := &job.Job{}
 	var createdAt, updatedAt string
-	var nextRetryAt sql.NullString
-	var workerID, errMsg, output sql.NullString
+	var seq sql.NullInt64
+	var nextRetryAt, scheduledAt, heldUntil, completedAt sql.NullString
+	var retryOf, nextJob, parentID, parentOutput, retrySchedule sql.NullString
+	var fallbackCommand, successPattern, failurePattern, history sql.NullString
+	var envFile, workerID, errMsg, errType, output sql.NullString
+	var env, workDir, tags, idempotencyKey sql.NullString
 
 	err := row.Scan(
 		&j.ID,
+		&seq,
+		&retryOf,
+		&j.Queue,
 		&j.Command,
+		&fallbackCommand,
 		&j.State,
 		&j.Attempts,
 		&j.MaxRetries,
+		&j.TimeoutSeconds,
+		&j.BackoffBase,
+		&j.Priority,
+		&envFile,
+		&j.RetryOnTimeoutOnly,
+		&j.Sandbox,
+		&successPattern,
+		&failurePattern,
 		&createdAt,
 		&updatedAt,
 		&nextRetryAt,
+		&scheduledAt,
+		&heldUntil,
+		&completedAt,
 		&workerID,
 		&errMsg,
+		&errType,
 		&output,
+		&history,
+		&nextJob,
+		&parentID,
+		&parentOutput,
+		&retrySchedule,
+		&j.CPUTimeMS,
+		&j.MaxRSSKB,
+		&j.ExitCode,
+		&env,
+		&workDir,
+		&tags,
+		&j.DurationMS,
+		&idempotencyKey,
+		&j.Progress,
 	)
 
 	if err != nil {
 		return nil, err
 	}
 
+	j.Seq = seq.Int64
 	j.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
 	j.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+	j.NextRetryAt = parseNullTime(nextRetryAt)
+	j.ScheduledAt = parseNullTime(scheduledAt)
+	j.HeldUntil = parseNullTime(heldUntil)
+	j.CompletedAt = parseNullTime(completedAt)
 
-	if nextRetryAt.Valid {
-		t, _ := time.Parse(time.RFC3339, nextRetryAt.String)
-		j.NextRetryAt = &t
+	if retryOf.Valid {
+		j.RetryOf = retryOf.String
+	}
+	if envFile.Valid {
+		j.EnvFile = envFile.String
+	}
+	if workDir.Valid {
+		j.WorkDir = workDir.String
+	}
+	if idempotencyKey.Valid {
+		j.IdempotencyKey = idempotencyKey.String
+	}
+	if fallbackCommand.Valid {
+		j.FallbackCommand = fallbackCommand.String
+	}
+	if successPattern.Valid {
+		j.SuccessPattern = successPattern.String
+	}
+	if failurePattern.Valid {
+		j.FailurePattern = failurePattern.String
 	}
 	if workerID.Valid {
 		j.WorkerID = workerID.String
@@ -315,54 +1559,135 @@ func (s *SQLiteStorage) scanJob(row *sql.Row) (*job.Job, error) {
 	if errMsg.Valid {
 		j.Error = errMsg.String
 	}
+	if errType.Valid {
+		j.ErrorType = job.ErrorType(errType.String)
+	}
 	if output.Valid {
 		j.Output = output.String
 	}
+	if parentID.Valid {
+		j.ParentID = parentID.String
+	}
+	if parentOutput.Valid {
+		j.ParentOutput = parentOutput.String
+	}
+	if nextJob.Valid && nextJob.String != "" {
+		if err := json.Unmarshal([]byte(nextJob.String), &j.NextJob); err != nil {
+			return nil, fmt.Errorf("failed to decode next_job for job %s: %w", j.ID, err)
+		}
+	}
+	if retrySchedule.Valid && retrySchedule.String != "" {
+		if err := json.Unmarshal([]byte(retrySchedule.String), &j.RetrySchedule); err != nil {
+			return nil, fmt.Errorf("failed to decode retry_schedule for job %s: %w", j.ID, err)
+		}
+	}
+	if history.Valid && history.String != "" {
+		if err := json.Unmarshal([]byte(history.String), &j.History); err != nil {
+			return nil, fmt.Errorf("failed to decode history for job %s: %w", j.ID, err)
+		}
+	}
+	if tags.Valid && tags.String != "" {
+		if err := json.Unmarshal([]byte(tags.String), &j.Tags); err != nil {
+			return nil, fmt.Errorf("failed to decode tags for job %s: %w", j.ID, err)
+		}
+	}
+	if env.Valid && env.String != "" {
+		if err := json.Unmarshal([]byte(env.String), &j.Env); err != nil {
+			return nil, fmt.Errorf("failed to decode env for job %s: %w", j.ID, err)
+		}
+	}
 
 	return j, nil
 }
 
-// Helper function to scan jobs from Rows
-func (s *SQLiteStorage) scanJobFromRows(rows *sql.Rows) (*job.Job, error) {
-	j := &job.Job{}
-	var createdAt, updatedAt string
-	var nextRetryAt sql.NullString
-	var workerID, errMsg, output sql.NullString
+// marshalHistory encodes a job's attempt history for storage
+func marshalHistory(history []job.AttemptRecord) (interface{}, error) {
+	if len(history) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(history)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job history: %w", err)
+	}
+	return string(data), nil
+}
 
-	err := rows.Scan(
-		&j.ID,
-		&j.Command,
-		&j.State,
-		&j.Attempts,
-		&j.MaxRetries,
-		&createdAt,
-		&updatedAt,
-		&nextRetryAt,
-		&workerID,
-		&errMsg,
-		&output,
-	)
+// marshalNextJob encodes a job's follow-up job spec for storage
+func marshalNextJob(next *job.Job) (interface{}, error) {
+	if next == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(next)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode next_job: %w", err)
+	}
+	return string(data), nil
+}
 
+// marshalRetrySchedule encodes a job's retry schedule for storage
+func marshalRetrySchedule(schedule []job.Duration) (interface{}, error) {
+	if len(schedule) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(schedule)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to encode retry_schedule: %w", err)
 	}
+	return string(data), nil
+}
 
-	j.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
-	j.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+// marshalEnv encodes a job's environment variables for storage
+func marshalEnv(env map[string]string) (interface{}, error) {
+	if len(env) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode env: %w", err)
+	}
+	return string(data), nil
+}
 
-	if nextRetryAt.Valid {
-		t, _ := time.Parse(time.RFC3339, nextRetryAt.String)
-		j.NextRetryAt = &t
+// marshalTags encodes a job's tags for storage
+func marshalTags(tags []string) (interface{}, error) {
+	if len(tags) == 0 {
+		return nil, nil
 	}
-	if workerID.Valid {
-		j.WorkerID = workerID.String
+	data, err := json.Marshal(tags)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode tags: %w", err)
 	}
-	if errMsg.Valid {
-		j.Error = errMsg.String
+	return string(data), nil
+}
+
+// nullIfEmpty stores an empty string as NULL
+func nullIfEmpty(v string) interface{} {
+	if v == "" {
+		return nil
 	}
-	if output.Valid {
-		j.Output = output.String
+	return v
+}
+
+// dbTime formats a timestamp for storage. Timestamps are compared as
+// strings, so every one is written in the local zone whatever zone the
+// caller's value carries (e.g. a UTC time parsed from "...Z").
+func dbTime(t time.Time) string {
+	return t.Local().Format(time.RFC3339)
+}
+
+// formatNullTime formats an optional timestamp for storage
+func formatNullTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
 	}
+	return dbTime(*t)
+}
 
-	return j, nil
-}
\ No newline at end of file
+// parseNullTime parses an optional stored timestamp
+func parseNullTime(v sql.NullString) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	t, _ := time.Parse(time.RFC3339, v.String)
+	return &t
+}
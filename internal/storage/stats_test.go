@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+)
+
+func TestRecordStatsPrunesOldSamples(t *testing.T) {
+	for name, s := range testBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := s.SaveJob(job.NewJob("true", 3)); err != nil {
+				t.Fatal(err)
+			}
+
+			now := time.Now().Truncate(time.Second)
+			// Recorded without retention, so nothing is pruned yet
+			for _, age := range []time.Duration{72 * time.Hour, 36 * time.Hour, 12 * time.Hour} {
+				if err := s.RecordStats(now.Add(-age), 0); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := s.RecordStats(now, 48*time.Hour); err != nil {
+				t.Fatal(err)
+			}
+
+			samples, err := s.GetStatsHistory(now.Add(-100 * time.Hour))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []time.Time
+			for _, sample := range samples {
+				got = append(got, sample.Time)
+			}
+			want := []time.Time{now.Add(-36 * time.Hour), now.Add(-12 * time.Hour), now}
+			if len(got) != len(want) {
+				t.Fatalf("samples at %v, want %v", got, want)
+			}
+			for i := range want {
+				if !got[i].Equal(want[i]) {
+					t.Errorf("sample %d at %s, want %s", i, got[i], want[i])
+				}
+			}
+		})
+	}
+}
@@ -1,9 +1,141 @@
 package storage
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/MithileshwaranS/queuectl/internal/job"
 )
 
+// AllQueues is the queue name used to pause every queue at once
+const AllQueues = "*"
+
+// ErrJobExists is returned by InsertJob when a job with the same ID is
+// already stored
+var ErrJobExists = errors.New("job already exists")
+
+// ErrIdempotencyKeyInUse is returned when saving a job whose idempotency
+// key is held by another unfinished job
+var ErrIdempotencyKeyInUse = errors.New("idempotency key is in use by another job")
+
+// ErrScheduleExists is returned by AddSchedule when a schedule with the
+// same ID is already stored
+var ErrScheduleExists = errors.New("schedule already exists")
+
+// ErrNotClaimed is returned by SaveJobIfOwner when the job is no longer
+// processing under the worker, e.g. because it was deleted or requeued
+// while the worker ran it
+var ErrNotClaimed = errors.New("job is no longer claimed by this worker")
+
+// StateError is returned by the state-guarded writes (SaveJobIfState,
+// DeleteJobIfState) when the stored job is not in the state they require,
+// e.g. because a worker claimed it after the caller read it
+type StateError struct {
+	ID    string
+	Want  job.State
+	State job.State
+}
+
+func (e *StateError) Error() string {
+	return fmt.Sprintf("job %s is not %s (state: %s)", e.ID, e.Want, e.State)
+}
+
+// Schedule is a recurring job: each time its cron expression fires, the
+// worker pool enqueues a fresh job running Command in Queue
+type Schedule struct {
+	ID        string
+	Cron      string
+	Command   string
+	Queue     string
+	CreatedAt time.Time
+
+	// LastFiredAt is the latest fire time a job was enqueued for (nil
+	// until the schedule first fires)
+	LastFiredAt *time.Time
+}
+
+// ThroughputBucket holds the number of jobs completed in one time interval
+type ThroughputBucket struct {
+	Start time.Time
+	Count int
+}
+
+// StatsSample holds the job counts by state recorded at one point in time.
+// States without jobs are absent from Counts.
+type StatsSample struct {
+	Time   time.Time
+	Counts map[job.State]int
+}
+
+// ClaimCandidate is a job that can be claimed, with the priority used to
+// order it (its own priority plus any aging boost)
+type ClaimCandidate struct {
+	Job               *job.Job
+	EffectivePriority int
+}
+
+// ClaimFilter restricts which jobs a worker may claim. The zero value
+// matches every job.
+type ClaimFilter struct {
+	// CommandPrefixes limits claims to jobs whose command starts with any
+	// of the prefixes (empty matches every command)
+	CommandPrefixes []string
+
+	// Queues limits claims to jobs in any of the named queues (empty
+	// matches every queue)
+	Queues []string
+}
+
+// Fields ListJobs can sort by
+const (
+	SortCreated  = "created"
+	SortUpdated  = "updated"
+	SortPriority = "priority"
+	SortAttempts = "attempts"
+)
+
+// ValidSortFields lists the accepted ListOptions.SortBy values
+var ValidSortFields = []string{SortCreated, SortUpdated, SortPriority, SortAttempts}
+
+// ListOptions controls the order and page of ListJobs results. The zero
+// value returns every job, newest first.
+type ListOptions struct {
+	Limit     int    // Maximum number of jobs to return (0 = no limit)
+	Offset    int    // Number of jobs to skip first
+	SortBy    string // One of ValidSortFields ("" sorts by creation time)
+	Ascending bool   // Oldest/smallest first instead of newest/largest first
+	Tag       string // Only jobs carrying this tag ("" = any)
+	Queue     string // Only jobs in this queue ("" = any)
+
+	// UpdatedSince and UpdatedUntil bound updated_at, inclusive (zero =
+	// unbounded)
+	UpdatedSince time.Time
+	UpdatedUntil time.Time
+
+	WorkerID string // Only jobs last claimed by this worker ("" = any)
+}
+
+// Validate checks the sort field, page bounds and updated_at range
+func (o ListOptions) Validate() error {
+	if o.Limit < 0 || o.Offset < 0 {
+		return fmt.Errorf("limit and offset cannot be negative")
+	}
+	if !o.UpdatedSince.IsZero() && !o.UpdatedUntil.IsZero() && o.UpdatedUntil.Before(o.UpdatedSince) {
+		return fmt.Errorf("the end of the updated range is before its start")
+	}
+	if o.SortBy == "" {
+		return nil
+	}
+	for _, f := range ValidSortFields {
+		if o.SortBy == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid sort field: %s (valid: %s)", o.SortBy, strings.Join(ValidSortFields, ", "))
+}
+
 // Storage defines the interface for job persistence
 type Storage interface {
 	// Initialize sets up the storage (create tables, etc.)
@@ -15,26 +147,157 @@ type Storage interface {
 	// SaveJob creates or updates a job
 	SaveJob(j *job.Job) error
 
+	// InsertJob creates a job, failing with ErrJobExists if its ID is taken
+	InsertJob(j *job.Job) error
+
+	// SaveJobs creates or updates several jobs atomically
+	SaveJobs(jobs []*job.Job) error
+
+	// InsertJobs creates several jobs atomically, skipping those whose ID
+	// is already stored, and returns the skipped ones. Stored jobs are
+	// never replaced.
+	InsertJobs(jobs []*job.Job) ([]*job.Job, error)
+
+	// ReplaceJobs creates or replaces several jobs atomically, skipping
+	// those whose stored job a worker is processing, and returns the
+	// skipped ones
+	ReplaceJobs(jobs []*job.Job) ([]*job.Job, error)
+
 	// GetJob retrieves a job by ID
 	GetJob(id string) (*job.Job, error)
 
+	// FindByIdempotencyKey returns the job holding an idempotency key: an
+	// unfinished job with the key, or one that completed less than
+	// reuseAfter ago. It returns nil if the key is free.
+	FindByIdempotencyKey(key string, reuseAfter time.Duration) (*job.Job, error)
+
 	// GetNextPendingJob gets the next available pending job and locks it
 	// Returns nil if no jobs available
-	GetNextPendingJob(workerID string) (*job.Job, error)
+	GetNextPendingJob(workerID string, filter ClaimFilter) (*job.Job, error)
 
-	// ListJobs returns all jobs matching the given state
-	// If state is empty, returns all jobs
-	ListJobs(state job.State) ([]*job.Job, error)
+	// GetNextPendingJobs claims up to n available jobs at once, in the
+	// order n calls of GetNextPendingJob would. Either every returned job
+	// is locked or, on error, none is. Returns an empty slice if no jobs
+	// are available.
+	GetNextPendingJobs(workerID string, filter ClaimFilter, n int) ([]*job.Job, error)
+
+	// PreviewClaimOrder returns up to limit claimable jobs in the order
+	// GetNextPendingJob would claim them, without locking any
+	PreviewClaimOrder(limit int) ([]ClaimCandidate, error)
+
+	// ListJobs returns the jobs matching the given state, ordered and
+	// paged by opts. If state is empty, jobs in every state are returned.
+	ListJobs(state job.State, opts ListOptions) ([]*job.Job, error)
 
 	// GetJobStats returns counts of jobs by state
 	GetJobStats() (map[job.State]int, error)
 
+	// GetQueueStats returns counts of jobs by state in each queue that
+	// has jobs
+	GetQueueStats() (map[string]map[job.State]int, error)
+
+	// CountFailedAttempts returns the number of failed attempts recorded
+	// across all stored jobs (the sum of their attempts)
+	CountFailedAttempts() (int, error)
+
+	// SaveJobIfState updates a stored job to j only if the stored job is
+	// still in state, returning a *StateError otherwise. Commands that
+	// change a job they read use it so a concurrent claim is not undone.
+	SaveJobIfState(j *job.Job, state job.State) error
+
+	// SaveJobIfOwner updates a stored job to j only if it is still
+	// processing under workerID, wrapping ErrNotClaimed otherwise.
+	// Workers save the outcome of a job with it, so the result of a job
+	// deleted or requeued while it ran does not bring it back.
+	SaveJobIfOwner(j *job.Job, workerID string) error
+
 	// DeleteJob removes a job by ID
 	DeleteJob(id string) error
 
+	// DeleteJobIfState removes a job only if it is in state, returning a
+	// *StateError otherwise
+	DeleteJobIfState(id string, state job.State) error
+
+	// CancelJob moves a pending, held or failed job to StateCancelled and
+	// reports true. For a processing job it records a cancellation request
+	// for its worker instead and reports false. Jobs in other states
+	// cannot be cancelled.
+	CancelJob(id string) (bool, error)
+
+	// Heartbeat records that the worker running a processing job is
+	// still alive by refreshing its updated_at
+	Heartbeat(id string) error
+
+	// SetProgress records the percent done a processing job's command
+	// reported. Jobs in other states are left alone.
+	SetProgress(id string, progress int) error
+
+	// RecoverStaleJobs returns processing jobs whose updated_at is older
+	// than threshold to pending, as their worker is assumed to have died.
+	// It returns the jobs as they were before being requeued.
+	RecoverStaleJobs(threshold time.Duration) ([]*job.Job, error)
+
+	// IsCancelRequested reports whether CancelJob was called for the job
+	// while it was processing. Saving the job in any other state clears
+	// the request.
+	IsCancelRequested(id string) (bool, error)
+
+	// DeleteCompletedBefore removes completed jobs that finished before the
+	// cutoff and returns how many were deleted
+	DeleteCompletedBefore(cutoff time.Time) (int, error)
+
+	// DeleteJobsByState removes jobs in the given state last updated
+	// before olderThan and returns how many were deleted
+	DeleteJobsByState(state job.State, olderThan time.Time) (int, error)
+
 	// GetRetryableJobs returns failed jobs that are ready to retry
 	GetRetryableJobs() ([]*job.Job, error)
 
 	// GetDLQJobs returns all jobs in the dead letter queue
 	GetDLQJobs() ([]*job.Job, error)
+
+	// GetThroughput returns the number of jobs completed in each bucket
+	// of the given size since the given time
+	GetThroughput(since time.Time, bucket time.Duration) ([]ThroughputBucket, error)
+
+	// PauseQueue stops workers from claiming jobs in the queue
+	// (AllQueues pauses every queue)
+	PauseQueue(queue string) error
+
+	// ResumeQueue lets workers claim jobs in a paused queue again
+	ResumeQueue(queue string) error
+
+	// ListPausedQueues returns the names of all paused queues
+	ListPausedQueues() ([]string, error)
+
+	// RecordStats stores the current job counts by state as a sample and
+	// deletes the samples older than retention (0 keeps them all)
+	RecordStats(at time.Time, retention time.Duration) error
+
+	// GetStatsHistory returns the samples recorded since the given time
+	GetStatsHistory(since time.Time) ([]StatsSample, error)
+
+	// Optimize performs query-performance maintenance and returns a
+	// description of each step taken
+	Optimize() ([]string, error)
+
+	// AddSchedule stores a new schedule, failing with ErrScheduleExists
+	// if its ID is taken
+	AddSchedule(s *Schedule) error
+
+	// ListSchedules returns every schedule, oldest first
+	ListSchedules() ([]*Schedule, error)
+
+	// DeleteSchedule removes a schedule, returning sql.ErrNoRows if there
+	// is none with the ID
+	DeleteSchedule(id string) error
+
+	// MarkScheduleFired records that a schedule enqueued its job for the
+	// fire time at. Times before the recorded one are ignored.
+	MarkScheduleFired(id string, at time.Time) error
+}
+
+// staleReason is the error recorded on a job requeued by RecoverStaleJobs
+func staleReason(j *job.Job, threshold time.Duration) string {
+	return fmt.Sprintf("requeued: worker %s sent no heartbeat for %s", j.WorkerID, threshold)
 }
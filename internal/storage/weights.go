@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+)
+
+// queueScheduler picks which queue a claim is served from under weighted
+// fair scheduling, using smooth weighted round-robin so that over time each
+// queue is served in proportion to its weight
+type queueScheduler struct {
+	mu sync.Mutex
+	// weights is keyed by lowercase queue name (nil disables weighting)
+	weights map[string]int
+	// current holds each queue's accumulated round-robin credit
+	current map[string]int
+}
+
+// set replaces the weights and resets the round-robin state
+func (qs *queueScheduler) set(weights map[string]int) {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	qs.weights = weights
+	qs.current = make(map[string]int)
+}
+
+// enabled reports whether any queue weights are set
+func (qs *queueScheduler) enabled() bool {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	return qs.weights != nil
+}
+
+// weight returns the scheduling weight of a queue
+func (qs *queueScheduler) weight(queue string) int {
+	if w, ok := qs.weights[strings.ToLower(queue)]; ok && w > 0 {
+		return w
+	}
+	return 1
+}
+
+// pick chooses one of the queues that have claimable jobs. Queues without
+// work are not passed in and so do not accumulate credit. It returns ""
+// if queues is empty.
+func (qs *queueScheduler) pick(queues []string) string {
+	if len(queues) == 0 {
+		return ""
+	}
+	sort.Strings(queues)
+
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+
+	best, total := "", 0
+	for _, q := range queues {
+		w := qs.weight(q)
+		qs.current[q] += w
+		total += w
+		if best == "" || qs.current[q] > qs.current[best] {
+			best = q
+		}
+	}
+	qs.current[best] -= total
+	return best
+}
+
+// narrow picks a queue among the claimable jobs' queues and returns only
+// the jobs in it, for stores that select candidates before ordering them
+func (qs *queueScheduler) narrow(candidates []*job.Job) []*job.Job {
+	seen := make(map[string]bool)
+	var queues []string
+	for _, j := range candidates {
+		if !seen[j.Queue] {
+			seen[j.Queue] = true
+			queues = append(queues, j.Queue)
+		}
+	}
+	queue := qs.pick(queues)
+
+	var inQueue []*job.Job
+	for _, j := range candidates {
+		if j.Queue == queue {
+			inQueue = append(inQueue, j)
+		}
+	}
+	return inQueue
+}
@@ -0,0 +1,91 @@
+// Package version holds the build information of the queuectl binary.
+// Release builds set the variables at link time, e.g.
+//
+//	go build -ldflags "-X github.com/MithileshwaranS/queuectl/internal/version.Version=1.2.0" ./cmd/queuectl
+//
+// The Makefile's build target sets all three.
+package version
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+var (
+	// Version is the release, e.g. 1.2.0
+	Version = "dev"
+	// Commit is the git commit the binary was built from
+	Commit = ""
+	// Date is when the binary was built, in RFC 3339
+	Date = ""
+)
+
+// Info describes a build
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+// Get returns the build information. Values not set with -ldflags fall
+// back to what the Go toolchain embedded: the module version for
+// 'go install ...@version', the VCS revision and time for builds of a
+// git checkout.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	if info.Version == "dev" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		info.Version = bi.Main.Version
+	}
+	var dirty bool
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = s.Value
+				if len(info.Commit) > 12 {
+					info.Commit = info.Commit[:12]
+				}
+			}
+		case "vcs.time":
+			if info.Date == "" {
+				info.Date = s.Value
+			}
+		case "vcs.modified":
+			dirty = s.Value == "true"
+		}
+	}
+	// Only flag the embedded revision; a commit set at link time is
+	// reported as given
+	if dirty && Commit == "" && info.Commit != "" {
+		info.Commit += "-dirty"
+	}
+	return info
+}
+
+// String renders the build on one line, e.g.
+// "1.2.0 (commit 3f2a9c1, built 2025-11-07T03:00:00Z, go1.23.4 linux/amd64)"
+func (i Info) String() string {
+	commit := i.Commit
+	if commit == "" {
+		commit = "unknown"
+	}
+	date := i.Date
+	if date == "" {
+		date = "unknown"
+	}
+	return fmt.Sprintf("%s (commit %s, built %s, %s %s)", i.Version, commit, date, i.GoVersion, i.Platform)
+}
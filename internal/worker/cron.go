@@ -0,0 +1,169 @@
+package worker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/MithileshwaranS/queuectl/internal/storage"
+	"github.com/robfig/cron/v3"
+)
+
+// scheduleCheckInterval is how often the pool looks for schedules that
+// are due. Cron fires at minute boundaries, so jobs start at most this
+// late.
+const scheduleCheckInterval = 5 * time.Second
+
+// ParseCron parses a standard five-field cron expression, or a descriptor
+// such as @daily, in local time. A CRON_TZ= prefix selects another zone.
+func ParseCron(expr string) (cron.Schedule, error) {
+	sched, err := cron.ParseStandard(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %w", expr, err)
+	}
+	return sched, nil
+}
+
+// NextFire returns when a schedule fires next after it last fired, or
+// after it was created if it has not fired yet
+func NextFire(sc *storage.Schedule) (time.Time, error) {
+	sched, err := ParseCron(sc.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return sched.Next(lastFire(sc)), nil
+}
+
+// lastFire returns the time a schedule's next fire is counted from
+func lastFire(sc *storage.Schedule) time.Time {
+	if sc.LastFiredAt != nil {
+		return *sc.LastFiredAt
+	}
+	return sc.CreatedAt
+}
+
+// dueFire returns the latest time a schedule fired at up to now that it
+// has not enqueued a job for, and false if there is none. Fires missed
+// while no pool was running are coalesced into that one.
+func dueFire(sched cron.Schedule, sc *storage.Schedule, now time.Time) (time.Time, bool) {
+	at := sched.Next(lastFire(sc))
+	if at.IsZero() || at.After(now) {
+		return time.Time{}, false
+	}
+	for next := sched.Next(at); !next.IsZero() && !next.After(now); next = sched.Next(next) {
+		at = next
+	}
+	return at, true
+}
+
+// ScheduleJobID returns the ID of the job a schedule enqueues for the fire
+// time at. The ID is the same for every pool, so a fire is enqueued once
+// even if pools race or one restarts before recording it.
+func ScheduleJobID(scheduleID string, at time.Time) string {
+	return fmt.Sprintf("%s-%s", scheduleID, at.UTC().Format("20060102T150405Z"))
+}
+
+// runSchedules enqueues the jobs of due schedules, once on start and then
+// periodically until stop is closed
+func (p *Pool) runSchedules(stop <-chan struct{}) {
+	ticker := time.NewTicker(scheduleCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		p.fireSchedules(time.Now())
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// fireSchedules enqueues one job for every schedule that is due at now
+func (p *Pool) fireSchedules(now time.Time) {
+	schedules, err := p.storage.get().ListSchedules()
+	if err != nil {
+		p.log.Warn("Failed to list schedules", "error", err)
+		return
+	}
+
+	for _, sc := range schedules {
+		sched, err := ParseCron(sc.Cron)
+		if err != nil {
+			p.log.Warn("Skipping schedule", "schedule_id", sc.ID, "error", err)
+			continue
+		}
+		at, ok := dueFire(sched, sc, now)
+		if !ok {
+			continue
+		}
+
+		if !p.enqueueFire(sc, at) {
+			continue
+		}
+		if err := p.storage.get().MarkScheduleFired(sc.ID, at); err != nil {
+			p.log.Warn("Failed to record schedule fire", "schedule_id", sc.ID, "error", err)
+		}
+	}
+}
+
+// enqueueFire enqueues the job of a schedule for the fire time at,
+// reporting whether the fire is dealt with and can be recorded
+func (p *Pool) enqueueFire(sc *storage.Schedule, at time.Time) bool {
+	if err := p.checkScheduleSchema(sc); err != nil {
+		// Recorded all the same, so each fire is reported only once
+		p.log.Warn("Skipping schedule fire", "schedule_id", sc.ID, "error", err)
+		return true
+	}
+
+	j := p.scheduledJob(sc, at)
+	err := p.storage.get().InsertJob(j)
+	switch {
+	case errors.Is(err, storage.ErrJobExists):
+		// Already enqueued by another pool, or by this one before a
+		// restart that came before the fire was recorded
+	case err != nil:
+		// Not recorded, so the next check tries again
+		p.log.Warn("Failed to enqueue scheduled job", "schedule_id", sc.ID, "error", err)
+		return false
+	default:
+		p.log.Info("Schedule fired",
+			"schedule_id", sc.ID, "job_id", j.ID, "queue", j.Queue, "command", j.Command)
+	}
+	return true
+}
+
+// ScheduleSpec returns the job JSON a schedule's jobs are checked against
+// the job schema as: the fields an equivalent enqueue would give
+func ScheduleSpec(sc *storage.Schedule) string {
+	data, _ := json.Marshal(map[string]string{"command": sc.Command, "queue": sc.Queue})
+	return string(data)
+}
+
+// checkScheduleSchema checks a schedule's jobs against the configured job
+// schema. The file is read on each fire, so edits to it apply without a
+// restart.
+func (p *Pool) checkScheduleSchema(sc *storage.Schedule) error {
+	if p.config.JobSchemaPath == "" {
+		return nil
+	}
+	schema, err := job.LoadSchema(p.config.JobSchemaPath)
+	if err != nil {
+		return err
+	}
+	return schema.Validate(ScheduleSpec(sc))
+}
+
+// scheduledJob builds the job a schedule enqueues for the fire time at,
+// with the same defaults enqueue applies
+func (p *Pool) scheduledJob(sc *storage.Schedule, at time.Time) *job.Job {
+	j := job.NewJob(sc.Command, 0)
+	j.ID = ScheduleJobID(sc.ID, at)
+	j.Queue = sc.Queue
+	j.Tags = []string{"schedule:" + sc.ID}
+	ApplyQueueDefaults(j, p.config, nil)
+	return j
+}
@@ -0,0 +1,31 @@
+package worker
+
+import (
+	"encoding/json"
+
+	"github.com/MithileshwaranS/queuectl/internal/config"
+	"github.com/MithileshwaranS/queuectl/internal/job"
+)
+
+// ApplyQueueDefaults fills in the fields of j that its spec left out from
+// the defaults of j's queue. specified holds the spec's fields by JSON
+// name; a nil map means the job was built without a spec (e.g. by a
+// recurring schedule), so every field gets its default. Enqueue, next_job
+// and schedules all go through here so a job is the same however it was
+// created.
+func ApplyQueueDefaults(j *job.Job, cfg *config.Config, specified map[string]json.RawMessage) {
+	defaults := cfg.QueueDefaults(j.Queue)
+	if _, ok := specified["max_retries"]; !ok {
+		j.MaxRetries = *defaults.MaxRetries
+		// A retry schedule implies one retry per entry
+		if len(j.RetrySchedule) > 0 {
+			j.MaxRetries = len(j.RetrySchedule)
+		}
+	}
+	if _, ok := specified["timeout_seconds"]; !ok {
+		j.TimeoutSeconds = defaults.TimeoutSeconds
+	}
+	if _, ok := specified["backoff_base"]; !ok {
+		j.BackoffBase = defaults.BackoffBase
+	}
+}
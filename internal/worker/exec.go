@@ -0,0 +1,173 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/MithileshwaranS/queuectl/internal/config"
+	"github.com/MithileshwaranS/queuectl/internal/job"
+)
+
+// DefaultTimeout is how long a job may run when neither it nor the config
+// sets a timeout
+const DefaultTimeout = 5 * time.Minute
+
+// JobTimeout returns how long the job's command may run: its own
+// timeout_seconds, else the job_timeout_seconds config value
+func JobTimeout(j *job.Job, cfg *config.Config) time.Duration {
+	if j.TimeoutSeconds > 0 {
+		return time.Duration(j.TimeoutSeconds) * time.Second
+	}
+	if cfg != nil && cfg.JobTimeoutSeconds > 0 {
+		return time.Duration(cfg.JobTimeoutSeconds) * time.Second
+	}
+	return DefaultTimeout
+}
+
+// JobEnv returns the variables added to the worker's environment for the
+// job: the env_file contents, then the job's env, then the parent job's
+// output. Later entries win. Sandboxed jobs also get QUEUECTL_SANDBOX once
+// their directory exists.
+func JobEnv(j *job.Job) ([]string, error) {
+	var env []string
+	if j.EnvFile != "" {
+		fileEnv, err := loadEnvFile(j.EnvFile)
+		if err != nil {
+			return nil, err
+		}
+		env = append(env, fileEnv...)
+	}
+
+	names := make([]string, 0, len(j.Env))
+	for name := range j.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		env = append(env, name+"="+j.Env[name])
+	}
+
+	// Follow-up jobs see the output of the job that enqueued them
+	if j.ParentID != "" {
+		env = append(env, "QUEUECTL_PARENT_OUTPUT="+j.ParentOutput)
+	}
+	return env, nil
+}
+
+// JobCommand builds the process that runs command for the job exactly as
+// a worker does, in the job's work_dir if it has one. Sandboxed jobs get a
+// fresh temporary directory, which the returned cleanup func removes; it
+// must be called once the command ends.
+func JobCommand(ctx context.Context, j *job.Job, command string) (*exec.Cmd, func() error, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = j.WorkDir
+	cleanup := func() error { return nil }
+
+	extraEnv, err := JobEnv(j)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Run sandboxed jobs in a fresh temp directory that is always removed
+	if j.Sandbox {
+		dir, err := os.MkdirTemp("", "queuectl-sandbox-")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create sandbox: %w", err)
+		}
+		cleanup = func() error { return os.RemoveAll(dir) }
+		cmd.Dir = dir
+		extraEnv = append(extraEnv, "QUEUECTL_SANDBOX="+dir)
+	}
+
+	if len(extraEnv) > 0 {
+		cmd.Env = append(os.Environ(), extraEnv...)
+	}
+	return cmd, cleanup, nil
+}
+
+// RecordUsage stores the CPU time and peak memory of the finished command
+// on the job. It does nothing if the command never started.
+func RecordUsage(j *job.Job, cmd *exec.Cmd) {
+	state := cmd.ProcessState
+	if state == nil {
+		return
+	}
+	j.CPUTimeMS = (state.UserTime() + state.SystemTime()).Milliseconds()
+	j.MaxRSSKB = maxRSSKB(state)
+}
+
+// FormatUsage renders a job's recorded resource usage, e.g.
+// "cpu 1.25s, max rss 12.3 MB"
+func FormatUsage(j *job.Job) string {
+	return fmt.Sprintf("cpu %.2fs, max rss %.1f MB", float64(j.CPUTimeMS)/1000, float64(j.MaxRSSKB)/1024)
+}
+
+// capOutput limits output to maxLines lines, keeping the last ones unless
+// keep is config.OutputKeepFirst. A note records how many were dropped.
+// A maxLines of 0 returns output unchanged.
+func capOutput(output string, maxLines int, keep string) string {
+	if maxLines <= 0 {
+		return output
+	}
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	if len(lines) <= maxLines {
+		return output
+	}
+
+	dropped := len(lines) - maxLines
+	if keep == config.OutputKeepFirst {
+		return strings.Join(lines[:maxLines], "\n") + fmt.Sprintf("\n... (%d more lines omitted)", dropped)
+	}
+	return fmt.Sprintf("... (%d earlier lines omitted)\n", dropped) + strings.Join(lines[dropped:], "\n")
+}
+
+// tailBuffer is a writer that holds only the last max bytes written to it
+// (all of them if max is 0). String prefixes the tail with a note of how
+// many bytes were dropped.
+type tailBuffer struct {
+	max   int
+	buf   []byte
+	total int64 // bytes written, including dropped ones
+}
+
+// Write appends p, dropping the oldest bytes beyond max
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	b.total += int64(n)
+	if b.max > 0 && len(p) >= b.max {
+		b.buf = append(b.buf[:0], p[len(p)-b.max:]...)
+		return n, nil
+	}
+	b.buf = append(b.buf, p...)
+	// Let the buffer reach twice max before moving the tail to the front,
+	// so each byte is copied about once rather than on every write
+	if b.max > 0 && len(b.buf) > 2*b.max {
+		b.buf = append(b.buf[:0], b.buf[len(b.buf)-b.max:]...)
+	}
+	return n, nil
+}
+
+// String returns the bytes held, after a "[truncated N bytes]" line if
+// any were dropped
+func (b *tailBuffer) String() string {
+	tail := b.buf
+	if b.max > 0 && len(tail) > b.max {
+		tail = tail[len(tail)-b.max:]
+	}
+	dropped := b.total - int64(len(tail))
+	if dropped == 0 {
+		return string(tail)
+	}
+	// Do not start in the middle of a UTF-8 sequence
+	for len(tail) > 0 && !utf8.RuneStart(tail[0]) {
+		tail = tail[1:]
+		dropped++
+	}
+	return fmt.Sprintf("[truncated %d bytes]\n", dropped) + string(tail)
+}
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// HeartbeatInterval is how often a running pool refreshes the heartbeat
+// file of each of its workers
+const HeartbeatInterval = 2 * time.Second
+
+// Heartbeat is the state a worker last reported in its heartbeat file
+type Heartbeat struct {
+	PID  int       `json:"pid"`
+	Time time.Time `json:"time"`
+	Jobs []string  `json:"jobs"` // IDs of the jobs the worker is running
+}
+
+// HeartbeatPath returns the heartbeat file of a worker,
+// workers/<id>.json in the profile directory, kept next to its PID file
+func HeartbeatPath(workerID string) string {
+	return filepath.Join(WorkersDir(), workerID+".json")
+}
+
+// ReadHeartbeat returns the last heartbeat written for a worker
+func ReadHeartbeat(workerID string) (*Heartbeat, error) {
+	path := HeartbeatPath(workerID)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var hb Heartbeat
+	if err := json.Unmarshal(data, &hb); err != nil {
+		return nil, err
+	}
+	return &hb, nil
+}
+
+// writeHeartbeat records that w is alive and which jobs it is running.
+// The file is replaced by a rename so readers never see a partial write.
+func writeHeartbeat(w *Worker) error {
+	path := HeartbeatPath(w.ID)
+	data, err := json.Marshal(Heartbeat{
+		PID:  os.Getpid(),
+		Time: time.Now(),
+		Jobs: w.runningJobs(),
+	})
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// removeHeartbeat deletes a worker's heartbeat file
+func removeHeartbeat(workerID string) error {
+	path := HeartbeatPath(workerID)
+	return os.Remove(path)
+}
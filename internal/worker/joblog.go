@@ -0,0 +1,24 @@
+package worker
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/MithileshwaranS/queuectl/internal/config"
+)
+
+// JobLogPath returns the file a job's output is streamed to while it runs,
+// logs/<id>.log in the profile directory. Each attempt replaces the
+// previous one's log.
+func JobLogPath(jobID string) string {
+	return filepath.Join(config.Dir(), "logs", jobID+".log")
+}
+
+// createJobLog creates or truncates the job's log file
+func createJobLog(jobID string) (*os.File, error) {
+	path := JobLogPath(jobID)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	return os.Create(path)
+}
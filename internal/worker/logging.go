@@ -0,0 +1,162 @@
+package worker
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/MithileshwaranS/queuectl/internal/config"
+)
+
+// logSink is the writer a pool and its workers log to. Every logger of a
+// pool shares one, so SetLogOutput redirects them all at once.
+type logSink struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// Write writes one log record to the current output
+func (s *logSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.out.Write(p)
+}
+
+// setOutput replaces the output
+func (s *logSink) setOutput(out io.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.out = out
+}
+
+// newLogger returns a logger writing to sink in the configured log_format
+// at or above the configured log_level. Unknown values fall back to text
+// and info, which config set never stores.
+func newLogger(sink io.Writer, cfg *config.Config) *slog.Logger {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+		level = slog.LevelInfo
+	}
+
+	if cfg.LogFormat == config.LogFormatJSON {
+		return slog.New(slog.NewJSONHandler(sink, &slog.HandlerOptions{Level: level}))
+	}
+	return slog.New(&textHandler{out: sink, level: level})
+}
+
+// textHandler writes the human-readable log lines queuectl has always
+// printed: a timestamp, the worker the record came from and the message,
+// followed by the record's attributes as key=value pairs.
+type textHandler struct {
+	out   io.Writer
+	level slog.Level
+	// prefix names the worker, from a worker_id attribute added with With
+	prefix string
+	// attrs holds the other attributes added with With, already formatted
+	attrs string
+	// group is the key prefix of attributes added after WithGroup
+	group string
+}
+
+// Enabled reports whether records at level are written
+func (h *textHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+// Handle writes one record as a single line
+func (h *textHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	b.WriteString(r.Time.Format("2006/01/02 15:04:05 "))
+	b.WriteString(h.prefix)
+	if r.Level == slog.LevelWarn {
+		b.WriteString("Warning: ")
+	}
+	b.WriteString(r.Message)
+	b.WriteString(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		appendAttr(&b, h.group, a)
+		return true
+	})
+	b.WriteByte('\n')
+	_, err := io.WriteString(h.out, b.String())
+	return err
+}
+
+// WithAttrs picks up the worker_id attribute for the line prefix and
+// formats the rest once, ahead of every record
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	var b strings.Builder
+	b.WriteString(c.attrs)
+	for _, a := range attrs {
+		if a.Key == "worker_id" && c.group == "" {
+			c.prefix = "[Worker " + a.Value.String() + "] "
+			continue
+		}
+		appendAttr(&b, c.group, a)
+	}
+	c.attrs = b.String()
+	return &c
+}
+
+// WithGroup qualifies the keys of later attributes with name
+func (h *textHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := *h
+	c.group += name + "."
+	return &c
+}
+
+// appendAttr writes a as " key=value", flattening groups into dotted keys
+// and quoting values that would otherwise be ambiguous
+func appendAttr(b *strings.Builder, group string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			group += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			appendAttr(b, group, ga)
+		}
+		return
+	}
+
+	var v string
+	switch a.Value.Kind() {
+	case slog.KindTime:
+		v = a.Value.Time().Format(time.RFC3339)
+	default:
+		v = a.Value.String()
+	}
+	b.WriteByte(' ')
+	b.WriteString(group)
+	b.WriteString(a.Key)
+	b.WriteByte('=')
+	if needsQuoting(v) {
+		v = strconv.Quote(v)
+	}
+	b.WriteString(v)
+}
+
+// needsQuoting reports whether v must be quoted to read back as one value
+func needsQuoting(v string) bool {
+	if v == "" {
+		return true
+	}
+	for _, r := range v {
+		if r == '=' || r == '"' || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
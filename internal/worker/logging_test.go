@@ -0,0 +1,35 @@
+package worker
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTextHandlerAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(&textHandler{out: &buf, level: slog.LevelInfo}).With("worker_id", "w1", "queue", "default")
+
+	log.Warn("Job failed", "job_id", "j1", "error", errors.New("exit status 1"), "retry_in", 2*time.Second, "empty", "")
+	log.WithGroup("usage").Info("Job completed", "cpu_ms", 5)
+	log.Debug("Not written")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), buf.String())
+	}
+
+	// Drop the timestamp
+	want := []string{
+		`[Worker w1] Warning: Job failed queue=default job_id=j1 error="exit status 1" retry_in=2s empty=""`,
+		`[Worker w1] Job completed queue=default usage.cpu_ms=5`,
+	}
+	for i, line := range lines {
+		if got := line[len("2006/01/02 15:04:05 "):]; got != want[i] {
+			t.Errorf("line %d = %q, want %q", i, got, want[i])
+		}
+	}
+}
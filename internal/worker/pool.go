@@ -1,42 +1,95 @@
 package worker
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 	"syscall"
+	"time"
 
+	"github.com/MithileshwaranS/queuectl/internal/audit"
 	"github.com/MithileshwaranS/queuectl/internal/config"
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/MithileshwaranS/queuectl/internal/metrics"
+	"github.com/MithileshwaranS/queuectl/internal/notify"
 	"github.com/MithileshwaranS/queuectl/internal/storage"
+	"golang.org/x/time/rate"
 )
 
+// exportShutdownTimeout bounds the final metrics push on Stop
+const exportShutdownTimeout = 10 * time.Second
+
 // Pool manages multiple workers
 type Pool struct {
 	workers []*Worker
-	storage storage.Storage
+	// storage is shared with the workers, so a reconnect by any of them
+	// also reaches the background tasks
+	storage *sharedStorage
 	config  *config.Config
-	logger  *log.Logger
+	log     *slog.Logger
+	logSink *logSink
 	mu      sync.Mutex
+
+	// filter restricts which jobs the workers claim
+	filter storage.ClaimFilter
+	// concurrency is how many jobs each worker runs at once
+	concurrency int
+
+	// fatal receives errors from workers that stopped themselves
+	fatal       chan error
+	exitOnFatal bool
+
+	// maxLifetime stops the pool this long after Start (0 disables)
+	maxLifetime time.Duration
+	expired     <-chan time.Time
+
+	// bgStop stops the pool's periodic tasks (retention sweep, stats,
+	// stale job recovery, recurring schedules)
+	bgStop chan struct{}
+
+	// exporter pushes worker metrics to a collector (nil disables)
+	exporter *metrics.OTLPExporter
+
+	// heartbeatStop stops the heartbeat files from being refreshed once
+	// every worker has stopped
+	heartbeatStop chan struct{}
+	heartbeatDone chan struct{}
 }
 
 // NewPool creates a new worker pool
 func NewPool(store storage.Storage, cfg *config.Config, count int) *Pool {
-	logger := log.New(os.Stdout, "", log.LstdFlags)
+	sink := &logSink{out: os.Stdout}
+	logger := newLogger(sink, cfg)
     
+	shared := &sharedStorage{current: store}
 	pool := &Pool{
 		workers: make([]*Worker, 0, count),
-		storage: store,
+		storage: shared,
 		config:  cfg,
-		logger:  logger,
+		log:     logger,
+		logSink: sink,
+		fatal:   make(chan error, count),
+	}
+
+	// One limiter paces every worker, so the rate holds for the pool
+	var limiter *rate.Limiter
+	if cfg.JobsPerSecond > 0 {
+		limiter = rate.NewLimiter(rate.Limit(cfg.JobsPerSecond), 1)
 	}
 
 	// Create workers
 	for i := 0; i < count; i++ {
 		worker := NewWorker(store, cfg, logger)
+		worker.storage = shared
+		worker.onFatal = pool.workerFailed
+		worker.limiter = limiter
 		pool.workers = append(pool.workers, worker)
 	}
 
@@ -48,21 +101,61 @@ func (p *Pool) Start() error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	p.logger.Printf("Starting %d worker(s)...", len(p.workers))
+	p.log.Info("Starting workers", "workers", len(p.workers))
+	if p.concurrency > 1 {
+		p.log.Info("Each worker runs several jobs at once", "concurrency", p.concurrency)
+	}
+	if p.config.JobsPerSecond > 0 {
+		p.log.Info("Limiting job starts across all workers", "jobs_per_second", p.config.JobsPerSecond)
+	}
+	if len(p.filter.CommandPrefixes) > 0 {
+		p.log.Info("Only claiming jobs with command prefixes", "command_prefixes", p.filter.CommandPrefixes)
+	}
+	if len(p.filter.Queues) > 0 {
+		p.log.Info("Only claiming jobs in queues", "queues", p.filter.Queues)
+	}
 
 	// Start all workers
 	for _, w := range p.workers {
 		w.Start()
-		p.logger.Printf("Worker %s started", w.ID)
+		p.log.Info("Worker started", "worker_id", w.ID)
         
 		// Save worker PID for tracking
 		if err := p.saveWorkerPID(w.ID); err != nil {
-			p.logger.Printf("Warning: Failed to save worker PID: %v", err)
+			p.log.Warn("Failed to save worker PID", "worker_id", w.ID, "error", err)
 		}
 	}
 
-	p.logger.Println("All workers started successfully")
-	p.logger.Println("Press Ctrl+C to stop workers gracefully")
+	p.heartbeatStop = make(chan struct{})
+	p.heartbeatDone = make(chan struct{})
+	go p.writeHeartbeats(p.heartbeatStop, p.heartbeatDone)
+
+	if p.maxLifetime > 0 {
+		p.expired = time.After(p.maxLifetime)
+		p.log.Info("Workers will exit after their max lifetime", "max_lifetime", p.maxLifetime)
+	}
+
+	p.bgStop = make(chan struct{})
+	if p.config.CompletedRetention > 0 {
+		go p.sweepCompleted(p.config.CompletedRetention, p.bgStop)
+		p.log.Info("Deleting completed jobs past retention", "retention", p.config.CompletedRetention)
+	}
+	if p.config.StatsInterval > 0 {
+		go p.recordStats(p.config.StatsInterval, p.bgStop)
+		p.log.Info("Recording job counts", "interval", p.config.StatsInterval)
+	}
+	if p.config.StaleJobThreshold > 0 {
+		go p.recoverStale(p.config.StaleJobThreshold, p.bgStop)
+		p.log.Info("Requeueing processing jobs without a heartbeat", "threshold", p.config.StaleJobThreshold)
+	}
+	go p.runSchedules(p.bgStop)
+
+	if p.exporter != nil {
+		p.log.Info("Exporting metrics", "url", p.exporter.URL, "interval", metrics.ExportInterval)
+	}
+
+	p.log.Info("All workers started successfully")
+	p.log.Info("Press Ctrl+C to stop workers gracefully")
 
 	return nil
 }
@@ -72,7 +165,12 @@ func (p *Pool) Stop() {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	p.logger.Println("Stopping all workers...")
+	p.log.Info("Stopping all workers...")
+
+	if p.bgStop != nil {
+		close(p.bgStop)
+		p.bgStop = nil
+	}
 
 	// Stop all workers
 	var wg sync.WaitGroup
@@ -86,21 +184,264 @@ func (p *Pool) Stop() {
 	}
 
 	wg.Wait()
-	p.logger.Println("All workers stopped")
+
+	if p.heartbeatStop != nil {
+		close(p.heartbeatStop)
+		<-p.heartbeatDone
+		p.heartbeatStop = nil
+	}
+
+	// Flush the final measurements once every worker is done
+	if p.exporter != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), exportShutdownTimeout)
+		if err := p.exporter.Shutdown(ctx); err != nil {
+			p.log.Warn("Failed to export metrics", "error", err)
+		}
+		cancel()
+		p.exporter = nil
+	}
+
+	p.storage.close()
+	p.log.Info("All workers stopped")
 }
 
-// Wait blocks until workers are stopped (by signal)
-func (p *Pool) Wait() {
+// Wait blocks until workers are stopped (by signal or fatal error).
+// It returns the fatal error that caused the pool to exit, if any.
+func (p *Pool) Wait() error {
 	// Setup signal handling for graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
-	// Wait for signal
-	sig := <-sigChan
-	p.logger.Printf("Received signal: %v", sig)
+	failed := 0
+	for {
+		select {
+		case sig := <-sigChan:
+			p.log.Info("Received signal", "signal", sig.String())
+
+			// Stop all workers gracefully
+			p.Stop()
+			return nil
+		case <-p.expired:
+			p.log.Info("Reached max lifetime", "max_lifetime", p.maxLifetime)
+			p.Stop()
+			return nil
+		case err := <-p.fatal:
+			failed++
+			if p.exitOnFatal || failed == p.GetWorkerCount() {
+				p.log.Error("Exiting", "error", err)
+				p.Stop()
+				return err
+			}
+		}
+	}
+}
+
+// sweepCompleted deletes completed jobs older than retention, once on start
+// and then periodically until stop is closed
+func (p *Pool) sweepCompleted(retention time.Duration, stop <-chan struct{}) {
+	interval := retention
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := p.storage.get().DeleteCompletedBefore(time.Now().Add(-retention))
+		if err != nil {
+			p.log.Warn("Failed to reap completed jobs", "error", err)
+		} else if n > 0 {
+			p.log.Info("Reaped completed jobs", "jobs", n, "retention", retention)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// recordStats samples the job counts by state every interval until stop
+// is closed
+func (p *Pool) recordStats(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.storage.get().RecordStats(time.Now(), p.config.StatsRetention); err != nil {
+			p.log.Warn("Failed to record job stats", "error", err)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// recoverStale returns jobs whose worker stopped sending heartbeats to
+// pending, once on start and then periodically until stop is closed.
+// Workers heartbeat several times per threshold, so jobs that are still
+// running, however long, are never requeued.
+func (p *Pool) recoverStale(threshold time.Duration, stop <-chan struct{}) {
+	interval := threshold
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		jobs, err := p.storage.get().RecoverStaleJobs(threshold)
+		if err != nil {
+			p.log.Warn("Failed to recover stale jobs", "error", err)
+		}
+		for _, j := range jobs {
+			p.log.Info("Requeued job without a heartbeat",
+				"job_id", j.ID, "worker_id", j.WorkerID, "state", job.StatePending, "threshold", threshold)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// writeHeartbeats refreshes every worker's heartbeat file each
+// HeartbeatInterval, and removes the files when stop is closed. It keeps
+// running during a graceful shutdown, so workers finishing long jobs are
+// not reported as stalled.
+func (p *Pool) writeHeartbeats(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		for _, w := range p.workers {
+			if err := writeHeartbeat(w); err != nil {
+				p.log.Warn("Failed to write heartbeat", "worker_id", w.ID, "error", err)
+			}
+		}
 
-	// Stop all workers gracefully
-	p.Stop()
+		select {
+		case <-stop:
+			for _, w := range p.workers {
+				removeHeartbeat(w.ID)
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SetOTLPExporter makes every worker record job metrics in e, which
+// also reports the DLQ size. Stop pushes the final measurements and shuts
+// e down.
+func (p *Pool) SetOTLPExporter(e *metrics.OTLPExporter) error {
+	err := e.ObserveDLQSize(func() (int, error) {
+		stats, err := p.storage.get().GetJobStats()
+		return stats[job.StateDead], err
+	})
+	if err != nil {
+		return err
+	}
+	e.OnError(func(err error) {
+		p.log.Warn("Failed to export metrics", "error", err)
+	})
+
+	p.exporter = e
+	for _, w := range p.workers {
+		w.metrics = e
+	}
+	return nil
+}
+
+// SetPromRecorder makes every worker count finished attempts in r
+func (p *Pool) SetPromRecorder(r *metrics.PromRecorder) {
+	for _, w := range p.workers {
+		w.prom = r
+	}
+}
+
+// SetClaimFilter restricts the jobs every worker in the pool claims
+func (p *Pool) SetClaimFilter(f storage.ClaimFilter) {
+	p.filter = f
+	for _, w := range p.workers {
+		w.filter = f
+	}
+}
+
+// SetConcurrency sets how many jobs each worker runs at once
+func (p *Pool) SetConcurrency(n int) {
+	p.concurrency = n
+	for _, w := range p.workers {
+		w.concurrency = n
+	}
+}
+
+// SetShutdownTimeout bounds how long Stop waits for running jobs before
+// killing them and returning them to pending (0 waits until they finish)
+func (p *Pool) SetShutdownTimeout(d time.Duration) {
+	for _, w := range p.workers {
+		w.shutdownTimeout = d
+	}
+}
+
+// SetReconnect lets the pool re-open the database with open when it
+// becomes unreachable, instead of failing until restarted. The new
+// connection replaces the old one for every worker and background task,
+// so open must configure it as the original was.
+func (p *Pool) SetReconnect(open func() (storage.Storage, error)) {
+	p.storage.setReconnect(open)
+}
+
+// SetNotifier sets the notifier every worker uses for terminal job transitions
+func (p *Pool) SetNotifier(n notify.Notifier) {
+	for _, w := range p.workers {
+		w.notifier = n
+	}
+}
+
+// SetJobLogs sets whether workers stream job output to log files for
+// 'queuectl logs' (on by default)
+func (p *Pool) SetJobLogs(enabled bool) {
+	for _, w := range p.workers {
+		w.noJobLogs = !enabled
+	}
+}
+
+// SetAuditor sets the sink every worker records job starts to
+func (p *Pool) SetAuditor(a *audit.Sink) {
+	for _, w := range p.workers {
+		w.auditor = a
+	}
+}
+
+// SetMaxLifetime makes the pool stop gracefully once the given duration
+// has passed since Start. Workers finish their current jobs first.
+func (p *Pool) SetMaxLifetime(d time.Duration) {
+	p.maxLifetime = d
+}
+
+// SetExitOnFatal makes the whole pool exit as soon as any worker stops on
+// a fatal error. By default the pool only exits once every worker has.
+func (p *Pool) SetExitOnFatal(exit bool) {
+	p.exitOnFatal = exit
+}
+
+// workerFailed records a worker that stopped itself on a fatal error
+func (p *Pool) workerFailed(w *Worker, err error) {
+	p.fatal <- fmt.Errorf("worker %s: %w", w.ID, err)
+}
+
+// SetLogOutput redirects the pool and worker logs to w
+func (p *Pool) SetLogOutput(w io.Writer) {
+	p.logSink.setOutput(w)
 }
 
 // GetWorkerCount returns the number of workers
@@ -110,43 +451,35 @@ func (p *Pool) GetWorkerCount() int {
 	return len(p.workers)
 }
 
+// WorkersDir returns the directory holding the PID and heartbeat files of
+// the active profile's workers
+func WorkersDir() string {
+	return filepath.Join(config.Dir(), "workers")
+}
+
+// PIDPath returns the PID file of a worker
+func PIDPath(workerID string) string {
+	return filepath.Join(WorkersDir(), workerID+".pid")
+}
+
 // saveWorkerPID saves the worker's process ID to a file
 func (p *Pool) saveWorkerPID(workerID string) error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return err
-	}
-
-	workerDir := filepath.Join(homeDir, ".queuectl", "workers")
-	if err := os.MkdirAll(workerDir, 0755); err != nil {
+	if err := os.MkdirAll(WorkersDir(), 0755); err != nil {
 		return err
 	}
 
-	pidFile := filepath.Join(workerDir, fmt.Sprintf("%s.pid", workerID))
 	pid := strconv.Itoa(os.Getpid())
-
-	return os.WriteFile(pidFile, []byte(pid), 0644)
+	return os.WriteFile(PIDPath(workerID), []byte(pid), 0644)
 }
 
 // removeWorkerPID removes the worker's PID file
 func (p *Pool) removeWorkerPID(workerID string) error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return err
-	}
-
-	pidFile := filepath.Join(homeDir, ".queuectl", "workers", fmt.Sprintf("%s.pid", workerID))
-	return os.Remove(pidFile)
+	return os.Remove(PIDPath(workerID))
 }
 
 // CleanupOrphanedPIDs removes PID files for workers that are no longer running
 func CleanupOrphanedPIDs() error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return err
-	}
-
-	workerDir := filepath.Join(homeDir, ".queuectl", "workers")
+	workerDir := WorkersDir()
 	entries, err := os.ReadDir(workerDir)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -156,7 +489,7 @@ func CleanupOrphanedPIDs() error {
 	}
 
 	for _, entry := range entries {
-		if entry.IsDir() {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".pid") {
 			continue
 		}
 
@@ -167,17 +500,19 @@ func CleanupOrphanedPIDs() error {
 		}
 
 		pid := string(pidData)
-		if !isProcessRunning(pid) {
+		if !ProcessRunning(pid) {
 			os.Remove(pidFile)
+			removeHeartbeat(strings.TrimSuffix(entry.Name(), ".pid"))
 		}
 	}
 
 	return nil
 }
 
-// isProcessRunning checks if a process with given PID is running
-func isProcessRunning(pid string) bool {
-	pidInt, err := strconv.Atoi(pid)
+// ProcessRunning reports whether a process with the given PID, as read
+// from a worker PID file, is running
+func ProcessRunning(pid string) bool {
+	pidInt, err := strconv.Atoi(strings.TrimSpace(pid))
 	if err != nil {
 		return false
 	}
@@ -187,7 +522,8 @@ func isProcessRunning(pid string) bool {
 		return false
 	}
 
-	// Send signal 0 to check if process exists
+	// On Unix, FindProcess always succeeds, so we need to send signal 0
+	// to check if process actually exists
 	err = process.Signal(syscall.Signal(0))
 	return err == nil
 }
\ No newline at end of file
@@ -0,0 +1,87 @@
+package worker
+
+import (
+	"database/sql"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/config"
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/MithileshwaranS/queuectl/internal/storage"
+)
+
+func TestProcessRunningCurrentProcess(t *testing.T) {
+	if !ProcessRunning(strconv.Itoa(os.Getpid())) {
+		t.Error("ProcessRunning(current PID) = false, want true")
+	}
+	// PID files may end with a newline
+	if !ProcessRunning(strconv.Itoa(os.Getpid()) + "\n") {
+		t.Error("ProcessRunning(current PID with newline) = false, want true")
+	}
+}
+
+func TestProcessRunningDeadProcess(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "exit 0")
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	// Run has waited for the process, so its PID is no longer in use
+	if ProcessRunning(strconv.Itoa(cmd.Process.Pid)) {
+		t.Errorf("ProcessRunning(%d) = true for an exited process", cmd.Process.Pid)
+	}
+}
+
+func TestProcessRunningInvalidPID(t *testing.T) {
+	for _, pid := range []string{"", "abc", "-1"} {
+		if ProcessRunning(pid) {
+			t.Errorf("ProcessRunning(%q) = true, want false", pid)
+		}
+	}
+}
+
+// downStorage fails every claim with a connection error
+type downStorage struct {
+	storage.Storage
+}
+
+func (downStorage) GetNextPendingJobs(string, storage.ClaimFilter, int) ([]*job.Job, error) {
+	return nil, sql.ErrConnDone
+}
+
+func TestPoolReconnectReplacesStorageForAll(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	fresh := storage.NewMemoryStorage()
+	j := enqueueTestJob(t, fresh, "true")
+
+	cfg := config.DefaultConfig()
+	cfg.PollIntervalMS = 10
+	p := NewPool(downStorage{storage.NewMemoryStorage()}, cfg, 2)
+	p.SetLogOutput(io.Discard)
+	opens := 0
+	p.SetReconnect(func() (storage.Storage, error) {
+		opens++
+		return fresh, nil
+	})
+
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	waitForState(t, fresh, j.ID, job.StateCompleted, 5*time.Second)
+	// Background tasks read the storage through the pool
+	got := p.storage.get()
+	p.Stop()
+
+	if got != storage.Storage(fresh) {
+		t.Error("pool storage was not replaced by the reconnect")
+	}
+	if opens != 1 {
+		t.Errorf("opened %d connections, want 1 shared by both workers", opens)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package worker
+
+import (
+	"os/exec"
+	"time"
+)
+
+// setProcessGroup is not available on this platform; stopping a command
+// only kills the shell, without a grace period
+func setProcessGroup(cmd *exec.Cmd, grace time.Duration) {}
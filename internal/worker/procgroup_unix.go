@@ -0,0 +1,33 @@
+//go:build unix
+
+package worker
+
+import (
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// setProcessGroup runs the command in a process group of its own and makes
+// stopping it kill the whole group, so processes the shell started cannot
+// keep it running past a timeout, cancel or shutdown. It also keeps a
+// Ctrl+C meant for the worker away from the jobs it is finishing.
+//
+// With a positive grace the group is sent SIGTERM first and only killed
+// with SIGKILL if it is still running grace later.
+func setProcessGroup(cmd *exec.Cmd, grace time.Duration) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		pgid := -cmd.Process.Pid
+		if grace <= 0 {
+			return syscall.Kill(pgid, syscall.SIGKILL)
+		}
+		// The group outlives the shell if a child ignores SIGTERM, so
+		// the kill is sent even once the shell has exited; it fails
+		// harmlessly if the whole group is gone
+		time.AfterFunc(grace, func() {
+			syscall.Kill(pgid, syscall.SIGKILL)
+		})
+		return syscall.Kill(pgid, syscall.SIGTERM)
+	}
+}
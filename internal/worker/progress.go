@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// ProgressPrefix starts the stderr lines a job's command writes to report
+// how far along it is, e.g. "QUEUECTL_PROGRESS=45". Reporting is optional;
+// the lines are stored with the rest of the output.
+const ProgressPrefix = "QUEUECTL_PROGRESS="
+
+// maxProgressLine bounds the partial line progressWriter holds. Longer
+// lines cannot be progress reports, so they are skipped.
+const maxProgressLine = 256
+
+// progressWriter scans a command's stderr for progress lines and passes
+// each new percentage to report
+type progressWriter struct {
+	report func(progress int)
+	line   []byte
+	skip   bool // the current line grew past maxProgressLine
+	last   int
+}
+
+// Write scans p for complete lines, keeping a trailing partial one for
+// the next call
+func (pw *progressWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			pw.buffer(p)
+			break
+		}
+		pw.buffer(p[:i])
+		if !pw.skip {
+			pw.parse(string(pw.line))
+		}
+		pw.line = pw.line[:0]
+		pw.skip = false
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// buffer appends part of a line, giving up on lines that are too long
+func (pw *progressWriter) buffer(p []byte) {
+	if pw.skip {
+		return
+	}
+	if len(pw.line)+len(p) > maxProgressLine {
+		pw.line = pw.line[:0]
+		pw.skip = true
+		return
+	}
+	pw.line = append(pw.line, p...)
+}
+
+// parse reports the percentage of a progress line if it changed. Values
+// outside 0-100 are clamped; anything else is ignored.
+func (pw *progressWriter) parse(line string) {
+	value, ok := strings.CutPrefix(strings.TrimSpace(line), ProgressPrefix)
+	if !ok {
+		return
+	}
+	progress, err := strconv.Atoi(value)
+	if err != nil {
+		return
+	}
+	progress = min(max(progress, 0), 100)
+	if progress != pw.last {
+		pw.last = progress
+		pw.report(progress)
+	}
+}
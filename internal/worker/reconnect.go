@@ -0,0 +1,123 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/storage"
+)
+
+const (
+	// reconnectAfter is how many connection errors in a row trigger a
+	// reconnect
+	reconnectAfter = 3
+	// maxReconnectDelay caps the backoff between reconnect attempts
+	maxReconnectDelay = 30 * time.Second
+)
+
+// sharedStorage is the storage a pool, its workers and its background
+// tasks use. A reconnect replaces it for all of them at once.
+type sharedStorage struct {
+	mu      sync.Mutex
+	current storage.Storage
+	// own is set once a reconnect has replaced the storage the pool was
+	// given with a connection of its own, which must be closed
+	own bool
+	// open opens a fresh connection after connection errors (nil
+	// disables reconnecting)
+	open func() (storage.Storage, error)
+	// reconnecting serializes reconnects, so workers that fail together
+	// open one connection between them
+	reconnecting sync.Mutex
+}
+
+// get returns the storage currently in use
+func (s *sharedStorage) get() storage.Storage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current
+}
+
+// canReconnect reports whether a reconnect function is set
+func (s *sharedStorage) canReconnect() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.open != nil
+}
+
+// setReconnect sets the function reconnect opens connections with
+func (s *sharedStorage) setReconnect(open func() (storage.Storage, error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.open = open
+}
+
+// reconnect replaces failed, the storage that returned connection errors,
+// re-opening the database with exponential backoff until a connection
+// works or ctx is done. It reports whether the storage in use is now a
+// working one; if another caller already replaced failed, it returns at
+// once.
+func (s *sharedStorage) reconnect(ctx context.Context, failed storage.Storage, log *slog.Logger) bool {
+	s.reconnecting.Lock()
+	defer s.reconnecting.Unlock()
+	if s.get() != failed {
+		return true
+	}
+
+	delay := time.Second
+	for attempt := 1; ; attempt++ {
+		store, err := s.openChecked()
+		if err == nil {
+			s.mu.Lock()
+			if s.own {
+				s.current.Close()
+			}
+			s.current = store
+			s.own = true
+			s.mu.Unlock()
+			log.Info("Reconnected to database", "attempts", attempt)
+			return true
+		}
+
+		log.Warn("Reconnect attempt failed", "attempt", attempt, "retry_in", delay, "error", err)
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// openChecked opens a new connection and checks that it can read jobs
+func (s *sharedStorage) openChecked() (storage.Storage, error) {
+	s.mu.Lock()
+	open := s.open
+	s.mu.Unlock()
+
+	store, err := open()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := store.GetJobStats(); err != nil {
+		store.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// close closes the storage if a reconnect opened it. The storage the
+// pool was given belongs to its caller.
+func (s *sharedStorage) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.own {
+		s.current.Close()
+		s.own = false
+	}
+}
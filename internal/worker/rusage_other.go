@@ -0,0 +1,10 @@
+//go:build !unix
+
+package worker
+
+import "os"
+
+// maxRSSKB is not available on this platform
+func maxRSSKB(state *os.ProcessState) int64 {
+	return 0
+}
@@ -0,0 +1,23 @@
+//go:build unix
+
+package worker
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+)
+
+// maxRSSKB returns the peak resident memory of the finished process and
+// the children it waited for, in kilobytes
+func maxRSSKB(state *os.ProcessState) int64 {
+	ru, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok {
+		return 0
+	}
+	// Darwin reports ru_maxrss in bytes, other systems in kilobytes
+	if runtime.GOOS == "darwin" {
+		return int64(ru.Maxrss) / 1024
+	}
+	return int64(ru.Maxrss)
+}
@@ -1,175 +1,686 @@
 package worker
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os/exec"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/MithileshwaranS/queuectl/internal/audit"
 	"github.com/MithileshwaranS/queuectl/internal/config"
 	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/MithileshwaranS/queuectl/internal/metrics"
+	"github.com/MithileshwaranS/queuectl/internal/notify"
 	"github.com/MithileshwaranS/queuectl/internal/retry"
 	"github.com/MithileshwaranS/queuectl/internal/storage"
 	"github.com/google/uuid"
+	"golang.org/x/time/rate"
 )
 
 // Worker represents a background worker that processes jobs
 type Worker struct {
-	ID      string
-	storage storage.Storage
+	ID string
+	// storage is shared with the worker's pool, which replaces it on a
+	// reconnect
+	storage *sharedStorage
 	config  *config.Config
 	ctx     context.Context
 	cancel  context.CancelFunc
 	wg      sync.WaitGroup
-	logger  *log.Logger
+	// log carries the worker's ID on every record
+	log *slog.Logger
+
+	// jobCtx parents the commands of running jobs; abort kills them when
+	// a shutdown takes longer than shutdownTimeout
+	jobCtx context.Context
+	abort  context.CancelFunc
+	// shutdownTimeout bounds how long Stop waits for running jobs (0
+	// waits until they finish)
+	shutdownTimeout time.Duration
+
+	// consecutiveErrors counts job fetches that failed in a row
+	consecutiveErrors int
+	// onFatal is called once if the worker stops itself on a fatal error
+	onFatal func(w *Worker, err error)
+	// notifier is told about terminal job transitions (nil disables)
+	notifier notify.Notifier
+	// auditor records every job start (nil disables)
+	auditor *audit.Sink
+	// metrics records finished attempts for export (nil disables)
+	metrics *metrics.OTLPExporter
+	// prom counts finished attempts for Prometheus (nil disables)
+	prom *metrics.PromRecorder
+	// noJobLogs skips streaming job output to the job log files
+	noJobLogs bool
+	// filter restricts which jobs the worker claims
+	filter storage.ClaimFilter
+	// concurrency is how many jobs the worker runs at once (0 means 1)
+	concurrency int
+	// limiter paces job starts across the workers sharing it (nil is
+	// unlimited)
+	limiter *rate.Limiter
+	// running holds the IDs of the jobs being executed, for heartbeats
+	running   map[string]bool
+	runningMu sync.Mutex
 }
 
+const (
+	// auditRetryDelay is how long a job whose start could not be audited
+	// is held before a worker tries it again
+	auditRetryDelay = 30 * time.Second
+)
+
 // NewWorker creates a new worker instance
-func NewWorker(store storage.Storage, cfg *config.Config, logger *log.Logger) *Worker {
+func NewWorker(store storage.Storage, cfg *config.Config, logger *slog.Logger) *Worker {
 	ctx, cancel := context.WithCancel(context.Background())
-	
+	jobCtx, abort := context.WithCancel(context.Background())
+	id := uuid.New().String()[:8] // Short ID for display
+
 	return &Worker{
-		ID:      uuid.New().String()[:8], // Short ID for display
-		storage: store,
+		ID:      id,
+		storage: &sharedStorage{current: store},
 		config:  cfg,
 		ctx:     ctx,
 		cancel:  cancel,
-		logger:  logger,
+		log:     logger.With("worker_id", id),
+		jobCtx:  jobCtx,
+		abort:   abort,
 	}
 }
 
+// jobAttrs returns the log attributes of a job that has just finished an
+// attempt taking duration
+func jobAttrs(j *job.Job, duration time.Duration) []any {
+	return []any{"job_id", j.ID, "state", j.State, "duration_ms", duration.Milliseconds()}
+}
+
+// usageAttrs returns the log attributes of the resources a finished
+// attempt used
+func usageAttrs(j *job.Job) []any {
+	return []any{"cpu_ms", j.CPUTimeMS, "max_rss_kb", j.MaxRSSKB}
+}
+
 // Start begins processing jobs
 func (w *Worker) Start() {
 	w.wg.Add(1)
 	go w.run()
 }
 
-// Stop gracefully stops the worker
+// Stop gracefully stops the worker, waiting for every running job to
+// finish. Jobs still running after the shutdown timeout are killed and
+// returned to pending.
 func (w *Worker) Stop() {
-	w.logger.Printf("[Worker %s] Stopping gracefully...", w.ID)
+	w.log.Info("Stopping gracefully...")
+	w.cancel()
+	if !w.wait(w.shutdownTimeout) {
+		w.log.Warn("Jobs still running after the shutdown timeout, stopping them", "shutdown_timeout", w.shutdownTimeout)
+		w.abort()
+		w.wg.Wait()
+	}
+	w.abort()
+	w.log.Info("Stopped")
+}
+
+// wait waits up to timeout for the worker's loop and running jobs to
+// end, or indefinitely if timeout is 0. It reports whether they ended.
+func (w *Worker) wait(timeout time.Duration) bool {
+	if timeout <= 0 {
+		w.wg.Wait()
+		return true
+	}
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// fail stops the worker's loop because of an unrecoverable error
+func (w *Worker) fail(err error) {
+	w.log.Error("FATAL", "error", err)
 	w.cancel()
-	w.wg.Wait()
-	w.logger.Printf("[Worker %s] Stopped", w.ID)
+	if w.onFatal != nil {
+		w.onFatal(w, err)
+	}
 }
 
-// run is the main worker loop
+// run is the main worker loop. Each running job holds one of the
+// worker's slots; the loop claims jobs until every slot is taken.
 func (w *Worker) run() {
 	defer w.wg.Done()
-	
-	w.logger.Printf("[Worker %s] Started", w.ID)
-	
-	ticker := time.NewTicker(1 * time.Second)
+
+	w.log.Info("Started")
+
+	ticker := time.NewTicker(w.pollInterval())
 	defer ticker.Stop()
 
+	slots := make(chan struct{}, w.slots())
 	for {
 		select {
 		case <-w.ctx.Done():
 			return
 		case <-ticker.C:
-			w.processNext()
+			w.fill(slots)
 		}
 	}
 }
 
-// processNext fetches and processes the next available job
-func (w *Worker) processNext() {
-	// Get next pending job (with locking)
-	j, err := w.storage.GetNextPendingJob(w.ID)
-	if err != nil {
-		w.logger.Printf("[Worker %s] Error fetching job: %v", w.ID, err)
-		return
+// slots returns how many jobs the worker runs at once
+func (w *Worker) slots() int {
+	if w.concurrency < 1 {
+		return 1
 	}
+	return w.concurrency
+}
 
-	if j == nil {
-		// No jobs available
-		return
+// fill claims jobs while a slot is free and runs each in its own
+// goroutine, which releases the slot when the job is done. Free slots are
+// claimed for in one batch. Only run sends to slots, so a free slot cannot
+// be taken between the check and the send.
+func (w *Worker) fill(slots chan struct{}) {
+	for len(slots) < cap(slots) && w.ctx.Err() == nil {
+		n := cap(slots) - len(slots)
+
+		// Wait for a token before claiming, so a claimed job never sits
+		// in processing waiting for its turn. Tokens come one at a time.
+		if w.limiter != nil {
+			if w.limiter.Wait(w.ctx) != nil {
+				return
+			}
+			n = 1
+		}
+
+		jobs := w.claimNext(n)
+		for _, j := range jobs {
+			slots <- struct{}{}
+			w.setRunning(j.ID, true)
+			w.wg.Add(1)
+			go func() {
+				defer w.wg.Done()
+				defer func() { <-slots }()
+				defer w.setRunning(j.ID, false)
+				w.executeJob(j)
+			}()
+		}
+		if len(jobs) < n {
+			return
+		}
+	}
+}
+
+// setRunning records whether the job with the given ID is being executed
+func (w *Worker) setRunning(jobID string, running bool) {
+	w.runningMu.Lock()
+	defer w.runningMu.Unlock()
+	if running {
+		if w.running == nil {
+			w.running = make(map[string]bool)
+		}
+		w.running[jobID] = true
+	} else {
+		delete(w.running, jobID)
+	}
+}
+
+// runningJobs returns the sorted IDs of the jobs being executed
+func (w *Worker) runningJobs() []string {
+	w.runningMu.Lock()
+	defer w.runningMu.Unlock()
+	ids := make([]string, 0, len(w.running))
+	for id := range w.running {
+		ids = append(ids, id)
 	}
+	sort.Strings(ids)
+	return ids
+}
+
+// store returns the storage the worker currently uses
+func (w *Worker) store() storage.Storage {
+	return w.storage.get()
+}
 
-	w.logger.Printf("[Worker %s] Processing job %s: %s", w.ID, j.ID, j.Command)
-	
-	// Execute the job
-	w.executeJob(j)
+// pollInterval returns how often the worker looks for new jobs and for
+// cancellation of the job it is running
+func (w *Worker) pollInterval() time.Duration {
+	interval := time.Duration(w.config.PollIntervalMS) * time.Millisecond
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return interval
+}
+
+// claimNext claims up to n available jobs. It returns none if there are
+// none or the claim failed.
+func (w *Worker) claimNext(n int) []*job.Job {
+	// Get the next pending jobs (with locking)
+	store := w.store()
+	jobs, err := store.GetNextPendingJobs(w.ID, w.filter, n)
+	if err != nil {
+		w.log.Error("Error fetching job", "error", err)
+		w.consecutiveErrors++
+		// Connection errors never count towards max-consecutive-errors;
+		// the worker keeps reconnecting until the database is back
+		if w.storage.canReconnect() && storage.IsConnectionError(err) {
+			if w.consecutiveErrors >= reconnectAfter {
+				w.log.Warn("Database unavailable, reconnecting", "errors", w.consecutiveErrors)
+				if w.storage.reconnect(w.ctx, store, w.log) {
+					w.consecutiveErrors = 0
+				}
+			}
+			return nil
+		}
+		if limit := w.config.MaxConsecutiveErrors; limit > 0 && w.consecutiveErrors >= limit {
+			w.fail(fmt.Errorf("giving up after %d consecutive errors fetching jobs: %w", w.consecutiveErrors, err))
+		}
+		return nil
+	}
+	w.consecutiveErrors = 0
+
+	for _, j := range jobs {
+		w.log.Info("Processing job", "job_id", j.ID, "command", j.CommandForAttempt(),
+			"state", job.StateProcessing, "queue", j.Queue, "attempt", j.Attempts+1)
+	}
+	return jobs
 }
 
 // executeJob executes a single job and handles its result
 func (w *Worker) executeJob(j *job.Job) {
 	// Mark as processing
 	j.MarkAsProcessing(w.ID)
-	if err := w.storage.SaveJob(j); err != nil {
-		w.logger.Printf("[Worker %s] Error saving job state: %v", w.ID, err)
+	if err := w.store().SaveJobIfOwner(j, w.ID); err != nil {
+		w.log.Error("Error saving job state", "job_id", j.ID, "error", err)
+		return
+	}
+
+	// Record the start before anything runs so the audit trail is complete
+	if err := w.audit(j); err != nil {
+		w.handleAuditFailure(j, err)
 		return
 	}
 
 	// Execute command with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	timeout := JobTimeout(j, w.config)
+	ctx, cancel := context.WithTimeout(w.jobCtx, timeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "sh", "-c", j.Command)
-	
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	cmd, cleanup, err := JobCommand(ctx, j, j.CommandForAttempt())
+	if err != nil {
+		j.ExitCode = -1
+		w.handleFailure(j, err, job.ErrorTypeStart, "", 0)
+		return
+	}
+	setProcessGroup(cmd, time.Duration(w.config.KillGraceSeconds)*time.Second)
+	defer func() {
+		if err := cleanup(); err != nil {
+			w.log.Warn("Failed to remove sandbox", "job_id", j.ID, "dir", cmd.Dir, "error", err)
+		}
+	}()
+
+	// Only the tail of each stream is held, so a chatty command cannot
+	// grow the worker's memory or the stored output without bound
+	stdout := &tailBuffer{max: w.config.MaxOutputBytes}
+	stderr := &tailBuffer{max: w.config.MaxOutputBytes}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	// Stream both streams to the job's log file for 'queuectl logs'
+	if !w.noJobLogs {
+		if logFile, err := createJobLog(j.ID); err != nil {
+			w.log.Warn("Failed to create job log file", "job_id", j.ID, "error", err)
+		} else {
+			defer logFile.Close()
+			cmd.Stdout = io.MultiWriter(stdout, logFile)
+			cmd.Stderr = io.MultiWriter(stderr, logFile)
+		}
+	}
+
+	// Record the progress the command reports on stderr. Run waits for
+	// the writers, so j is not touched once it returns.
+	cmd.Stderr = io.MultiWriter(cmd.Stderr, &progressWriter{report: func(progress int) {
+		j.Progress = progress
+		if err := w.store().SetProgress(j.ID, progress); err != nil {
+			w.log.Error("Error recording job progress", "job_id", j.ID, "error", err)
+		}
+	}})
 
+	stopWatch := w.watchJob(j.ID, cancel)
 	startTime := time.Now()
-	err := cmd.Run()
+	err = cmd.Run()
 	duration := time.Since(startTime)
+	cancelled := stopWatch()
+	RecordUsage(j, cmd)
+	j.DurationMS = duration.Milliseconds()
+	j.ExitCode = exitCode(err)
 
 	output := stdout.String()
-	if stderr.Len() > 0 {
+	if stderr.total > 0 {
 		output += "\nSTDERR:\n" + stderr.String()
 	}
 
+	if cancelled {
+		w.handleCancel(j, capOutput(output, w.config.OutputTailLines, w.config.OutputKeep), duration)
+		return
+	}
+	if w.jobCtx.Err() != nil {
+		w.handleShutdown(j, duration)
+		return
+	}
+
+	var errType job.ErrorType
 	if err != nil {
-		w.handleFailure(j, err, output, duration)
+		errType = classifyError(ctx, err)
+		if errType == job.ErrorTypeTimeout {
+			// The exec error would only say the process was killed
+			err = fmt.Errorf("timed out after %ds", int(timeout.Seconds()))
+		}
+	} else if err = j.CheckOutput(output); err != nil {
+		// Exited 0 but the output says otherwise
+		errType = job.ErrorTypeOutput
+	}
+
+	// Patterns see all the output held; only the capped version is stored
+	output = capOutput(output, w.config.OutputTailLines, w.config.OutputKeep)
+
+	if err != nil {
+		w.handleFailure(j, err, errType, output, duration)
 	} else {
 		w.handleSuccess(j, output, duration)
 	}
 }
 
+// watchJob runs alongside a job's command. It polls for a cancel request
+// and calls stop when one arrives, and sends heartbeats so the pool does
+// not mistake a long-running job for one whose worker died. The returned
+// function ends the watch and reports whether the job was cancelled.
+func (w *Worker) watchJob(jobID string, stop context.CancelFunc) func() bool {
+	done := make(chan struct{})
+	result := make(chan bool, 1)
+
+	go func() {
+		ticker := time.NewTicker(w.pollInterval())
+		defer ticker.Stop()
+
+		heartbeat := w.heartbeatInterval()
+		lastBeat := time.Now()
+
+		for {
+			select {
+			case <-done:
+				result <- false
+				return
+			case <-ticker.C:
+				if heartbeat > 0 && time.Since(lastBeat) >= heartbeat {
+					if err := w.store().Heartbeat(jobID); err != nil {
+						w.log.Error("Error sending job heartbeat", "job_id", jobID, "error", err)
+					} else {
+						lastBeat = time.Now()
+					}
+				}
+
+				requested, err := w.store().IsCancelRequested(jobID)
+				if err != nil {
+					w.log.Error("Error checking job cancellation", "job_id", jobID, "error", err)
+					continue
+				}
+				if requested {
+					stop()
+					result <- true
+					return
+				}
+			}
+		}
+	}()
+
+	return func() bool {
+		close(done)
+		return <-result
+	}
+}
+
+// heartbeatInterval returns how often a running job's heartbeat is sent:
+// a third of stale-job-threshold, so a couple of missed beats are
+// tolerated, capped at a minute. It is 0 when stale job recovery is off.
+func (w *Worker) heartbeatInterval() time.Duration {
+	interval := w.config.StaleJobThreshold / 3
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+	return interval
+}
+
+// handleCancel marks a job stopped by 'queuectl cancel' as cancelled
+func (w *Worker) handleCancel(j *job.Job, output string, duration time.Duration) {
+	j.RecordAttempt(time.Now().Add(-duration), "cancelled")
+	j.Output = output
+	j.MarkAsCancelled()
+	w.log.Info("Job cancelled", jobAttrs(j, duration)...)
+
+	w.saveResult(j, "Error saving cancelled job")
+}
+
+// handleShutdown returns a job killed by a shutdown timeout to pending, so
+// the interrupted attempt does not count and another worker reruns it
+func (w *Worker) handleShutdown(j *job.Job, duration time.Duration) {
+	j.Requeue(fmt.Sprintf("requeued: worker %s shut down before the job finished", w.ID))
+	w.log.Warn("Job interrupted by shutdown, returning it to pending", jobAttrs(j, duration)...)
+
+	w.saveResult(j, "Error saving interrupted job")
+}
+
+// handleAuditFailure holds back a job whose start could not be audited
+// for auditRetryDelay. Nothing ran, so the attempt is not counted.
+func (w *Worker) handleAuditFailure(j *job.Job, err error) {
+	until := time.Now().Add(auditRetryDelay)
+	j.Hold(until)
+	j.WorkerID = ""
+	j.Error = err.Error()
+	j.ErrorType = job.ErrorTypeAudit
+	w.log.Warn("Job start not audited, holding it", "job_id", j.ID, "held_until", until, "error", err)
+
+	w.saveResult(j, "Error saving held job")
+}
+
 // handleSuccess marks job as completed
 func (w *Worker) handleSuccess(j *job.Job, output string, duration time.Duration) {
-	w.logger.Printf("[Worker %s] Job %s completed successfully (%.2fs)", w.ID, j.ID, duration.Seconds())
-	
+	j.RecordAttempt(time.Now().Add(-duration), "")
+
 	j.MarkAsCompleted(output)
-	
-	if err := w.storage.SaveJob(j); err != nil {
-		w.logger.Printf("[Worker %s] Error saving completed job: %v", w.ID, err)
+	w.log.Info("Job completed", append(jobAttrs(j, duration), usageAttrs(j)...)...)
+	w.record(j, duration)
+
+	if !w.saveResult(j, "Error saving completed job") {
+		return
+	}
+
+	if w.config.NotifyOnSuccess {
+		w.notify(j, notify.EventCompleted)
 	}
+
+	w.enqueueNext(j, output)
+}
+
+// enqueueNext enqueues the job's next_job, if any, now that it succeeded
+func (w *Worker) enqueueNext(j *job.Job, output string) {
+	next := j.SpawnNext(output)
+	if next == nil {
+		return
+	}
+
+	// Its queue defaults were applied when the parent was enqueued, so
+	// the spec is used as stored. It is inserted rather than saved, so a
+	// job already stored under an explicit next_job id is not replaced.
+	err := w.store().InsertJob(next)
+	if errors.Is(err, storage.ErrJobExists) {
+		w.log.Warn("Next job already exists, not enqueued again", "job_id", j.ID, "next_job_id", next.ID)
+		return
+	}
+	if err != nil {
+		w.log.Error("Error enqueuing next job", "job_id", j.ID, "error", err)
+		return
+	}
+	w.log.Info("Enqueued next job", "job_id", j.ID, "next_job_id", next.ID, "command", next.Command)
 }
 
 // handleFailure handles job failure with retry logic
-func (w *Worker) handleFailure(j *job.Job, execErr error, output string, duration time.Duration) {
+func (w *Worker) handleFailure(j *job.Job, execErr error, errType job.ErrorType, output string, duration time.Duration) {
+	j.ErrorType = errType
+
 	errMsg := fmt.Sprintf("%v", execErr)
 	if output != "" {
 		errMsg = fmt.Sprintf("%v\nOutput: %s", execErr, output)
 	}
 
-	w.logger.Printf("[Worker %s] Job %s failed (%.2fs): %v", w.ID, j.ID, duration.Seconds(), execErr)
+	w.log.Warn("Job failed", append([]any{"job_id", j.ID, "duration_ms", duration.Milliseconds(),
+		"error_type", errType, "error", execErr}, usageAttrs(j)...)...)
+
+	j.RecordAttempt(time.Now().Add(-duration), execErr.Error())
+	// Keep the failed attempt's output, as completed and cancelled jobs do
+	j.Output = output
 
 	// Check if we can retry
-	if j.CanRetry() {
+	if j.CanRetryAfter(errType) {
 		// Calculate next retry time with exponential backoff
-		nextRetryAt := retry.GetNextRetryAt(j.Attempts, w.config.BackoffBase)
+		// An explicit retry_schedule takes precedence over the backoff formula
+		// and is never jittered
+		var nextRetryAt *time.Time
+		if delay, ok := j.RetryDelay(); ok {
+			t := time.Now().Add(delay)
+			nextRetryAt = &t
+		} else {
+			backoffBase := w.config.BackoffBase
+			if j.BackoffBase > 0 {
+				backoffBase = j.BackoffBase
+			}
+			nextRetryAt = retry.GetNextRetryAt(j.Attempts, backoffBase, w.config.BackoffJitter,
+				time.Duration(w.config.MaxBackoffSeconds)*time.Second)
+		}
 		j.MarkAsFailed(errMsg, nextRetryAt)
-		
+
 		delay := nextRetryAt.Sub(time.Now())
-		w.logger.Printf("[Worker %s] Job %s will retry in %s (attempt %d/%d)", 
-			w.ID, j.ID, delay.Round(time.Second), j.Attempts+1, j.MaxRetries)
+		w.log.Info("Job will retry", append(jobAttrs(j, duration),
+			"retry_in", delay.Round(time.Second), "attempt", j.Attempts+1, "max_retries", j.MaxRetries)...)
+		if j.FallbackCommand != "" {
+			w.log.Info("Job will retry with fallback command", "job_id", j.ID, "command", j.FallbackCommand)
+		}
 	} else {
 		// Move to Dead Letter Queue
 		j.MarkAsDead(errMsg)
-		w.logger.Printf("[Worker %s] Job %s moved to DLQ after %d attempts", w.ID, j.ID, j.Attempts)
+		reason := "retries exhausted"
+		if j.CanRetry() {
+			reason = fmt.Sprintf("%s failure is not retried (retry_on_timeout_only)", errType)
+		} else if j.MaxRetries == 0 {
+			reason = "max_retries is 0"
+		}
+		w.log.Warn("Job moved to DLQ", append(jobAttrs(j, duration), "attempts", j.Attempts, "reason", reason)...)
+	}
+
+	w.record(j, duration)
+
+	if !w.saveResult(j, "Error saving failed job") {
+		return
 	}
 
-	if err := w.storage.SaveJob(j); err != nil {
-		w.logger.Printf("[Worker %s] Error saving failed job: %v", w.ID, err)
+	if j.State == job.StateDead {
+		w.notify(j, notify.EventDead)
 	}
 }
 
+// saveResult saves the outcome of the worker's attempt at j, logging a
+// failure with errMsg. A job deleted or requeued while it ran is no longer
+// the worker's, so its outcome is dropped rather than bringing the job
+// back; saveResult then reports false.
+func (w *Worker) saveResult(j *job.Job, errMsg string) bool {
+	err := w.store().SaveJobIfOwner(j, w.ID)
+	if errors.Is(err, storage.ErrNotClaimed) {
+		w.log.Warn("Job no longer claimed by this worker, dropping its result", "job_id", j.ID, "state", j.State)
+		return false
+	}
+	if err != nil {
+		w.log.Error(errMsg, "job_id", j.ID, "error", err)
+	}
+	return true
+}
+
+// record adds the finished attempt to the metrics, if enabled
+func (w *Worker) record(j *job.Job, duration time.Duration) {
+	if w.metrics != nil {
+		w.metrics.JobFinished(j.State, duration)
+	}
+	if w.prom != nil {
+		w.prom.JobFinished(j.State, duration)
+	}
+}
+
+// audit records the start of the job's attempt if an audit sink is
+// configured. Delivery failures are logged; they are returned, stopping
+// the attempt, only when audit_required is set.
+func (w *Worker) audit(j *job.Job) error {
+	if w.auditor == nil {
+		return nil
+	}
+	err := w.auditor.Send(audit.NewRecord(j, w.ID))
+	if err == nil {
+		return nil
+	}
+
+	w.log.Warn("Failed to audit job start", "job_id", j.ID, "error", err)
+	if w.config.AuditRequired {
+		return fmt.Errorf("audit required but the job start could not be recorded: %w", err)
+	}
+	return nil
+}
+
+// notify sends a notification for the job if a notifier is configured.
+// Delivery failures are logged and do not affect the job.
+func (w *Worker) notify(j *job.Job, event notify.Event) {
+	if w.notifier == nil {
+		return
+	}
+	if err := w.notifier.Notify(j, event); err != nil {
+		w.log.Warn("Failed to send notification", "job_id", j.ID, "event", event, "error", err)
+	}
+}
+
+// classifyError determines why a command execution failed
+func classifyError(ctx context.Context, err error) job.ErrorType {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return job.ErrorTypeTimeout
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return job.ErrorTypeExit
+	}
+	return job.ErrorTypeStart
+}
+
+// exitCode returns the exit status of a finished command: 0 on success,
+// the process's own code if it exited, and -1 if it could not be started
+// or was killed by a signal
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
 // GetID returns the worker ID
 func (w *Worker) GetID() string {
 	return w.ID
-}
\ No newline at end of file
+}
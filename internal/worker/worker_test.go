@@ -0,0 +1,189 @@
+package worker
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/config"
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/MithileshwaranS/queuectl/internal/storage"
+)
+
+// newTestWorker returns a worker polling store every 10ms, with its logs
+// discarded and its job logs in a temporary home directory
+func newTestWorker(t *testing.T, store storage.Storage) *Worker {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	cfg := config.DefaultConfig()
+	cfg.PollIntervalMS = 10
+	return NewWorker(store, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// enqueueTestJob saves a pending job running command
+func enqueueTestJob(t *testing.T, store storage.Storage, command string) *job.Job {
+	t.Helper()
+	j := job.NewJob(command, 3)
+	if err := store.SaveJob(j); err != nil {
+		t.Fatal(err)
+	}
+	return j
+}
+
+// waitForState polls until the job is in state, failing after timeout
+func waitForState(t *testing.T, store storage.Storage, id string, state job.State, timeout time.Duration) *job.Job {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for {
+		j, err := store.GetJob(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if j.State == state {
+			return j
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s still %s after %s, want %s", id, j.State, timeout, state)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestStopRequeuesJobAfterShutdownTimeout(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	w := newTestWorker(t, store)
+	w.shutdownTimeout = 100 * time.Millisecond
+	j := enqueueTestJob(t, store, "sleep 30")
+
+	w.Start()
+	waitForState(t, store, j.ID, job.StateProcessing, 5*time.Second)
+
+	start := time.Now()
+	w.Stop()
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Stop took %s, want about the shutdown timeout", elapsed)
+	}
+
+	got, err := store.GetJob(j.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.State != job.StatePending {
+		t.Errorf("state = %s, want pending", got.State)
+	}
+	if got.Attempts != 0 {
+		t.Errorf("attempts = %d, want 0: an interrupted attempt does not count", got.Attempts)
+	}
+	if got.WorkerID != "" {
+		t.Errorf("worker_id = %q, want none", got.WorkerID)
+	}
+	if !strings.Contains(got.Error, "shut down") {
+		t.Errorf("error = %q, want the shutdown reason", got.Error)
+	}
+}
+
+func TestStopWaitsForJobWithinShutdownTimeout(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	w := newTestWorker(t, store)
+	w.shutdownTimeout = 10 * time.Second
+	j := enqueueTestJob(t, store, "sleep 0.3; echo done")
+
+	w.Start()
+	waitForState(t, store, j.ID, job.StateProcessing, 5*time.Second)
+	w.Stop()
+
+	got, err := store.GetJob(j.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.State != job.StateCompleted {
+		t.Fatalf("state = %s, want completed", got.State)
+	}
+	if strings.TrimSpace(got.Output) != "done" {
+		t.Errorf("output = %q, want done", got.Output)
+	}
+}
+
+func TestStopClaimsNothingMore(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	w := newTestWorker(t, store)
+	w.Start()
+	w.Stop()
+
+	j := enqueueTestJob(t, store, "true")
+	time.Sleep(50 * time.Millisecond)
+	got, err := store.GetJob(j.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.State != job.StatePending {
+		t.Errorf("state = %s, want pending after the worker stopped", got.State)
+	}
+}
+
+func TestFailedJobKeepsOutput(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	w := newTestWorker(t, store)
+	j := job.NewJob("echo oops; exit 3", 0)
+	if err := store.SaveJob(j); err != nil {
+		t.Fatal(err)
+	}
+
+	w.Start()
+	got := waitForState(t, store, j.ID, job.StateDead, 5*time.Second)
+	w.Stop()
+
+	if strings.TrimSpace(got.Output) != "oops" {
+		t.Errorf("output = %q, want the failed attempt's output", got.Output)
+	}
+}
+
+func TestDeletedJobIsNotSavedWhenItFinishes(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	w := newTestWorker(t, store)
+	w.shutdownTimeout = 10 * time.Second
+	j := enqueueTestJob(t, store, "sleep 0.3; echo done")
+
+	w.Start()
+	waitForState(t, store, j.ID, job.StateProcessing, 5*time.Second)
+	// As delete --force does once the worker has not stopped in time
+	if err := store.DeleteJobIfState(j.ID, job.StateProcessing); err != nil {
+		t.Fatal(err)
+	}
+	// Stop waits for the job to finish
+	w.Stop()
+
+	if got, err := store.GetJob(j.ID); err == nil {
+		t.Errorf("deleted job saved again as %s", got.State)
+	}
+}
+
+func TestRequeuedJobIsNotSavedWhenItFinishes(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	w := newTestWorker(t, store)
+	w.shutdownTimeout = 10 * time.Second
+	j := enqueueTestJob(t, store, "sleep 0.3; echo done")
+
+	w.Start()
+	waitForState(t, store, j.ID, job.StateProcessing, 5*time.Second)
+	// End the claim loop so the requeued job stays pending; the running
+	// job carries on
+	w.cancel()
+	if recovered, err := store.RecoverStaleJobs(0); err != nil || len(recovered) != 1 {
+		t.Fatalf("requeue = %v, %v", recovered, err)
+	}
+	w.Stop()
+
+	got, err := store.GetJob(j.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.State != job.StatePending || got.WorkerID != "" {
+		t.Errorf("job = %s on %q, want pending with no worker", got.State, got.WorkerID)
+	}
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/spf13/cobra"
+)
+
+// attachPollInterval is how often enqueue --attach checks on its job
+const attachPollInterval = 500 * time.Millisecond
+
+// attachTimeoutCode is the exit code of enqueue --attach when --timeout
+// runs out, as with timeout(1)
+const attachTimeoutCode = 124
+
+// ExitError asks main to exit with Code, after printing Msg if it is set,
+// rather than with the usual 1
+type ExitError struct {
+	Code int
+	Msg  string
+}
+
+func (e *ExitError) Error() string {
+	return e.Msg
+}
+
+// attachJob waits until the job is completed, dead or cancelled, prints
+// its output and returns an *ExitError carrying its exit code, or nil if
+// it exited 0. A timeout of 0 waits forever.
+func attachJob(cmd *cobra.Command, id string, timeout time.Duration) error {
+	// The exit code speaks for the job; cobra should not add to it
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+
+	if len(getActiveWorkers()) == 0 {
+		fmt.Fprintf(os.Stderr, "Warning: no workers are running; job %s waits until one starts\n", id)
+	}
+
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+
+	for {
+		j, err := getStorage().GetJob(id)
+		if err != nil {
+			return &ExitError{Code: 1, Msg: fmt.Sprintf("failed to get job: %v", err)}
+		}
+		if j.IsTerminal() {
+			return finishAttach(j)
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return &ExitError{
+				Code: attachTimeoutCode,
+				Msg:  fmt.Sprintf("timed out after %s waiting for job %s (state: %s); it stays queued", timeout, id, j.State),
+			}
+		}
+		time.Sleep(attachPollInterval)
+	}
+}
+
+// finishAttach prints a finished job's output and converts its outcome to
+// an exit code
+func finishAttach(j *job.Job) error {
+	if j.Output != "" {
+		fmt.Print(j.Output)
+		if !strings.HasSuffix(j.Output, "\n") {
+			fmt.Println()
+		}
+	}
+
+	if j.State == job.StateCompleted {
+		return nil
+	}
+
+	code := j.ExitCode
+	if code <= 0 {
+		// Not started, killed or cancelled: still a failure
+		code = 1
+	}
+	msg := fmt.Sprintf("job %s is %s", j.ID, j.State)
+	// Failed attempts append their output to the error; it was printed above
+	errMsg := j.Error
+	if j.Output != "" {
+		errMsg = strings.TrimSuffix(errMsg, "\nOutput: "+j.Output)
+	}
+	if errMsg != "" {
+		msg += ": " + errMsg
+	}
+	return &ExitError{Code: code, Msg: msg}
+}
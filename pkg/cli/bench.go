@@ -0,0 +1,264 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/config"
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/MithileshwaranS/queuectl/internal/notify"
+	"github.com/MithileshwaranS/queuectl/internal/storage"
+	"github.com/MithileshwaranS/queuectl/internal/worker"
+	"github.com/spf13/cobra"
+)
+
+func benchCmd() *cobra.Command {
+	var jobs, concurrency, pollInterval int
+	var command string
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Measure queue throughput with synthetic jobs",
+		Long: `Enqueue a batch of synthetic jobs, process them with an embedded worker
+pool and report the total time, throughput and job latency.
+
+The benchmark runs against a temporary database, so it does not touch
+the real queue. Latency is measured from enqueue to completion. Jobs run
+without retries. Use it to compare worker-count and poll-interval-ms
+settings on this machine.
+
+The embedded workers run with the default configuration and a temporary
+home directory: they do not show up in 'queuectl status', write job log
+files, or send audit records, notifications or metrics. The bench gives
+up after --timeout, and Ctrl+C stops it cleanly.
+
+Examples:
+  queuectl bench                                   # 100 jobs of "true", 4 workers
+  queuectl bench --jobs 1000 --command "true" --concurrency 4
+  queuectl bench --jobs 500 --poll-interval 50
+  queuectl bench --command "sleep 1" --timeout 1m`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if jobs < 1 {
+				return fmt.Errorf("--jobs must be at least 1")
+			}
+			if concurrency < 1 {
+				return fmt.Errorf("--concurrency must be at least 1")
+			}
+
+			// Read the user's settings before the home directory is swapped
+			if pollInterval <= 0 {
+				pollInterval = getConfig().PollIntervalMS
+			}
+
+			dir, err := os.MkdirTemp("", "queuectl-bench-")
+			if err != nil {
+				return fmt.Errorf("failed to create bench directory: %w", err)
+			}
+			defer os.RemoveAll(dir)
+
+			// Worker PID, heartbeat and job log files go under the home
+			// directory, so point it at the bench directory to keep the
+			// bench workers out of 'queuectl status' and the real logs
+			restoreHome := isolateHome(dir)
+			defer restoreHome()
+
+			store, err := storage.NewSQLiteStorage(filepath.Join(dir, "bench.db"))
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+			if err := store.Initialize(); err != nil {
+				return err
+			}
+
+			// Start from the defaults rather than the user's config, so no
+			// audit, notification, metrics export, retention sweep or
+			// stats sampling runs for the synthetic jobs
+			cfg := config.DefaultConfig()
+			cfg.DBPath = filepath.Join(dir, "bench.db")
+			cfg.PollIntervalMS = pollInterval
+			cfg.NotifyOnSuccess = true
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+
+			fmt.Printf("Enqueuing %d job(s): %s\n", jobs, command)
+			rec := newBenchRecorder()
+			enqueueStart := time.Now()
+			for i := 0; i < jobs; i++ {
+				j := job.NewJob(command, 0)
+				rec.enqueued(j.ID)
+				if err := store.SaveJob(j); err != nil {
+					return fmt.Errorf("failed to enqueue job: %w", err)
+				}
+			}
+			enqueueTime := time.Since(enqueueStart)
+
+			fmt.Printf("Processing with %d worker(s), polling every %dms...\n", concurrency, cfg.PollIntervalMS)
+			pool := worker.NewPool(store, cfg, concurrency)
+			pool.SetLogOutput(io.Discard)
+			pool.SetNotifier(rec)
+			pool.SetJobLogs(false)
+			// Don't let a hung command hold up an interrupted bench
+			pool.SetShutdownTimeout(benchShutdownTimeout)
+
+			start := time.Now()
+			if err := pool.Start(); err != nil {
+				return fmt.Errorf("failed to start workers: %w", err)
+			}
+			waitErr := rec.wait(ctx, jobs)
+			total := time.Since(start)
+			pool.Stop()
+			if waitErr != nil {
+				finished, _ := rec.results()
+				if errors.Is(waitErr, context.DeadlineExceeded) {
+					return fmt.Errorf("bench timed out after %s with %d of %d job(s) finished", timeout, len(finished), jobs)
+				}
+				return fmt.Errorf("bench interrupted with %d of %d job(s) finished", len(finished), jobs)
+			}
+
+			latencies, failed := rec.results()
+
+			fmt.Println()
+			fmt.Println("=== Benchmark Results ===")
+			fmt.Println()
+			fmt.Printf("Jobs:        %d (%d failed)\n", jobs, failed)
+			fmt.Printf("Workers:     %d\n", concurrency)
+			fmt.Printf("Enqueue:     %s (%.1f jobs/s)\n", enqueueTime.Round(time.Millisecond), float64(jobs)/enqueueTime.Seconds())
+			fmt.Printf("Total time:  %s\n", total.Round(time.Millisecond))
+			fmt.Printf("Throughput:  %.1f jobs/s\n", float64(jobs)/total.Seconds())
+			fmt.Printf("Latency p50: %s\n", percentile(latencies, 50).Round(time.Millisecond))
+			fmt.Printf("Latency p95: %s\n", percentile(latencies, 95).Round(time.Millisecond))
+			fmt.Printf("Latency max: %s\n", percentile(latencies, 100).Round(time.Millisecond))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&jobs, "jobs", "n", 100, "Number of jobs to enqueue")
+	cmd.Flags().StringVar(&command, "command", "true", "Command each job runs")
+	cmd.Flags().IntVarP(&concurrency, "concurrency", "c", 4, "Number of workers")
+	cmd.Flags().IntVar(&pollInterval, "poll-interval", 0, "Worker poll interval in milliseconds (default from config)")
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Minute, "Give up if the jobs have not all finished after this long (0 waits forever)")
+
+	return cmd
+}
+
+// benchShutdownTimeout is how long an interrupted bench waits for running
+// jobs before killing them
+const benchShutdownTimeout = 5 * time.Second
+
+// isolateHome points the home directory at dir until the returned func is
+// called
+func isolateHome(dir string) func() {
+	vars := []string{"HOME", "USERPROFILE"}
+	saved := make(map[string]*string, len(vars))
+	for _, name := range vars {
+		if v, ok := os.LookupEnv(name); ok {
+			saved[name] = &v
+		} else {
+			saved[name] = nil
+		}
+		os.Setenv(name, dir)
+	}
+	return func() {
+		for name, v := range saved {
+			if v == nil {
+				os.Unsetenv(name)
+			} else {
+				os.Setenv(name, *v)
+			}
+		}
+	}
+}
+
+// benchRecorder is a notifier that records when each bench job finishes
+type benchRecorder struct {
+	mu        sync.Mutex
+	cond      *sync.Cond
+	enqueueAt map[string]time.Time
+	latencies []time.Duration
+	failed    int
+}
+
+func newBenchRecorder() *benchRecorder {
+	r := &benchRecorder{enqueueAt: make(map[string]time.Time)}
+	r.cond = sync.NewCond(&r.mu)
+	return r
+}
+
+// enqueued records the enqueue time of a job
+func (r *benchRecorder) enqueued(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.enqueueAt[id] = time.Now()
+}
+
+// Notify records a finished job
+func (r *benchRecorder) Notify(j *job.Job, event notify.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.latencies = append(r.latencies, time.Since(r.enqueueAt[j.ID]))
+	if event == notify.EventDead {
+		r.failed++
+	}
+	r.cond.Broadcast()
+	return nil
+}
+
+// wait blocks until n jobs have finished or ctx is done
+func (r *benchRecorder) wait(ctx context.Context, n int) error {
+	// Wake the loop below when ctx is done
+	stop := context.AfterFunc(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.cond.Broadcast()
+	})
+	defer stop()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for len(r.latencies) < n {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		r.cond.Wait()
+	}
+	return nil
+}
+
+// results returns the sorted latencies and the number of failed jobs
+func (r *benchRecorder) results() ([]time.Duration, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sorted := append([]time.Duration(nil), r.latencies...)
+	sort.Slice(sorted, func(a, b int) bool { return sorted[a] < sorted[b] })
+	return sorted, r.failed
+}
+
+// percentile returns the p-th percentile of sorted durations
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := (len(sorted)*p+99)/100 - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
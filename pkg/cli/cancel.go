@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/spf13/cobra"
+)
+
+func cancelCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "cancel [job-id]",
+		Short: "Stop a job that has not finished yet",
+		Long: `Cancel a pending, held or failed job, or stop one that is processing.
+
+Waiting jobs move straight to the cancelled state and are never run or
+retried. For a processing job the worker running it is asked to stop: it
+kills the command within one poll interval and marks the job cancelled.
+If the command finishes before the worker notices, its result is kept.
+
+Jobs that already completed, are dead or archived cannot be cancelled.
+
+Example:
+  queuectl cancel abc123-def456`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jobID := args[0]
+
+			j, err := getStorage().GetJob(jobID)
+			if err != nil {
+				return fmt.Errorf("failed to get job: %w", err)
+			}
+
+			if j.IsTerminal() {
+				return fmt.Errorf("job %s is already in a terminal state (%s)", jobID, j.State)
+			}
+
+			if dryRun {
+				printDryRun("cancelled", []*job.Job{j})
+				return nil
+			}
+
+			cancelled, err := getStorage().CancelJob(jobID)
+			if err != nil {
+				return err
+			}
+
+			if cancelled {
+				fmt.Printf("✓ Job %s cancelled\n", jobID)
+			} else {
+				fmt.Printf("✓ Cancellation of job %s requested\n", jobID)
+				fmt.Printf("  Worker %s will stop it shortly\n", j.WorkerID)
+			}
+
+			return nil
+		},
+	}
+
+	addDryRunFlag(cmd, &dryRun)
+
+	return cmd
+}
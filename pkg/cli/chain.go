@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/MithileshwaranS/queuectl/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func chainCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "chain [job-id]",
+		Short: "Show the retry lineage of a job",
+		Long: `Walk the retry_of links created by 'queuectl dlq retry --new-job' and
+print every job in the lineage of the given job, oldest first.
+
+Example:
+  queuectl chain abc123-def456`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jobs, err := getStorage().ListJobs("", storage.ListOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to list jobs: %w", err)
+			}
+
+			byID := make(map[string]*job.Job, len(jobs))
+			retries := make(map[string][]*job.Job)
+			for _, j := range jobs {
+				byID[j.ID] = j
+				if j.RetryOf != "" {
+					retries[j.RetryOf] = append(retries[j.RetryOf], j)
+				}
+			}
+
+			start, ok := byID[args[0]]
+			if !ok {
+				return fmt.Errorf("job not found: %s", args[0])
+			}
+
+			// Walk back to the original job, guarding against cycles
+			root := start
+			seen := map[string]bool{root.ID: true}
+			for root.RetryOf != "" {
+				parent, ok := byID[root.RetryOf]
+				if !ok || seen[parent.ID] {
+					break
+				}
+				seen[parent.ID] = true
+				root = parent
+			}
+
+			fmt.Printf("=== Retry chain of %s ===\n\n", start.ID)
+			printChain(root, retries, start.ID, 0, map[string]bool{})
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// printChain prints j and, indented below it, the jobs that retried it
+func printChain(j *job.Job, retries map[string][]*job.Job, highlight string, depth int, seen map[string]bool) {
+	if seen[j.ID] {
+		return
+	}
+	seen[j.ID] = true
+
+	marker := " "
+	if j.ID == highlight {
+		marker = "*"
+	}
+	indent := strings.Repeat("  ", depth)
+
+	fmt.Printf("%s%s %s %s %s (attempts %d/%d, created %s)\n",
+		marker, indent, getStateIcon(j.State), j.ID, j.State, j.Attempts, j.MaxRetries, formatTime(j.CreatedAt))
+	if j.Error != "" && j.State != job.StatePending {
+		fmt.Printf(" %s     Error: %s\n", indent, firstLine(j.Error))
+	}
+
+	// ListJobs returns newest first; print retries in the order they were made
+	children := retries[j.ID]
+	for i := len(children) - 1; i >= 0; i-- {
+		printChain(children[i], retries, highlight, depth+1, seen)
+	}
+}
+
+// firstLine returns the first line of a possibly multi-line message
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
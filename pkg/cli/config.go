@@ -2,7 +2,9 @@ package cli
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
+	"time"
 
 	"github.com/MithileshwaranS/queuectl/internal/config"
 	"github.com/spf13/cobra"
@@ -18,6 +20,8 @@ func configCmd() *cobra.Command {
 	cmd.AddCommand(configGetCmd())
 	cmd.AddCommand(configSetCmd())
 	cmd.AddCommand(configListCmd())
+	cmd.AddCommand(configResetCmd())
+	cmd.AddCommand(configProfileCmd())
 
 	return cmd
 }
@@ -32,7 +36,45 @@ Available keys:
   - max-retries: Maximum number of retry attempts
   - backoff-base: Base for exponential backoff calculation
   - db-path: Path to the SQLite database
-  - worker-count: Default number of workers`,
+  - worker-count: Default number of workers
+  - time-format: Timestamp display format
+  - age-priority-boost: Minutes of waiting per +1 claim priority (0 = off)
+  - log-max-size-mb: Worker log file size in MB before rotation
+  - log-max-backups: Number of compressed worker log backups to keep
+  - max-consecutive-errors: Job-fetch errors in a row before a worker stops (0 = off)
+  - notifier: Notification backend for terminal jobs
+  - notify-on-success: Also notify when jobs complete
+  - slack-webhook-url: Slack incoming webhook URL
+  - smtp-host: SMTP server host
+  - smtp-port: SMTP server port
+  - smtp-username: SMTP username
+  - smtp-password: SMTP password
+  - smtp-from: Sender address for email notifications
+  - smtp-to: Comma-separated email recipients
+  - poll-interval-ms: How often idle workers poll for jobs, in milliseconds
+  - completed-retention: How long completed jobs are kept (0 = forever)
+  - otel-endpoint: OpenTelemetry collector URL metrics are pushed to
+  - list-output-truncate: Characters of job output shown by list (0 = no limit)
+  - list-error-truncate: Characters of job errors shown by dlq list (0 = no limit)
+  - stats-interval: How often workers record job counts for 'stats history' (0 = off)
+  - stats-retention: How long job count samples are kept (0 = forever)
+  - job-schema-path: JSON Schema file enqueued jobs must match
+  - output-tail-lines: Lines of job output stored per attempt (0 = no limit)
+  - output-keep: Which end of capped output is stored: last or first
+  - max-output-bytes: Bytes of stdout and of stderr kept per attempt (0 = no limit)
+  - audit-command: Command run with a JSON record of every job start
+  - audit-url: URL every job start is POSTed to
+  - audit-required: Skip jobs whose start could not be audited
+  - job-timeout-seconds: Seconds a job may run when it sets no timeout_seconds
+  - backoff-jitter: Fraction of each backoff delay randomized away
+  - max-backoff-seconds: Longest delay between retries, in seconds
+  - stale-job-threshold: Requeue processing jobs without a heartbeat for this long (0 = off)
+  - kill-grace-seconds: Seconds stopped jobs get after SIGTERM before SIGKILL (0 = kill at once)
+  - jobs-per-second: Jobs all workers of a pool may start per second (0 = no limit)
+  - idempotency-window: How long a completed job keeps its idempotency key
+  - log-format: Worker log format: text or json
+  - log-level: Least severe worker log level written
+  - db-busy-retries: Retries of a job save or claim while SQLite is locked`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			key := args[0]
@@ -48,6 +90,82 @@ Available keys:
 				value = cfg.DBPath
 			case "worker-count":
 				value = cfg.WorkerCount
+			case "time-format":
+				value = cfg.TimeFormat
+			case "age-priority-boost":
+				value = cfg.AgePriorityBoost
+			case "log-max-size-mb":
+				value = cfg.LogMaxSizeMB
+			case "log-max-backups":
+				value = cfg.LogMaxBackups
+			case "max-consecutive-errors":
+				value = cfg.MaxConsecutiveErrors
+			case "notifier":
+				value = cfg.Notifier
+			case "notify-on-success":
+				value = cfg.NotifyOnSuccess
+			case "slack-webhook-url":
+				value = maskSecret(cfg.SlackWebhookURL)
+			case "smtp-host":
+				value = cfg.SMTPHost
+			case "smtp-port":
+				value = cfg.SMTPPort
+			case "smtp-username":
+				value = cfg.SMTPUsername
+			case "smtp-password":
+				value = maskSecret(cfg.SMTPPassword)
+			case "smtp-from":
+				value = cfg.SMTPFrom
+			case "smtp-to":
+				value = cfg.SMTPTo
+			case "poll-interval-ms":
+				value = cfg.PollIntervalMS
+			case "completed-retention":
+				value = cfg.CompletedRetention
+			case "otel-endpoint":
+				value = cfg.OTelEndpoint
+			case "list-output-truncate":
+				value = cfg.ListOutputTruncate
+			case "list-error-truncate":
+				value = cfg.ListErrorTruncate
+			case "stats-interval":
+				value = cfg.StatsInterval
+			case "stats-retention":
+				value = cfg.StatsRetention
+			case "job-schema-path":
+				value = cfg.JobSchemaPath
+			case "output-tail-lines":
+				value = cfg.OutputTailLines
+			case "output-keep":
+				value = cfg.OutputKeep
+			case "max-output-bytes":
+				value = cfg.MaxOutputBytes
+			case "audit-command":
+				value = cfg.AuditCommand
+			case "audit-url":
+				value = cfg.AuditURL
+			case "audit-required":
+				value = cfg.AuditRequired
+			case "job-timeout-seconds":
+				value = cfg.JobTimeoutSeconds
+			case "backoff-jitter":
+				value = cfg.BackoffJitter
+			case "max-backoff-seconds":
+				value = cfg.MaxBackoffSeconds
+			case "stale-job-threshold":
+				value = cfg.StaleJobThreshold
+			case "kill-grace-seconds":
+				value = cfg.KillGraceSeconds
+			case "jobs-per-second":
+				value = cfg.JobsPerSecond
+			case "idempotency-window":
+				value = cfg.IdempotencyWindow
+			case "log-format":
+				value = cfg.LogFormat
+			case "log-level":
+				value = cfg.LogLevel
+			case "db-busy-retries":
+				value = cfg.DBBusyRetries
 			default:
 				return fmt.Errorf("unknown config key: %s", key)
 			}
@@ -69,37 +187,90 @@ Available keys:
   - backoff-base: Base for exponential backoff calculation (float)
   - db-path: Path to the SQLite database (string)
   - worker-count: Default number of workers (integer)
+  - time-format: Timestamp display format (local, utc, rfc3339, unix, relative)
+  - age-priority-boost: Minutes of waiting per +1 claim priority, 0 disables (integer)
+  - log-max-size-mb: Worker log file size in MB before rotation (integer)
+  - log-max-backups: Number of compressed worker log backups to keep (integer)
+  - max-consecutive-errors: Job-fetch errors in a row before a worker stops, 0 disables (integer)
+  - notifier: Notification backend: none, slack or email
+  - notify-on-success: Also notify when jobs complete (true/false)
+  - slack-webhook-url: Slack incoming webhook URL
+  - smtp-host: SMTP server host
+  - smtp-port: SMTP server port (integer)
+  - smtp-username: SMTP username (empty for no auth)
+  - smtp-password: SMTP password
+  - smtp-from: Sender address for email notifications
+  - smtp-to: Comma-separated email recipients
+  - poll-interval-ms: How often idle workers poll for jobs in milliseconds (integer)
+  - completed-retention: Delete completed jobs older than this, e.g. 72h, 0 disables (duration)
+  - otel-endpoint: OTLP/HTTP collector URL for metrics, e.g. http://localhost:4318 (empty disables)
+  - list-output-truncate: Truncate job output in list to this many characters, 0 shows all (integer)
+  - list-error-truncate: Truncate job errors in dlq list to this many characters, 0 shows all (integer)
+  - stats-interval: Record job counts for 'stats history' this often, e.g. 5m, 0 disables (duration)
+  - stats-retention: Delete job count samples older than this, e.g. 720h, 0 keeps them (duration)
+  - job-schema-path: Path to a JSON Schema file enqueued jobs must match (empty disables)
+  - output-tail-lines: Store at most this many lines of each attempt's output, 0 keeps all (integer)
+  - output-keep: Keep the last or first output-tail-lines lines when capping (last, first)
+  - max-output-bytes: Keep only the last this many bytes of each attempt's stdout and of its stderr, even while it runs; 0 keeps all (integer)
+  - audit-command: Shell command run before each job with its audit record on stdin (empty disables)
+  - audit-url: URL the audit record of each job start is POSTed to, http:// or https:// (empty disables)
+  - audit-required: Hold a job and try again later instead of running it when its audit record cannot be delivered (true/false)
+  - job-timeout-seconds: Kill jobs without their own timeout_seconds after this many seconds (integer)
+  - backoff-jitter: Fraction of each backoff delay randomized away, 0 to 1 (float)
+  - max-backoff-seconds: Cap on the exponential backoff delay in seconds (integer)
+  - stale-job-threshold: Return processing jobs whose worker sent no heartbeat for this long to pending, e.g. 10m, 0 disables (duration)
+  - kill-grace-seconds: When a job times out or is cancelled, send its processes SIGTERM and wait this many seconds before SIGKILL, 0 kills at once (integer)
+  - jobs-per-second: Rate at which the workers of a pool may start jobs, shared by all of them, e.g. 0.5; 0 disables (float)
+  - idempotency-window: How long after completing a job still answers enqueues with its idempotency key, e.g. 24h; 0 frees the key at once (duration)
+  - log-format: Write worker logs as human-readable lines or one JSON object per line (text, json)
+  - log-level: Drop worker log records less severe than this (debug, info, warn, error)
+  - db-busy-retries: Retry a job save or claim this many times, with backoff, when the SQLite database is locked; 0 disables (integer)
 
 Examples:
   queuectl config set max-retries 5
   queuectl config set backoff-base 2.5
-  queuectl config set worker-count 3`,
+  queuectl config set worker-count 3
+  queuectl config set time-format relative`,
 		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			key := args[0]
 			valueStr := args[1]
 
+			// Only the type is checked here; config.Set checks the range
+			// or allowed values of every key
 			var value interface{}
-			var err error
-
 			switch key {
-			case "max-retries":
-				value, err = strconv.Atoi(valueStr)
+			case "max-retries", "worker-count", "age-priority-boost", "log-max-size-mb", "log-max-backups",
+				"max-consecutive-errors", "smtp-port", "poll-interval-ms", "list-output-truncate",
+				"list-error-truncate", "output-tail-lines", "max-output-bytes", "job-timeout-seconds",
+				"max-backoff-seconds", "kill-grace-seconds", "db-busy-retries":
+				n, err := strconv.Atoi(valueStr)
 				if err != nil {
-					return fmt.Errorf("max-retries must be an integer")
+					return fmt.Errorf("%s must be an integer", key)
 				}
-			case "backoff-base":
-				value, err = strconv.ParseFloat(valueStr, 64)
+				value = n
+			case "backoff-base", "backoff-jitter", "jobs-per-second":
+				f, err := strconv.ParseFloat(valueStr, 64)
 				if err != nil {
-					return fmt.Errorf("backoff-base must be a number")
+					return fmt.Errorf("%s must be a number", key)
 				}
-			case "db-path":
-				value = valueStr
-			case "worker-count":
-				value, err = strconv.Atoi(valueStr)
+				value = f
+			case "notify-on-success", "audit-required":
+				b, err := strconv.ParseBool(valueStr)
 				if err != nil {
-					return fmt.Errorf("worker-count must be an integer")
+					return fmt.Errorf("%s must be true or false", key)
 				}
+				value = b
+			case "completed-retention", "stats-interval", "stats-retention", "stale-job-threshold", "idempotency-window":
+				d, err := time.ParseDuration(valueStr)
+				if err != nil {
+					return fmt.Errorf("%s must be a duration such as 10m or 24h", key)
+				}
+				value = d.String()
+			case "db-path", "time-format", "notifier", "slack-webhook-url", "smtp-host", "smtp-username",
+				"smtp-password", "smtp-from", "smtp-to", "otel-endpoint", "job-schema-path", "output-keep",
+				"audit-command", "audit-url", "log-format", "log-level":
+				value = valueStr
 			default:
 				return fmt.Errorf("unknown config key: %s", key)
 			}
@@ -116,6 +287,51 @@ Examples:
 	}
 }
 
+func configResetCmd() *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "reset [key]",
+		Short: "Revert configuration values to their defaults",
+		Long: `Remove a key from the active profile's config file so that it falls
+back to its default value. With --all the whole config file is removed,
+resetting every key, including the per-queue defaults.
+
+Examples:
+  queuectl config reset max-retries
+  queuectl config reset --all`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if all {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key := ""
+			if !all {
+				key = args[0]
+			}
+
+			if _, err := config.Reset(key); err != nil {
+				return fmt.Errorf("failed to reset config: %w", err)
+			}
+
+			if all {
+				fmt.Println("✓ All configuration values reset to their defaults")
+			} else {
+				fmt.Printf("✓ Configuration reset to default: %s\n", key)
+			}
+			fmt.Printf("Config file: %s\n", config.GetConfigPath())
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Reset every key")
+
+	return cmd
+}
+
 func configListCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "list",
@@ -126,14 +342,87 @@ func configListCmd() *cobra.Command {
 
 			fmt.Println("=== Configuration ===")
 			fmt.Println()
-			fmt.Printf("max-retries   = %d\n", cfg.MaxRetries)
-			fmt.Printf("backoff-base  = %.1f\n", cfg.BackoffBase)
-			fmt.Printf("db-path       = %s\n", cfg.DBPath)
-			fmt.Printf("worker-count  = %d\n", cfg.WorkerCount)
+			fmt.Printf("max-retries            = %d\n", cfg.MaxRetries)
+			fmt.Printf("backoff-base           = %.1f\n", cfg.BackoffBase)
+			fmt.Printf("db-path                = %s\n", cfg.DBPath)
+			fmt.Printf("worker-count           = %d\n", cfg.WorkerCount)
+			fmt.Printf("time-format            = %s\n", cfg.TimeFormat)
+			fmt.Printf("age-priority-boost     = %d\n", cfg.AgePriorityBoost)
+			fmt.Printf("log-max-size-mb        = %d\n", cfg.LogMaxSizeMB)
+			fmt.Printf("log-max-backups        = %d\n", cfg.LogMaxBackups)
+			fmt.Printf("max-consecutive-errors = %d\n", cfg.MaxConsecutiveErrors)
+			fmt.Printf("notifier               = %s\n", cfg.Notifier)
+			fmt.Printf("notify-on-success      = %t\n", cfg.NotifyOnSuccess)
+			fmt.Printf("slack-webhook-url      = %s\n", maskSecret(cfg.SlackWebhookURL))
+			fmt.Printf("smtp-host              = %s\n", cfg.SMTPHost)
+			fmt.Printf("smtp-port              = %d\n", cfg.SMTPPort)
+			fmt.Printf("smtp-username          = %s\n", cfg.SMTPUsername)
+			fmt.Printf("smtp-password          = %s\n", maskSecret(cfg.SMTPPassword))
+			fmt.Printf("smtp-from              = %s\n", cfg.SMTPFrom)
+			fmt.Printf("smtp-to                = %s\n", cfg.SMTPTo)
+			fmt.Printf("poll-interval-ms       = %d\n", cfg.PollIntervalMS)
+			fmt.Printf("completed-retention    = %s\n", cfg.CompletedRetention)
+			fmt.Printf("otel-endpoint          = %s\n", cfg.OTelEndpoint)
+			fmt.Printf("list-output-truncate   = %d\n", cfg.ListOutputTruncate)
+			fmt.Printf("list-error-truncate    = %d\n", cfg.ListErrorTruncate)
+			fmt.Printf("stats-interval         = %s\n", cfg.StatsInterval)
+			fmt.Printf("stats-retention        = %s\n", cfg.StatsRetention)
+			fmt.Printf("job-schema-path        = %s\n", cfg.JobSchemaPath)
+			fmt.Printf("output-tail-lines      = %d\n", cfg.OutputTailLines)
+			fmt.Printf("output-keep            = %s\n", cfg.OutputKeep)
+			fmt.Printf("max-output-bytes       = %d\n", cfg.MaxOutputBytes)
+			fmt.Printf("audit-command          = %s\n", cfg.AuditCommand)
+			fmt.Printf("audit-url              = %s\n", cfg.AuditURL)
+			fmt.Printf("audit-required         = %t\n", cfg.AuditRequired)
+			fmt.Printf("job-timeout-seconds    = %d\n", cfg.JobTimeoutSeconds)
+			fmt.Printf("backoff-jitter         = %v\n", cfg.BackoffJitter)
+			fmt.Printf("max-backoff-seconds    = %d\n", cfg.MaxBackoffSeconds)
+			fmt.Printf("stale-job-threshold    = %s\n", cfg.StaleJobThreshold)
+			fmt.Printf("kill-grace-seconds     = %d\n", cfg.KillGraceSeconds)
+			fmt.Printf("jobs-per-second        = %v\n", cfg.JobsPerSecond)
+			fmt.Printf("idempotency-window     = %s\n", cfg.IdempotencyWindow)
+			fmt.Printf("log-format             = %s\n", cfg.LogFormat)
+			fmt.Printf("log-level              = %s\n", cfg.LogLevel)
+			fmt.Printf("db-busy-retries        = %d\n", cfg.DBBusyRetries)
+			printQueueDefaults(cfg)
 			fmt.Println()
+			fmt.Printf("Profile:     %s\n", config.ActiveProfile())
 			fmt.Printf("Config file: %s\n", config.GetConfigPath())
 
 			return nil
 		},
 	}
 }
+
+// printQueueDefaults lists the per-queue enqueue defaults from the config file
+func printQueueDefaults(cfg *config.Config) {
+	if len(cfg.Queues) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(cfg.Queues))
+	for name := range cfg.Queues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Println()
+	fmt.Println("Queue defaults (timeout and backoff 0 = global default):")
+	for _, name := range names {
+		qc := cfg.Queues[name]
+		maxRetries := "global"
+		if qc.MaxRetries != nil {
+			maxRetries = strconv.Itoa(*qc.MaxRetries)
+		}
+		fmt.Printf("  %-12s max-retries=%s timeout-seconds=%d backoff-base=%.1f weight=%d\n",
+			name, maxRetries, qc.TimeoutSeconds, qc.BackoffBase, qc.Weight)
+	}
+}
+
+// maskSecret hides a configured secret in command output
+func maskSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return "********"
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/MithileshwaranS/queuectl/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func dbCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Database maintenance commands",
+		Long:  `Maintenance commands for the queuectl job database.`,
+	}
+
+	cmd.AddCommand(dbNormalizeCmd())
+	cmd.AddCommand(dbOptimizeCmd())
+
+	return cmd
+}
+
+func dbNormalizeCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "normalize",
+		Short: "Re-validate and normalize all stored jobs",
+		Long: `Iterate over every stored job, re-run validation and apply defaulting,
+then save any rows that changed.
+
+This brings a database created by an older version of queuectl in line
+with the current job fields. Jobs that fail validation are reported but
+left untouched, as are jobs a worker is processing; run it again once
+they finish to normalize them too.
+
+Examples:
+  queuectl db normalize --dry-run    # Show what would change
+  queuectl db normalize              # Apply the changes`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jobs, err := getStorage().ListJobs("", storage.ListOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to list jobs: %w", err)
+			}
+
+			updated, invalid, skipped := 0, 0, 0
+			for _, j := range jobs {
+				if j.State == job.StateProcessing {
+					fmt.Printf("• Job %s is being processed, skipped\n", j.ID)
+					skipped++
+					continue
+				}
+				if err := j.Validate(); err != nil {
+					fmt.Printf("✗ Job %s is invalid: %v\n", j.ID, err)
+					invalid++
+					continue
+				}
+
+				from := j.State
+				changed := j.Normalize()
+				if len(changed) == 0 {
+					continue
+				}
+
+				if !dryRun {
+					// A job claimed since it was listed is left to its worker
+					err := getStorage().SaveJobIfState(j, from)
+					var stateErr *storage.StateError
+					if errors.As(err, &stateErr) {
+						fmt.Printf("• Job %s is now %s, skipped\n", j.ID, stateErr.State)
+						skipped++
+						continue
+					}
+					if err != nil {
+						return fmt.Errorf("failed to save job %s: %w", j.ID, err)
+					}
+				}
+				fmt.Printf("• Job %s: %s\n", j.ID, strings.Join(changed, ", "))
+				updated++
+			}
+
+			fmt.Println()
+			if dryRun {
+				fmt.Printf("Dry run: %d of %d job(s) would be updated\n", updated, len(jobs))
+			} else {
+				fmt.Printf("✓ Normalized %d of %d job(s)\n", updated, len(jobs))
+			}
+			if invalid > 0 {
+				fmt.Printf("⚠ %d job(s) failed validation\n", invalid)
+			}
+			if skipped > 0 {
+				fmt.Printf("⚠ %d job(s) skipped while a worker processed them\n", skipped)
+			}
+
+			return nil
+		},
+	}
+
+	addDryRunFlag(cmd, &dryRun)
+
+	return cmd
+}
+
+func dbOptimizeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "optimize",
+		Short: "Rebuild missing indexes and refresh query statistics",
+		Long: `Recreate any missing job indexes, then run ANALYZE and PRAGMA optimize
+so SQLite picks good query plans.
+
+Run this after heavy churn (large enqueues or DLQ clears) to keep job
+claiming fast on large tables. It does not reclaim disk space.
+
+Example:
+  queuectl db optimize`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			steps, err := getStorage().Optimize()
+			for _, step := range steps {
+				fmt.Printf("• %s\n", step)
+			}
+			if err != nil {
+				return err
+			}
+
+			fmt.Println()
+			fmt.Println("✓ Database optimized")
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func debugCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:    "debug",
+		Short:  "Diagnostic commands for troubleshooting",
+		Hidden: true,
+	}
+
+	cmd.AddCommand(debugClaimSQLCmd())
+
+	return cmd
+}
+
+func debugClaimSQLCmd() *cobra.Command {
+	var commandPrefixes, queues []string
+
+	cmd := &cobra.Command{
+		Use:   "claim-sql",
+		Short: "Print the SQL a worker runs to claim its next job",
+		Long: `Print the query and parameters a worker started now with the same
+flags would use to pick its next job, reflecting the current
+configuration (age-priority-boost, queue weights) and worker flags.
+
+Nothing is claimed. The query can be pasted into sqlite3, with the
+parameters substituted in order, to see why a job is or is not picked.
+
+Example:
+  queuectl debug claim-sql --command-prefix backup-
+  queuectl debug claim-sql --queue emails`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sqliteStore, ok := getStorage().(*storage.SQLiteStorage)
+			if !ok {
+				return fmt.Errorf("claim-sql is only available for the SQLite storage backend")
+			}
+
+			weights := getConfig().QueueWeights()
+			queue := ""
+			if weights != nil {
+				queue = "<queue picked by weighted round-robin>"
+			}
+
+			now := time.Now().Format(time.RFC3339)
+			query, params := sqliteStore.ClaimQuery(now, storage.ClaimFilter{CommandPrefixes: commandPrefixes, Queues: queues}, queue)
+
+			fmt.Println("=== Claim Query ===")
+			fmt.Println(strings.TrimSpace(dedent(query)))
+			fmt.Println()
+			fmt.Println("Parameters:")
+			for i, p := range params {
+				fmt.Printf("  %2d: %v\n", i+1, p)
+			}
+
+			if weights != nil {
+				names := make([]string, 0, len(weights))
+				for name := range weights {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+
+				fmt.Println()
+				fmt.Println("Queue weights (queues not listed weigh 1):")
+				for _, name := range names {
+					fmt.Printf("  %s = %d\n", name, weights[name])
+				}
+			}
+
+			fmt.Println()
+			fmt.Println("Before each claim, held jobs whose hold expired are returned to pending.")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&commandPrefixes, "command-prefix", nil, "Render the query for workers started with this --command-prefix (repeatable)")
+	cmd.Flags().StringArrayVarP(&queues, "queue", "q", nil, "Render the query for workers started with this --queue (repeatable)")
+
+	return cmd
+}
+
+// dedent removes leading tabs from every line of s
+func dedent(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimLeft(line, "\t")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/spf13/cobra"
+)
+
+// deleteCancelWait is how long delete --force waits for a worker to stop a
+// processing job before deleting it anyway
+const deleteCancelWait = 10 * time.Second
+
+func deleteCmd() *cobra.Command {
+	var force, dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "delete [job-id]",
+		Short: "Permanently delete a job in any state",
+		Long: `Permanently delete a job, whatever its state.
+
+A processing job is refused unless --force is given, since a worker may
+still be running it. With --force the job is cancelled first and the
+worker is given up to 10s to stop the command; the job is then deleted
+even if the worker has not responded (e.g. because it died). A worker
+that finishes the job later drops its result instead of saving the job
+again.
+
+Warning: This action cannot be undone.
+
+Examples:
+  queuectl delete abc123-def456
+  queuectl delete abc123-def456 --force`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jobID := args[0]
+
+			j, err := getStorage().GetJob(jobID)
+			if err != nil {
+				return fmt.Errorf("failed to get job: %w", err)
+			}
+
+			if j.State == job.StateProcessing && !force {
+				return fmt.Errorf("job %s is currently being processed by worker %s (use --force to cancel and delete it)", jobID, j.WorkerID)
+			}
+
+			if dryRun {
+				printDryRun("deleted", []*job.Job{j})
+				return nil
+			}
+
+			state := j.State
+			if state == job.StateProcessing {
+				if state, err = cancelBeforeDelete(j); err != nil {
+					return err
+				}
+			}
+
+			// The job is deleted only in the state it was checked in, so
+			// one a worker claims meanwhile is not pulled from under it
+			if err := getStorage().DeleteJobIfState(jobID, state); err != nil {
+				return fmt.Errorf("failed to delete job: %w", err)
+			}
+
+			fmt.Printf("✓ Job %s permanently deleted (was %s)\n", jobID, state)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Cancel and delete the job even if a worker is processing it")
+	addDryRunFlag(cmd, &dryRun)
+
+	return cmd
+}
+
+// cancelBeforeDelete asks the worker processing j to stop and waits up to
+// deleteCancelWait for the job to leave the processing state. It returns
+// the state the job is in when it stops waiting.
+func cancelBeforeDelete(j *job.Job) (job.State, error) {
+	if _, err := getStorage().CancelJob(j.ID); err != nil {
+		return "", fmt.Errorf("failed to cancel job: %w", err)
+	}
+	fmt.Printf("Waiting for worker %s to stop job %s...\n", j.WorkerID, j.ID)
+
+	deadline := time.Now().Add(deleteCancelWait)
+	for time.Now().Before(deadline) {
+		current, err := getStorage().GetJob(j.ID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get job: %w", err)
+		}
+		if current.State != job.StateProcessing {
+			return current.State, nil
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	fmt.Printf("Warning: worker %s did not stop the job within %s; deleting it anyway (the worker will drop its result)\n", j.WorkerID, deleteCancelWait)
+	return job.StateProcessing, nil
+}
@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/MithileshwaranS/queuectl/internal/worker"
+	"github.com/spf13/cobra"
+)
+
+func describeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "describe [job-id]",
+		Short: "Show every detail of a single job",
+		Long: `Print all fields of a job, including its complete command, output and
+error, which 'list' truncates, and the history of its attempts.
+
+With --output json the stored job is printed as a JSON object.
+
+Example:
+  queuectl describe abc123-def456
+  queuectl describe abc123-def456 -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			j, err := getStorage().GetJob(args[0])
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return fmt.Errorf("job not found: %s", args[0])
+				}
+				return fmt.Errorf("failed to get job: %w", err)
+			}
+
+			if jsonOutput() {
+				return printJSON(j)
+			}
+
+			describeJob(j)
+			return nil
+		},
+	}
+}
+
+// describeJob prints every field of j without truncation
+func describeJob(j *job.Job) {
+	fmt.Printf("=== Job %s ===\n\n", j.ID)
+
+	fmt.Printf("%-22s %s %s\n", "State:", getStateIcon(j.State), j.State)
+	if j.State == job.StateProcessing && j.Progress > 0 {
+		fmt.Printf("%-22s %s\n", "Progress:", progressBar(j.Progress))
+	}
+	fmt.Printf("%-22s %s\n", "Queue:", j.Queue)
+	fmt.Printf("%-22s %d\n", "Sequence:", j.Seq)
+	fmt.Printf("%-22s %d\n", "Priority:", j.Priority)
+	describeField("Retry Of:", j.RetryOf)
+	describeField("Parent:", j.ParentID)
+
+	fmt.Println()
+	fmt.Println("Command:")
+	printBlock(j.Command)
+	if j.FallbackCommand != "" {
+		fmt.Println("Fallback Command:")
+		printBlock(j.FallbackCommand)
+	}
+
+	fmt.Println()
+	fmt.Printf("%-22s %d/%d\n", "Attempts:", j.Attempts, j.MaxRetries)
+	timeout := "default"
+	if j.TimeoutSeconds > 0 {
+		timeout = fmt.Sprintf("%ds", j.TimeoutSeconds)
+	}
+	fmt.Printf("%-22s %s\n", "Timeout:", timeout)
+	backoff := "default"
+	if j.BackoffBase > 0 {
+		backoff = fmt.Sprintf("%g", j.BackoffBase)
+	}
+	fmt.Printf("%-22s %s\n", "Backoff Base:", backoff)
+	if len(j.RetrySchedule) > 0 {
+		delays := make([]string, len(j.RetrySchedule))
+		for i, d := range j.RetrySchedule {
+			delays[i] = time.Duration(d).String()
+		}
+		fmt.Printf("%-22s %s\n", "Retry Schedule:", strings.Join(delays, ", "))
+	}
+	fmt.Printf("%-22s %t\n", "Retry On Timeout Only:", j.RetryOnTimeoutOnly)
+	fmt.Printf("%-22s %t\n", "Sandbox:", j.Sandbox)
+	describeField("Env File:", j.EnvFile)
+	if len(j.Env) > 0 {
+		names := make([]string, 0, len(j.Env))
+		for name := range j.Env {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for i, name := range names {
+			label := ""
+			if i == 0 {
+				label = "Env:"
+			}
+			fmt.Printf("%-22s %s=%s\n", label, name, j.Env[name])
+		}
+	}
+	describeField("Work Dir:", j.WorkDir)
+	describeField("Idempotency Key:", j.IdempotencyKey)
+	if len(j.Tags) > 0 {
+		fmt.Printf("%-22s %s\n", "Tags:", strings.Join(j.Tags, ", "))
+	}
+	describeField("Success Pattern:", j.SuccessPattern)
+	describeField("Failure Pattern:", j.FailurePattern)
+
+	fmt.Println()
+	fmt.Printf("%-22s %s\n", "Created:", formatTime(j.CreatedAt))
+	fmt.Printf("%-22s %s\n", "Updated:", formatTime(j.UpdatedAt))
+	describeTime("Scheduled:", j.ScheduledAt)
+	describeTime("Next Retry:", j.NextRetryAt)
+	describeTime("Held Until:", j.HeldUntil)
+	describeTime("Completed:", j.CompletedAt)
+	describeField("Worker:", j.WorkerID)
+
+	if len(j.History) > 0 {
+		fmt.Println()
+		fmt.Printf("%-22s %d\n", "Exit Code:", j.ExitCode)
+		describeField("Error Type:", string(j.ErrorType))
+		if j.DurationMS > 0 {
+			fmt.Printf("%-22s %s\n", "Duration:", formatRunTime(j.DurationMS))
+		}
+		if j.CPUTimeMS > 0 || j.MaxRSSKB > 0 {
+			fmt.Printf("%-22s %s\n", "Resources:", worker.FormatUsage(j))
+		}
+
+		fmt.Println()
+		fmt.Println("History:")
+		for _, a := range j.History {
+			result := "ok"
+			if a.Error != "" {
+				result = a.Error
+			}
+			fmt.Printf("  #%d  %s  %s\n", a.Attempt, formatTime(a.StartedAt), a.Command)
+			fmt.Printf("      %s\n", result)
+		}
+	}
+
+	if j.NextJob != nil {
+		fmt.Println()
+		fmt.Println("Next Job:")
+		printBlock(j.NextJob.Command)
+		if j.NextJob.DependsDelaySeconds > 0 {
+			fmt.Printf("%-22s %ds\n", "Next Job Delay:", j.NextJob.DependsDelaySeconds)
+		}
+	}
+
+	if j.ParentOutput != "" {
+		fmt.Println()
+		fmt.Println("Parent Output:")
+		printBlock(j.ParentOutput)
+	}
+
+	if j.Error != "" {
+		fmt.Println()
+		fmt.Println("Error:")
+		printBlock(j.Error)
+	}
+
+	if j.Output != "" {
+		fmt.Println()
+		fmt.Println("Output:")
+		printBlock(j.Output)
+	}
+}
+
+// describeField prints a labelled value, skipping empty ones
+func describeField(label, value string) {
+	if value != "" {
+		fmt.Printf("%-22s %s\n", label, value)
+	}
+}
+
+// describeTime prints a labelled timestamp, skipping unset ones
+func describeTime(label string, t *time.Time) {
+	if t != nil {
+		fmt.Printf("%-22s %s\n", label, formatTime(*t))
+	}
+}
+
+// printBlock prints text in full, indented by two spaces
+func printBlock(text string) {
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		fmt.Printf("  %s\n", line)
+	}
+}
@@ -1,10 +1,20 @@
 package cli
 
 import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/MithileshwaranS/queuectl/internal/storage"
 	"github.com/spf13/cobra"
 )
 
@@ -20,8 +30,10 @@ all retry attempts. These jobs require manual intervention.`,
 
 	cmd.AddCommand(dlqListCmd())
 	cmd.AddCommand(dlqRetryCmd())
+	cmd.AddCommand(dlqRetryAllCmd())
 	cmd.AddCommand(dlqDeleteCmd())
 	cmd.AddCommand(dlqClearCmd())
+	cmd.AddCommand(dlqExportCmd())
 
 	return cmd
 }
@@ -52,16 +64,16 @@ func dlqListCmd() *cobra.Command {
 				fmt.Printf("Job ID: %s\n", j.ID)
 				fmt.Printf("Command: %s\n", j.Command)
 				fmt.Printf("Attempts: %d/%d\n", j.Attempts, j.MaxRetries)
-				fmt.Printf("Created: %s\n", j.CreatedAt.Format("2006-01-02 15:04:05"))
-				fmt.Printf("Failed: %s\n", j.UpdatedAt.Format("2006-01-02 15:04:05"))
+				fmt.Printf("Created: %s\n", formatTime(j.CreatedAt))
+				fmt.Printf("Failed: %s\n", formatTime(j.UpdatedAt))
+
+				// Jobs killed before they ever ran have no exit code
+				if len(j.History) > 0 {
+					fmt.Printf("Exit Code: %d\n", j.ExitCode)
+				}
 
 				if j.Error != "" {
-					// Truncate long errors
-					errMsg := j.Error
-					if len(errMsg) > 300 {
-						errMsg = errMsg[:300] + "..."
-					}
-					fmt.Printf("Error: %s\n", errMsg)
+					fmt.Printf("Error: %s\n", truncateText(j.Error, getConfig().ListErrorTruncate))
 				}
 
 				fmt.Println()
@@ -75,6 +87,9 @@ func dlqListCmd() *cobra.Command {
 }
 
 func dlqRetryCmd() *cobra.Command {
+	var newJob bool
+	var delay time.Duration
+
 	cmd := &cobra.Command{
 		Use:   "retry [job-id]",
 		Short: "Retry a job from the Dead Letter Queue",
@@ -83,11 +98,24 @@ func dlqRetryCmd() *cobra.Command {
 This resets the job's attempt counter and clears the error.
 The job will be picked up by the next available worker.
 
-Example:
-  queuectl dlq retry abc123-def456`,
+With --new-job the dead job is moved out of the DLQ to the archived
+state, keeping its attempts, error and history, and a new pending job is
+created with the same spec and a retry_of link to it. Use 'queuectl
+chain' to walk the lineage.
+
+With --delay the job stays pending but is not picked up until the delay
+has passed, e.g. to replay it after a maintenance window.
+
+Examples:
+  queuectl dlq retry abc123-def456
+  queuectl dlq retry abc123-def456 --new-job
+  queuectl dlq retry abc123-def456 --delay 1h`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			jobID := args[0]
+			if delay < 0 {
+				return fmt.Errorf("--delay cannot be negative")
+			}
 
 			// Get the job
 			j, err := getStorage().GetJob(jobID)
@@ -100,25 +128,173 @@ Example:
 				return fmt.Errorf("job %s is not in the Dead Letter Queue (current state: %s)", jobID, j.State)
 			}
 
+			var scheduledAt *time.Time
+			if delay > 0 {
+				t := time.Now().Add(delay)
+				scheduledAt = &t
+			}
+
+			if newJob {
+				retry := j.NewRetry()
+				retry.ScheduledAt = scheduledAt
+				j.Archive()
+
+				// Both or neither, so a failure cannot leave a retry of a
+				// job that is still dead
+				if err := getStorage().SaveJobs([]*job.Job{retry, j}); err != nil {
+					return fmt.Errorf("failed to archive and retry job: %w", err)
+				}
+
+				fmt.Printf("✓ Job %s archived and retried as new job %s\n", jobID, retry.ID)
+				printRetryStart(scheduledAt)
+				return nil
+			}
+
 			// Reset for retry
 			j.ResetForRetry()
+			j.ScheduledAt = scheduledAt
 
-			// Save updated job
-			if err := getStorage().SaveJob(j); err != nil {
+			// Only update the job if it is still dead, so a concurrent
+			// retry that a worker already claimed is not reset
+			if err := getStorage().SaveJobIfState(j, job.StateDead); err != nil {
 				return fmt.Errorf("failed to retry job: %w", err)
 			}
 
 			fmt.Printf("✓ Job %s moved from DLQ to pending queue\n", jobID)
-			fmt.Println("  The job will be picked up by the next available worker")
+			printRetryStart(scheduledAt)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&newJob, "new-job", false, "Archive the dead job and retry it as a new job linked by retry_of")
+	cmd.Flags().DurationVar(&delay, "delay", 0, "Wait this long before the retry can run, e.g. 1h (default: immediately)")
+
+	return cmd
+}
+
+// printRetryStart tells the user when a retried job will run
+func printRetryStart(scheduledAt *time.Time) {
+	if scheduledAt != nil {
+		fmt.Printf("  The job is scheduled to run at %s\n", formatTime(*scheduledAt))
+		return
+	}
+	fmt.Println("  The job will be picked up by the next available worker")
+}
+
+func dlqRetryAllCmd() *cobra.Command {
+	var rate string
+
+	cmd := &cobra.Command{
+		Use:   "retry-all",
+		Short: "Retry every job in the Dead Letter Queue",
+		Long: `Move all jobs from the DLQ back to pending state for retry.
+
+With --rate the replays are spread out by staggering each job's scheduled
+start time, so they trickle back into processing instead of all failing
+again at once if the root cause isn't fully fixed.
+
+Rate format is <count>/<unit>, where unit is s, min or h.
+
+Examples:
+  queuectl dlq retry-all                # Retry everything immediately
+  queuectl dlq retry-all --rate 10/min  # Release 10 jobs per minute`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var interval time.Duration
+			if rate != "" {
+				var err error
+				interval, err = parseRate(rate)
+				if err != nil {
+					return err
+				}
+			}
+
+			jobs, err := getStorage().GetDLQJobs()
+			if err != nil {
+				return fmt.Errorf("failed to list DLQ jobs: %w", err)
+			}
+
+			if len(jobs) == 0 {
+				fmt.Println("✓ Dead Letter Queue is empty")
+				return nil
+			}
+
+			// Replay the oldest failures first
+			sort.Slice(jobs, func(a, b int) bool {
+				return jobs[a].UpdatedAt.Before(jobs[b].UpdatedAt)
+			})
+
+			start := time.Now()
+			retried, skipped := 0, 0
+			for _, j := range jobs {
+				j.ResetForRetry()
+				if interval > 0 && retried > 0 {
+					scheduledAt := start.Add(time.Duration(retried) * interval)
+					j.ScheduledAt = &scheduledAt
+				}
+
+				// Jobs retried by someone else since the listing are skipped
+				err := getStorage().SaveJobIfState(j, job.StateDead)
+				var stateErr *storage.StateError
+				if errors.As(err, &stateErr) || errors.Is(err, sql.ErrNoRows) {
+					skipped++
+					continue
+				}
+				if err != nil {
+					fmt.Printf("Warning: Failed to retry job %s: %v\n", j.ID, err)
+					continue
+				}
+				retried++
+			}
+
+			fmt.Printf("✓ Moved %d job(s) from DLQ to pending queue\n", retried)
+			if skipped > 0 {
+				fmt.Printf("  Skipped %d job(s) that left the DLQ meanwhile\n", skipped)
+			}
+			if interval > 0 && retried > 1 {
+				last := start.Add(time.Duration(retried-1) * interval)
+				fmt.Printf("  Replays staggered every %s, last one at %s\n", interval, formatTime(last))
+			}
 
 			return nil
 		},
 	}
 
+	cmd.Flags().StringVar(&rate, "rate", "", "Maximum replay rate, e.g. 10/min (default: all at once)")
+
 	return cmd
 }
 
+// parseRate parses a rate like "10/min" into the interval between events
+func parseRate(rate string) (time.Duration, error) {
+	parts := strings.SplitN(rate, "/", 2)
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid rate %q (expected <count>/<unit>, e.g. 10/min)", rate)
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || count < 1 {
+		return 0, fmt.Errorf("invalid rate %q: count must be a positive integer", rate)
+	}
+
+	var unit time.Duration
+	switch strings.TrimSpace(parts[1]) {
+	case "s", "sec", "second":
+		unit = time.Second
+	case "m", "min", "minute":
+		unit = time.Minute
+	case "h", "hour":
+		unit = time.Hour
+	default:
+		return 0, fmt.Errorf("invalid rate %q: unit must be s, min or h", rate)
+	}
+
+	return unit / time.Duration(count), nil
+}
+
 func dlqDeleteCmd() *cobra.Command {
+	var dryRun bool
+
 	cmd := &cobra.Command{
 		Use:   "delete [job-id]",
 		Short: "Delete a job from the Dead Letter Queue",
@@ -142,6 +318,11 @@ Example:
 				return fmt.Errorf("job %s is not in the Dead Letter Queue (current state: %s)", jobID, j.State)
 			}
 
+			if dryRun {
+				printDryRun("deleted", []*job.Job{j})
+				return nil
+			}
+
 			// Delete the job
 			if err := getStorage().DeleteJob(jobID); err != nil {
 				return fmt.Errorf("failed to delete job: %w", err)
@@ -153,23 +334,28 @@ Example:
 		},
 	}
 
+	addDryRunFlag(cmd, &dryRun)
+
 	return cmd
 }
 
 func dlqClearCmd() *cobra.Command {
 	var force bool
+	var dryRun bool
 
 	cmd := &cobra.Command{
 		Use:   "clear",
 		Short: "Clear all jobs from the Dead Letter Queue",
 		Long: `Delete all jobs from the DLQ.
 
-Warning: This action cannot be undone. Use --force to confirm.
+Warning: This action cannot be undone. Use --force to confirm,
+or --dry-run to see which jobs would be deleted.
 
-Example:
+Examples:
+  queuectl dlq clear --dry-run
   queuectl dlq clear --force`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if !force {
+			if !force && !dryRun {
 				return fmt.Errorf("this action requires --force flag to confirm")
 			}
 
@@ -184,6 +370,11 @@ Example:
 				return nil
 			}
 
+			if dryRun {
+				printDryRun("deleted", jobs)
+				return nil
+			}
+
 			// Delete all jobs
 			deletedCount := 0
 			for _, j := range jobs {
@@ -201,6 +392,104 @@ Example:
 	}
 
 	cmd.Flags().BoolVarP(&force, "force", "f", false, "Confirm deletion of all DLQ jobs")
+	addDryRunFlag(cmd, &dryRun)
 
 	return cmd
 }
+
+func dlqExportCmd() *cobra.Command {
+	var format string
+	var outputPath string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the Dead Letter Queue as a report",
+		Long: `Export all DLQ jobs as CSV or JSON for offline review.
+
+CSV columns: id, command, attempts, max_retries, created_at, failed_at,
+error, error_type. Timestamps are written in RFC3339 and fields are
+quoted as needed, so multi-line errors are preserved.
+
+Examples:
+  queuectl dlq export --format csv --out-file dlq.csv
+  queuectl dlq export --format json > dlq.json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "csv" && format != "json" {
+				return fmt.Errorf("invalid format: %s (valid: csv, json)", format)
+			}
+
+			jobs, err := getStorage().GetDLQJobs()
+			if err != nil {
+				return fmt.Errorf("failed to list DLQ jobs: %w", err)
+			}
+
+			out := os.Stdout
+			if outputPath != "" {
+				f, err := os.Create(outputPath)
+				if err != nil {
+					return fmt.Errorf("failed to create output file: %w", err)
+				}
+				defer f.Close()
+				out = f
+			}
+
+			if format == "json" {
+				err = writeDLQJSON(out, jobs)
+			} else {
+				err = writeDLQCSV(out, jobs)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to export DLQ: %w", err)
+			}
+
+			if outputPath != "" {
+				fmt.Printf("✓ Exported %d DLQ job(s) to %s\n", len(jobs), outputPath)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "csv", "Export format (csv, json)")
+	cmd.Flags().StringVar(&outputPath, "out-file", "", "Write the report to a file instead of stdout")
+
+	return cmd
+}
+
+// writeDLQCSV writes DLQ jobs as a CSV report
+func writeDLQCSV(out io.Writer, jobs []*job.Job) error {
+	w := csv.NewWriter(out)
+	header := []string{"id", "command", "attempts", "max_retries", "created_at", "failed_at", "error", "error_type"}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	for _, j := range jobs {
+		record := []string{
+			j.ID,
+			j.Command,
+			strconv.Itoa(j.Attempts),
+			strconv.Itoa(j.MaxRetries),
+			j.CreatedAt.Format(time.RFC3339),
+			j.UpdatedAt.Format(time.RFC3339),
+			j.Error,
+			string(j.ErrorType),
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// writeDLQJSON writes DLQ jobs as an indented JSON array
+func writeDLQJSON(out io.Writer, jobs []*job.Job) error {
+	if jobs == nil {
+		jobs = []*job.Job{}
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(jobs)
+}
@@ -0,0 +1,21 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/spf13/cobra"
+)
+
+// addDryRunFlag registers the shared --dry-run flag on a destructive command
+func addDryRunFlag(cmd *cobra.Command, dryRun *bool) {
+	cmd.Flags().BoolVar(dryRun, "dry-run", false, "Show what would be affected without making changes")
+}
+
+// printDryRun reports the jobs a destructive command would affect
+func printDryRun(action string, jobs []*job.Job) {
+	fmt.Printf("Dry run: %d job(s) would be %s\n", len(jobs), action)
+	for _, j := range jobs {
+		fmt.Printf("  • %s [%s] %s\n", j.ID, j.State, j.Command)
+	}
+}
@@ -1,15 +1,28 @@
 package cli
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/MithileshwaranS/queuectl/internal/storage"
+	"github.com/MithileshwaranS/queuectl/internal/worker"
 	"github.com/spf13/cobra"
 )
 
 func enqueueCmd() *cobra.Command {
+	var idFromCommand, overwrite, requireWorker, dryRun, attach bool
+	var file, queue string
+	var attachTimeout time.Duration
+
 	cmd := &cobra.Command{
-		Use:   "enqueue [job-json]",
+		Use:   "enqueue [job-json] | --file jobs.json",
 		Short: "Add a new job to the queue",
 		Long: `Enqueue a new job by providing a JSON string with job details.
 
@@ -17,44 +30,538 @@ Example:
   queuectl enqueue '{"command":"echo Hello World"}'
   queuectl enqueue '{"command":"sleep 5", "max_retries":5}'
   queuectl enqueue '{"id":"custom-id","command":"ls -la"}'
+  queuectl enqueue --overwrite '{"id":"custom-id","command":"ls -la /tmp"}'
+  queuectl enqueue --id-from-command '{"command":"./nightly-report.sh"}'
+  queuectl enqueue --file jobs.ndjson
+  queuectl enqueue --queue emails '{"command":"./send-digest.sh"}'
+  queuectl enqueue --attach --timeout 10m '{"command":"./deploy.sh"}'
 
 Job JSON fields:
   - command (required): Shell command to execute
-  - id (optional): Custom job ID (auto-generated if not provided)
-  - max_retries (optional): Maximum retry attempts (default: 3)`,
-		Args: cobra.ExactArgs(1),
+  - id (optional): Custom job ID (auto-generated if not provided). It must
+    not belong to a stored job unless --overwrite is given
+  - queue (optional): Queue the job belongs to (default: the --queue flag,
+    else "default")
+  - priority (optional): Higher priorities are claimed first; jobs of equal
+    priority run in enqueue order (default: 0)
+  - max_retries (optional): Maximum retry attempts; 0 moves the job to the
+    DLQ on its first failure (default: the queue's max_retries, else the
+    max-retries config value)
+  - timeout_seconds (optional): Kill the command after this many seconds
+    (default: the queue's timeout_seconds, else the job-timeout-seconds
+    config value)
+  - backoff_base (optional): Retry backoff base (default: the queue's
+    backoff_base, else the backoff-base config value)
+  - retry_schedule (optional): Explicit retry delays, e.g. ["30s","5m","1h"].
+    Retry N waits for entry N; once the list runs out the last entry is
+    reused until max_retries is reached. Without max_retries, the job
+    retries once per entry. Overrides backoff_base
+  - fallback_command (optional): Command to run instead of "command" on retries
+  - env_file (optional): Path to a KEY=VALUE file loaded into the command's environment
+  - env (optional): Object of variables added to the command's environment,
+    e.g. {"STAGE":"prod"}; they override env_file
+  - work_dir (optional): Absolute directory the command runs in (default:
+    the worker's working directory). Cannot be combined with sandbox
+  - tags (optional): Labels for grouping jobs, e.g. ["nightly","reports"];
+    see 'queuectl list --tag'
+  - idempotency_key (optional): Caller-chosen key identifying the logical
+    job; see below
+  - retry_on_timeout_only (optional): Only retry attempts that timed out; any
+    other failure moves the job straight to the DLQ (default: false)
+  - sandbox (optional): Run in a fresh temporary directory that is removed
+    afterwards; its path is exported as QUEUECTL_SANDBOX (default: false)
+  - success_pattern (optional): Regex the output must match for the job to succeed
+  - failure_pattern (optional): Regex that marks the job failed if found in the
+    output, even when the command exits 0
+  - next_job (optional): A nested job spec enqueued when this job succeeds.
+    It receives this job's output (up to 64KB) in QUEUECTL_PARENT_OUTPUT and
+    may have its own next_job, up to 10 levels deep
+  - depends_delay_seconds (optional, next_job only): Seconds to wait after the
+    parent job completes before the follow-up can be claimed
+
+With --id-from-command the job ID is derived from a hash of the command
+(whitespace is trimmed and collapsed first), so enqueuing the same command
+again reuses the same ID. The new job then replaces the stored one: a
+completed, failed or dead job is re-queued as a fresh pending job, and a
+pending job is overwritten in place, so at most one job exists per
+command. Enqueuing is refused while a worker is processing the job.
+Commands that differ only in whitespace, even inside quotes, are treated
+as identical. IDs use 64 bits of SHA-256, so unrelated commands colliding
+is not a practical concern. The flag cannot be combined with "id".
+
+With --overwrite a job whose "id" is already stored replaces the stored
+job, as with --id-from-command; without it the enqueue is refused.
+
+A job with an "idempotency_key" is only enqueued if no other job holds
+the key. A job holds its key until it finishes, and a completed job keeps
+it for idempotency-window (default 1h) after completion; dead and
+cancelled jobs release it at once. When the key is held, nothing is
+saved and the existing job is reported as "returned existing job <id>",
+so callers can safely retry an enqueue.
+
+If job-schema-path is configured, the job JSON must also conform to that
+JSON Schema; any violations are listed and the job is rejected.
+
+With --require-worker the job is only enqueued if at least one worker is
+currently running (as reported by "queuectl status"). The check is
+advisory: workers may still stop before the job is claimed, and jobs
+enqueued without the flag wait in pending until a worker starts.
+
+With --file every job in the file is enqueued. The file holds either a
+JSON array of job objects or one job object per line (newline-delimited
+JSON, blank lines ignored). Each job is validated as above; invalid ones
+are reported with their line number and skipped, and the valid ones are
+saved together in a single transaction. An "id" used twice in the file
+is rejected the same way as one already stored.
+
+With --dry-run the jobs are parsed and validated exactly as above, and
+the job that would be enqueued is printed with its resolved defaults,
+but nothing is saved. The command still fails if any job is invalid, so
+it can check generated job definitions in CI.
+
+With --attach the command waits until the job is completed, dead or
+cancelled, retries included, then prints the job's output and exits with
+the exit code of its last attempt (1 if the command could not be run or
+was stopped). The enqueue summary goes to stderr, so stdout holds only
+the job's output, as if the command had been run directly. A job
+returned for its idempotency key is waited for in the same way. With
+--timeout the wait is abandoned after that long with exit code 124; the
+job itself stays queued.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if file != "" {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// Parse job from JSON
-			j, err := job.FromJSON(args[0])
+			if attach && file != "" {
+				return fmt.Errorf("--attach cannot be used with --file")
+			}
+			if attachTimeout != 0 && !attach {
+				return fmt.Errorf("--timeout requires --attach")
+			}
+
+			// Enforce the configured job schema before anything else
+			schema, err := loadJobSchema()
+			if err != nil {
+				return err
+			}
+
+			if file != "" {
+				return enqueueFile(file, schema, queue, idFromCommand, overwrite, requireWorker, dryRun)
+			}
+
+			j, existing, err := prepareJob(args[0], schema, queue, idFromCommand, overwrite)
 			if err != nil {
-				return fmt.Errorf("invalid job JSON: %w", err)
+				return err
+			}
+			// Attached, stdout is left to the job's output
+			out := os.Stdout
+			if attach && !dryRun {
+				out = os.Stderr
+			}
+
+			if existing != nil {
+				if dryRun {
+					fmt.Printf("Dry run: job is valid, but enqueuing it would return existing job %s\n", existing.ID)
+					return nil
+				}
+				printExistingJob(out, existing)
+				if attach {
+					return attachJob(cmd, existing.ID, attachTimeout)
+				}
+				return nil
 			}
 
-			// Validate job
-			if err := j.Validate(); err != nil {
-				return fmt.Errorf("invalid job: %w", err)
+			if dryRun {
+				fmt.Println("Dry run: job is valid and would be enqueued")
+				printResolvedJob(j)
+				return nil
 			}
 
-			// Use config default for max_retries if not specified
-			if j.MaxRetries == 0 {
-				j.MaxRetries = getConfig().MaxRetries
+			if requireWorker && len(getActiveWorkers()) == 0 {
+				return errNoWorkers
 			}
 
-			// Save to storage
-			if err := getStorage().SaveJob(j); err != nil {
+			err = saveNewJob(j, overwrite, idFromCommand)
+			if errors.Is(err, storage.ErrJobExists) {
+				return errJobExists(j.ID)
+			}
+			if errors.Is(err, storage.ErrIdempotencyKeyInUse) {
+				// Another enqueue with the same key saved its job first
+				existing, findErr := findIdempotentJob(j.IdempotencyKey)
+				if findErr == nil && existing != nil {
+					printExistingJob(out, existing)
+					if attach {
+						return attachJob(cmd, existing.ID, attachTimeout)
+					}
+					return nil
+				}
+			}
+			if err != nil {
 				return fmt.Errorf("failed to enqueue job: %w", err)
 			}
 
 			// Print success with job details
-			fmt.Printf("✓ Job enqueued successfully\n")
-			fmt.Printf("  ID: %s\n", j.ID)
-			fmt.Printf("  Command: %s\n", j.Command)
-			fmt.Printf("  State: %s\n", j.State)
-			fmt.Printf("  Max Retries: %d\n", j.MaxRetries)
+			fmt.Fprintf(out, "✓ Job enqueued successfully\n")
+			fmt.Fprintf(out, "  ID: %s\n", j.ID)
+			fmt.Fprintf(out, "  Command: %s\n", j.Command)
+			fmt.Fprintf(out, "  State: %s\n", j.State)
+			fmt.Fprintf(out, "  Queue: %s\n", j.Queue)
+			fmt.Fprintf(out, "  Max Retries: %d\n", j.MaxRetries)
 
+			if attach {
+				return attachJob(cmd, j.ID, attachTimeout)
+			}
 			return nil
 		},
 	}
 
+	cmd.Flags().BoolVar(&idFromCommand, "id-from-command", false, "Derive the job ID from a hash of the command so identical commands share one job")
+	cmd.Flags().BoolVar(&overwrite, "overwrite", false, "Replace a stored job that has the same id instead of refusing")
+	cmd.Flags().BoolVar(&requireWorker, "require-worker", false, "Refuse to enqueue unless at least one worker is running")
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Enqueue every job in a JSON array or newline-delimited JSON file")
+	cmd.Flags().StringVarP(&queue, "queue", "q", "", "Queue for jobs whose JSON does not name one (default \"default\")")
+	cmd.Flags().BoolVar(&attach, "attach", false, "Wait for the job to finish, print its output and exit with its exit code")
+	cmd.Flags().DurationVar(&attachTimeout, "timeout", 0, "With --attach, stop waiting after this long, e.g. 30m (0 waits forever)")
+	addDryRunFlag(cmd, &dryRun)
+
 	return cmd
-}
\ No newline at end of file
+}
+
+// loadJobSchema compiles the configured job schema, or returns nil if
+// none is set. Every command that stores a job from outside the queue
+// (enqueue, import, transfer, schedule add) checks it.
+func loadJobSchema() (*job.Schema, error) {
+	path := getConfig().JobSchemaPath
+	if path == "" {
+		return nil, nil
+	}
+	return job.LoadSchema(path)
+}
+
+// errNoWorkers is returned by --require-worker when no worker is running
+var errNoWorkers = fmt.Errorf("no workers are running; start one with 'queuectl worker start' or drop --require-worker")
+
+// errJobExists is returned when an explicit id is taken and --overwrite
+// was not given
+func errJobExists(id string) error {
+	return fmt.Errorf("job %s already exists (use --overwrite to replace it)", id)
+}
+
+// errJobProcessing reports a job that cannot be replaced because a worker
+// holds it
+func errJobProcessing(id string) error {
+	return fmt.Errorf("job %s is currently being processed by a worker", id)
+}
+
+// saveNewJob stores a job prepared by prepareJob. New jobs are inserted so
+// that a job stored since prepareJob checked the ID is not replaced, and
+// replaced jobs are only written if no worker has claimed them since.
+func saveNewJob(j *job.Job, overwrite, idFromCommand bool) error {
+	if !overwrite && !idFromCommand {
+		return getStorage().InsertJob(j)
+	}
+	busy, err := getStorage().ReplaceJobs([]*job.Job{j})
+	if err != nil {
+		return err
+	}
+	if len(busy) > 0 {
+		return errJobProcessing(j.ID)
+	}
+	return nil
+}
+
+// prepareJob parses and validates one job spec and applies the queue
+// defaults, --queue and --id-from-command, ready to be saved. Unless overwrite is
+// set, an explicit id that is already stored is rejected. If another job
+// holds the spec's idempotency key, that job is returned as existing and
+// the new one must not be saved.
+func prepareJob(spec string, schema *job.Schema, queue string, idFromCommand, overwrite bool) (j, existing *job.Job, err error) {
+	if schema != nil {
+		if err := schema.Validate(spec); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Parse job from JSON
+	j, err = job.FromJSON(spec)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid job JSON: %w", err)
+	}
+
+	// Validate job
+	if err := j.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid job: %w", err)
+	}
+
+	// Fields present in the JSON override the queue defaults
+	var specified map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(spec), &specified); err != nil {
+		return nil, nil, fmt.Errorf("invalid job JSON: %w", err)
+	}
+	if _, ok := specified["queue"]; !ok && queue != "" {
+		j.Queue = queue
+	}
+
+	// A repeated enqueue returns the first job before its id is checked,
+	// since the repeat usually carries the same id too
+	if existing, err = findIdempotentJob(j.IdempotencyKey); err != nil || existing != nil {
+		return j, existing, err
+	}
+
+	if idFromCommand {
+		if _, ok := specified["id"]; ok {
+			return nil, nil, fmt.Errorf("--id-from-command cannot be used with an explicit id")
+		}
+		j.ID = job.IDFromCommand(j.Command)
+
+		stored, err := getStorage().GetJob(j.ID)
+		if err == nil && stored.State == job.StateProcessing {
+			return nil, nil, fmt.Errorf("job %s for this command is currently being processed by worker %s", j.ID, stored.WorkerID)
+		}
+	} else if _, ok := specified["id"]; ok {
+		stored, err := getStorage().GetJob(j.ID)
+		if err == nil {
+			if !overwrite {
+				return nil, nil, errJobExists(j.ID)
+			}
+			if stored.State == job.StateProcessing {
+				return nil, nil, fmt.Errorf("job %s is currently being processed by worker %s", j.ID, stored.WorkerID)
+			}
+		}
+	}
+
+	// Apply the job's queue defaults, falling back to the global ones
+	worker.ApplyQueueDefaults(j, getConfig(), specified)
+	if err := prepareNextJobs(j.NextJob, specified["next_job"], schema); err != nil {
+		return nil, nil, err
+	}
+
+	return j, nil, nil
+}
+
+// prepareNextJobs checks each job of a next_job chain against the job
+// schema and applies its queue defaults at enqueue time, while its spec
+// still shows which fields it sets, so an explicit 0 is kept when the job
+// is spawned
+func prepareNextJobs(next *job.Job, spec json.RawMessage, schema *job.Schema) error {
+	for next != nil {
+		if schema != nil {
+			if err := schema.Validate(string(spec)); err != nil {
+				return fmt.Errorf("invalid next_job: %w", err)
+			}
+		}
+		var specified map[string]json.RawMessage
+		if err := json.Unmarshal(spec, &specified); err != nil {
+			return fmt.Errorf("invalid next_job: %w", err)
+		}
+		if next.Queue == "" {
+			next.Queue = job.DefaultQueue
+		}
+		worker.ApplyQueueDefaults(next, getConfig(), specified)
+		next, spec = next.NextJob, specified["next_job"]
+	}
+	return nil
+}
+
+// findIdempotentJob returns the job holding an idempotency key within the
+// configured idempotency window, or nil if the key is empty or free
+func findIdempotentJob(key string) (*job.Job, error) {
+	if key == "" {
+		return nil, nil
+	}
+	existing, err := getStorage().FindByIdempotencyKey(key, getConfig().IdempotencyWindow)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up idempotency key: %w", err)
+	}
+	return existing, nil
+}
+
+// printExistingJob reports the job an enqueue returned because it holds
+// the new job's idempotency key
+func printExistingJob(out io.Writer, j *job.Job) {
+	fmt.Fprintf(out, "returned existing job %s\n", j.ID)
+	fmt.Fprintf(out, "  Idempotency Key: %s\n", j.IdempotencyKey)
+	fmt.Fprintf(out, "  Command: %s\n", j.Command)
+	fmt.Fprintf(out, "  State: %s\n", j.State)
+	fmt.Fprintf(out, "  Queue: %s\n", j.Queue)
+}
+
+// printResolvedJob prints the fields of a job about to be enqueued,
+// including the defaults applied to it
+func printResolvedJob(j *job.Job) {
+	backoff := getConfig().BackoffBase
+	if j.BackoffBase > 0 {
+		backoff = j.BackoffBase
+	}
+
+	fmt.Printf("  ID: %s\n", j.ID)
+	fmt.Printf("  Command: %s\n", j.Command)
+	fmt.Printf("  State: %s\n", j.State)
+	fmt.Printf("  Queue: %s\n", j.Queue)
+	fmt.Printf("  Priority: %d\n", j.Priority)
+	fmt.Printf("  Max Retries: %d\n", j.MaxRetries)
+	fmt.Printf("  Timeout: %s\n", worker.JobTimeout(j, getConfig()))
+	fmt.Printf("  Backoff Base: %g\n", backoff)
+	if j.ScheduledAt != nil {
+		fmt.Printf("  Scheduled At: %s\n", formatTime(*j.ScheduledAt))
+	}
+	if j.NextJob != nil {
+		fmt.Printf("  Next Job: %s\n", j.NextJob.Command)
+	}
+}
+
+// jobSpec is one job read from an --file import
+type jobSpec struct {
+	line int
+	json string
+}
+
+// enqueueFile enqueues every valid job in path, reporting invalid ones by
+// line number. It fails if any job was rejected. With dryRun the valid jobs
+// are listed instead of saved.
+func enqueueFile(path string, schema *job.Schema, queue string, idFromCommand, overwrite, requireWorker, dryRun bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read job file: %w", err)
+	}
+
+	specs, err := readJobSpecs(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(specs) == 0 {
+		return fmt.Errorf("no jobs found in %s", path)
+	}
+
+	var jobs []*job.Job
+	var failures, returned []string
+	seen := make(map[string]int)
+	keys := make(map[string]*job.Job)
+	for _, spec := range specs {
+		j, existing, err := prepareJob(spec.json, schema, queue, idFromCommand, overwrite)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("line %d: %v", spec.line, err))
+			continue
+		}
+		// A key used earlier in the file is held by that line's job
+		if existing == nil && j.IdempotencyKey != "" {
+			existing = keys[j.IdempotencyKey]
+		}
+		if existing != nil {
+			returned = append(returned, fmt.Sprintf("line %d: returned existing job %s", spec.line, existing.ID))
+			continue
+		}
+		// --id-from-command deliberately maps repeated commands to one job
+		if line, ok := seen[j.ID]; ok && !overwrite && !idFromCommand {
+			failures = append(failures, fmt.Sprintf("line %d: job %s already appears on line %d", spec.line, j.ID, line))
+			continue
+		}
+		seen[j.ID] = spec.line
+		if j.IdempotencyKey != "" {
+			keys[j.IdempotencyKey] = j
+		}
+		jobs = append(jobs, j)
+	}
+
+	enqueued := len(jobs)
+	if dryRun {
+		fmt.Printf("Dry run: %d of %d job(s) from %s would be enqueued\n", len(jobs), len(specs), path)
+		for _, j := range jobs {
+			fmt.Printf("  • %s [%s] %s (max retries %d)\n", j.ID, j.Queue, j.Command, j.MaxRetries)
+		}
+	} else if len(jobs) > 0 {
+		if requireWorker && len(getActiveWorkers()) == 0 {
+			return errNoWorkers
+		}
+		// As for a single job, new jobs are inserted so that one stored
+		// since prepareJob checked its ID is reported, not replaced, and
+		// replacements skip jobs a worker claimed since
+		var taken, busy []*job.Job
+		if overwrite || idFromCommand {
+			busy, err = getStorage().ReplaceJobs(jobs)
+		} else {
+			taken, err = getStorage().InsertJobs(jobs)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to enqueue jobs: %w", err)
+		}
+		for _, j := range taken {
+			failures = append(failures, fmt.Sprintf("line %d: %v", seen[j.ID], errJobExists(j.ID)))
+		}
+		for _, j := range busy {
+			failures = append(failures, fmt.Sprintf("line %d: %v", seen[j.ID], errJobProcessing(j.ID)))
+		}
+		enqueued -= len(taken) + len(busy)
+	}
+
+	if !dryRun {
+		fmt.Printf("✓ Enqueued %d of %d job(s) from %s\n", enqueued, len(specs), path)
+	}
+	for _, r := range returned {
+		fmt.Printf("  %s\n", r)
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+
+	fmt.Printf("✗ %d job(s) rejected:\n", len(failures))
+	for _, f := range failures {
+		fmt.Printf("  %s\n", f)
+	}
+	return fmt.Errorf("%d of %d job(s) could not be enqueued", len(failures), len(specs))
+}
+
+// readJobSpecs splits a job file into its job objects. A file starting
+// with '[' is a JSON array; anything else is read as one object per line.
+func readJobSpecs(data []byte) ([]jobSpec, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		return readJobArray(data)
+	}
+
+	var specs []jobSpec
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		specs = append(specs, jobSpec{line: i + 1, json: line})
+	}
+	return specs, nil
+}
+
+// readJobArray reads the elements of a JSON array along with the line
+// each one starts on
+func readJobArray(data []byte) ([]jobSpec, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+
+	var specs []jobSpec
+	for dec.More() {
+		// The element starts after the separator following the last token
+		start := int(dec.InputOffset())
+		for start < len(data) && strings.ContainsRune(" \t\r\n,", rune(data[start])) {
+			start++
+		}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineAt(data, start), err)
+		}
+		specs = append(specs, jobSpec{line: lineAt(data, start), json: string(raw)})
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return specs, nil
+}
+
+// lineAt returns the 1-based line number of the byte at offset
+func lineAt(data []byte, offset int) int {
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
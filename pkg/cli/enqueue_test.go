@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/MithileshwaranS/queuectl/internal/config"
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/MithileshwaranS/queuectl/internal/storage"
+)
+
+// useTestStorage points the commands at an empty in-memory store with the
+// default config for the duration of the test
+func useTestStorage(t *testing.T) *storage.MemoryStorage {
+	t.Helper()
+	m := storage.NewMemoryStorage()
+	if err := m.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	prevStore, prevCfg := store, cfg
+	store, cfg = m, config.DefaultConfig()
+	t.Cleanup(func() { store, cfg = prevStore, prevCfg })
+	return m
+}
+
+func TestOverwriteSkipsJobClaimedAfterCheck(t *testing.T) {
+	m := useTestStorage(t)
+	old := job.NewJob("echo old", 3)
+	old.ID = "job-1"
+	if err := m.SaveJob(old); err != nil {
+		t.Fatal(err)
+	}
+
+	j, existing, err := prepareJob(`{"id":"job-1","command":"echo new"}`, nil, "", false, true)
+	if err != nil || existing != nil {
+		t.Fatalf("prepareJob = %v, %v", existing, err)
+	}
+
+	// A worker claims the job after prepareJob found it pending
+	if claimed, err := m.GetNextPendingJob("w1", storage.ClaimFilter{}); err != nil || claimed == nil {
+		t.Fatalf("claim = %v, %v", claimed, err)
+	}
+
+	if err := saveNewJob(j, true, false); err == nil {
+		t.Fatal("expected overwriting a claimed job to fail")
+	}
+	stored, err := m.GetJob("job-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.State != job.StateProcessing || stored.WorkerID != "w1" || stored.Command != "echo old" {
+		t.Errorf("stored job = %s by %q running %q, want the claimed original", stored.State, stored.WorkerID, stored.Command)
+	}
+}
+
+func TestIDFromCommandSkipsJobClaimedAfterCheck(t *testing.T) {
+	m := useTestStorage(t)
+	old := job.NewJob("echo same", 3)
+	old.ID = job.IDFromCommand(old.Command)
+	old.Priority = 1
+	if err := m.SaveJob(old); err != nil {
+		t.Fatal(err)
+	}
+
+	j, existing, err := prepareJob(`{"command":"echo same","priority":5}`, nil, "", true, false)
+	if err != nil || existing != nil {
+		t.Fatalf("prepareJob = %v, %v", existing, err)
+	}
+	if claimed, err := m.GetNextPendingJob("w1", storage.ClaimFilter{}); err != nil || claimed == nil {
+		t.Fatalf("claim = %v, %v", claimed, err)
+	}
+
+	if err := saveNewJob(j, false, true); err == nil {
+		t.Fatal("expected replacing a claimed job to fail")
+	}
+	stored, err := m.GetJob(old.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.State != job.StateProcessing || stored.Priority != 1 {
+		t.Errorf("stored job = %s with priority %d, want the claimed original", stored.State, stored.Priority)
+	}
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/spf13/cobra"
+)
+
+func etaCmd() *cobra.Command {
+	var window time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "eta",
+		Short: "Estimate when the backlog will be drained",
+		Long: `Estimate how long it will take to drain the current backlog.
+
+The backlog is every pending, processing and failed (awaiting retry) job.
+The drain rate is the number of jobs completed over the recent --window.
+The estimate assumes that rate holds, and the per-worker breakdown
+assumes throughput scales linearly with the number of workers.
+
+Examples:
+  queuectl eta               # Based on the last 15 minutes
+  queuectl eta --window 1h   # Smooth over a longer window`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if window < time.Minute {
+				return fmt.Errorf("--window must be at least 1m")
+			}
+
+			stats, err := getStorage().GetJobStats()
+			if err != nil {
+				return fmt.Errorf("failed to get job stats: %w", err)
+			}
+			backlog := stats[job.StatePending] + stats[job.StateProcessing] + stats[job.StateFailed]
+
+			buckets, err := getStorage().GetThroughput(time.Now().Add(-window), window)
+			if err != nil {
+				return fmt.Errorf("failed to get throughput: %w", err)
+			}
+			completed := 0
+			for _, b := range buckets {
+				completed += b.Count
+			}
+			rate := float64(completed) / window.Minutes()
+
+			workers := len(getActiveWorkers())
+
+			fmt.Println("=== Backlog ETA ===")
+			fmt.Println()
+			fmt.Printf("Backlog: %d job(s) (pending %d, processing %d, failed %d)\n",
+				backlog, stats[job.StatePending], stats[job.StateProcessing], stats[job.StateFailed])
+			fmt.Printf("Rate: %.2f job(s)/min over the last %s\n", rate, window)
+			fmt.Printf("Active workers: %d\n", workers)
+			fmt.Println()
+
+			if backlog == 0 {
+				fmt.Println("Backlog is empty")
+				return nil
+			}
+			if rate == 0 {
+				fmt.Println("No jobs completed in the window; cannot estimate drain time")
+				return nil
+			}
+
+			fmt.Printf("ETA at current rate: %s\n", formatETA(backlog, rate))
+
+			if workers == 0 {
+				return nil
+			}
+			perWorker := rate / float64(workers)
+			fmt.Println()
+			fmt.Printf("Per worker: %.2f job(s)/min\n", perWorker)
+			for _, n := range []int{1, workers, workers * 2} {
+				if n == 1 && workers == 1 {
+					continue
+				}
+				fmt.Printf("  %3d worker(s): %s\n", n, formatETA(backlog, perWorker*float64(n)))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&window, "window", 15*time.Minute, "How far back to measure the completion rate")
+
+	return cmd
+}
+
+// formatETA renders the time to finish backlog jobs at rate jobs/minute
+func formatETA(backlog int, rate float64) string {
+	d := time.Duration(float64(backlog) / rate * float64(time.Minute))
+	if d < time.Minute {
+		return d.Round(time.Second).String()
+	}
+	return d.Round(time.Minute).String()
+}
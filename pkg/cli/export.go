@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/MithileshwaranS/queuectl/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func exportCmd() *cobra.Command {
+	var file, state string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write every job to an NDJSON file for backup or migration",
+		Long: `Write jobs as newline-delimited JSON, one full job object per line,
+oldest first. The file can be loaded into any database with
+'queuectl import', keeping job IDs, states, attempts and history.
+
+Without --file the jobs are written to stdout.
+
+Examples:
+  queuectl export --file dump.ndjson
+  queuectl export --state dead --file dead.ndjson
+  queuectl export | gzip > dump.ndjson.gz`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s := job.State(state)
+			if state != "" && !isJobState(s) {
+				return fmt.Errorf("invalid state: %s (valid: pending, processing, completed, failed, dead, held, archived, cancelled)", state)
+			}
+
+			jobs, err := getStorage().ListJobs(s, storage.ListOptions{SortBy: storage.SortCreated, Ascending: true})
+			if err != nil {
+				return fmt.Errorf("failed to list jobs: %w", err)
+			}
+
+			out := io.Writer(os.Stdout)
+			if file != "" {
+				f, err := os.Create(file)
+				if err != nil {
+					return fmt.Errorf("failed to create export file: %w", err)
+				}
+				defer f.Close()
+				out = f
+			}
+
+			// Encode writes each job compactly on its own line
+			enc := json.NewEncoder(out)
+			for _, j := range jobs {
+				if err := enc.Encode(j); err != nil {
+					return fmt.Errorf("failed to export job %s: %w", j.ID, err)
+				}
+			}
+
+			if file != "" {
+				fmt.Printf("✓ Exported %d job(s) to %s\n", len(jobs), file)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&file, "file", "", "Write the jobs to this file instead of stdout")
+	cmd.Flags().StringVar(&state, "state", "", "Only export jobs in this state (empty for every state)")
+
+	return cmd
+}
+
+func importCmd() *cobra.Command {
+	var file string
+	var overwrite bool
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Load jobs written by 'queuectl export'",
+		Long: `Save every job in a file written by 'queuectl export' (or any file of
+job objects accepted by 'enqueue --file') into the configured database.
+Job IDs, states, attempts and history are kept as they are.
+
+Jobs that were processing when exported are imported as pending, since
+the worker running them does not exist in this database. A job whose ID
+is already stored is skipped unless --overwrite is given; a stored job
+that is being processed is never overwritten. Invalid jobs, including
+ones that do not match the configured job-schema-path, are reported with
+their line number and skipped.
+
+Examples:
+  queuectl import --file dump.ndjson
+  queuectl --profile staging import --file dump.ndjson --overwrite`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if file == "" {
+				return fmt.Errorf("--file is required")
+			}
+
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("failed to read import file: %w", err)
+			}
+			specs, err := readJobSpecs(data)
+			if err != nil {
+				return fmt.Errorf("failed to parse %s: %w", file, err)
+			}
+			schema, err := loadJobSchema()
+			if err != nil {
+				return err
+			}
+
+			imported, skipped, failed := 0, 0, 0
+			for _, spec := range specs {
+				j, err := readImportedJob(spec.json, schema)
+				if err != nil {
+					fmt.Printf("✗ Line %d: %v\n", spec.line, err)
+					failed++
+					continue
+				}
+
+				if overwrite {
+					existing, err := getStorage().GetJob(j.ID)
+					if err == nil && existing.State == job.StateProcessing {
+						fmt.Printf("• Skipped %s: stored job is being processed\n", j.ID)
+						skipped++
+						continue
+					}
+					err = getStorage().SaveJob(j)
+				} else {
+					err = getStorage().InsertJob(j)
+				}
+				if errors.Is(err, storage.ErrJobExists) {
+					fmt.Printf("• Skipped %s: ID already exists\n", j.ID)
+					skipped++
+					continue
+				}
+				if err != nil {
+					fmt.Printf("✗ Line %d: failed to save job %s: %v\n", spec.line, j.ID, err)
+					failed++
+					continue
+				}
+				imported++
+			}
+
+			fmt.Printf("✓ Imported %d job(s) from %s (%d skipped, %d failed)\n", imported, file, skipped, failed)
+			if failed > 0 {
+				return fmt.Errorf("%d job(s) could not be imported", failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&file, "file", "", "File of jobs to import")
+	cmd.Flags().BoolVar(&overwrite, "overwrite", false, "Replace stored jobs that have the same ID instead of skipping them")
+
+	return cmd
+}
+
+// readImportedJob parses and validates one exported job, returning jobs
+// that were processing to pending
+func readImportedJob(spec string, schema *job.Schema) (*job.Job, error) {
+	if schema != nil {
+		if err := schema.Validate(spec); err != nil {
+			return nil, err
+		}
+	}
+	j, err := job.FromJSON(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid job JSON: %w", err)
+	}
+	if err := j.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid job: %w", err)
+	}
+	if !isJobState(j.State) {
+		return nil, fmt.Errorf("invalid state: %s", j.State)
+	}
+
+	if j.State == job.StateProcessing {
+		j.Requeue("requeued: the job was processing when it was exported")
+	}
+	return j, nil
+}
@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/config"
+)
+
+// displayTimeLayout is the layout used for the default "local" time format
+const displayTimeLayout = "2006-01-02 15:04:05"
+
+// Values for the global --output flag
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// outputFormat holds the value of the global --output flag
+var outputFormat string
+
+// validateOutputFormat checks that the given output format is supported
+func validateOutputFormat(format string) error {
+	if format != outputText && format != outputJSON {
+		return fmt.Errorf("invalid output format: %s (valid: text, json)", format)
+	}
+	return nil
+}
+
+// jsonOutput reports whether --output json was given
+func jsonOutput() bool {
+	return outputFormat == outputJSON
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to encode JSON output: %w", err)
+	}
+	return nil
+}
+
+// timeFormatFlag holds the value of the global --time-format flag
+var timeFormatFlag string
+
+// getTimeFormat returns the active time format, preferring the
+// --time-format flag over the configured value
+func getTimeFormat() string {
+	if timeFormatFlag != "" {
+		return timeFormatFlag
+	}
+	if cfg := getConfig(); cfg != nil && cfg.TimeFormat != "" {
+		return cfg.TimeFormat
+	}
+	return config.TimeFormatLocal
+}
+
+// validateTimeFormat checks that the given time format is supported
+func validateTimeFormat(format string) error {
+	for _, f := range config.ValidTimeFormats {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid time format: %s (valid: local, utc, rfc3339, unix, relative)", format)
+}
+
+// formatTime renders a timestamp according to the active time format
+func formatTime(t time.Time) string {
+	switch getTimeFormat() {
+	case config.TimeFormatUTC:
+		return t.UTC().Format(displayTimeLayout) + " UTC"
+	case config.TimeFormatRFC3339:
+		return t.Format(time.RFC3339)
+	case config.TimeFormatUnix:
+		return strconv.FormatInt(t.Unix(), 10)
+	case config.TimeFormatRelative:
+		return formatRelative(t, time.Now())
+	default:
+		return t.Local().Format(displayTimeLayout)
+	}
+}
+
+// formatRelative renders t relative to now, e.g. "3m ago" or "in 2h"
+func formatRelative(t, now time.Time) string {
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+
+	var s string
+	switch {
+	case d < time.Second:
+		return "just now"
+	case d < time.Minute:
+		s = fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		s = fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		s = fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		s = fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+
+	if future {
+		return "in " + s
+	}
+	return s + " ago"
+}
+
+// formatRunTime renders a duration in milliseconds as seconds, e.g. "1.25s"
+func formatRunTime(ms int64) string {
+	return fmt.Sprintf("%.2fs", float64(ms)/1000)
+}
+
+// progressBarWidth is the number of cells in a progress bar
+const progressBarWidth = 20
+
+// progressBar renders a percentage as a bar, e.g. "[█████░░░░░░░░░░░░░░░] 25%"
+func progressBar(percent int) string {
+	filled := percent * progressBarWidth / 100
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", progressBarWidth-filled) + fmt.Sprintf("] %d%%", percent)
+}
+
+// truncateText shortens s to at most limit characters followed by "...",
+// never splitting a multi-byte character. A limit of 0 disables
+// truncation.
+func truncateText(s string, limit int) string {
+	if limit <= 0 || len(s) <= limit {
+		return s
+	}
+	n := 0
+	for i := range s {
+		if n == limit {
+			return s[:i] + "..."
+		}
+		n++
+	}
+	return s
+}
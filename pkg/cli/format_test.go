@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		s     string
+		limit int
+		want  string
+	}{
+		{"hello", 0, "hello"},
+		{"hello", 5, "hello"},
+		{"hello", 3, "hel..."},
+		// Multi-byte characters count once and are never split
+		{"héllo", 2, "hé..."},
+		{"日本語テキスト", 3, "日本語..."},
+		{"日本語", 3, "日本語"},
+		{"日本語", 5, "日本語"},
+	}
+	for _, tt := range tests {
+		got := truncateText(tt.s, tt.limit)
+		if got != tt.want {
+			t.Errorf("truncateText(%q, %d) = %q, want %q", tt.s, tt.limit, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("truncateText(%q, %d) = %q is not valid UTF-8", tt.s, tt.limit, got)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/spf13/cobra"
+)
+
+func holdCmd() *cobra.Command {
+	var duration time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "hold [job-id]",
+		Short: "Temporarily take a pending job out of rotation",
+		Long: `Move a pending job to the held state for a fixed duration.
+
+Held jobs are not claimed by workers. Once the hold expires the job
+automatically returns to pending and is picked up as usual. Holding an
+already held job replaces its expiry.
+
+Example:
+  queuectl hold abc123-def456 --for 10m`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jobID := args[0]
+
+			if duration <= 0 {
+				return fmt.Errorf("--for must be positive")
+			}
+
+			j, err := getStorage().GetJob(jobID)
+			if err != nil {
+				return fmt.Errorf("failed to get job: %w", err)
+			}
+
+			if j.State != job.StatePending && j.State != job.StateHeld {
+				return fmt.Errorf("job %s is not pending (state: %s)", jobID, j.State)
+			}
+
+			from := j.State
+			until := time.Now().Add(duration)
+			j.Hold(until)
+
+			// Only update the job if no worker has claimed it since it was read
+			if err := getStorage().SaveJobIfState(j, from); err != nil {
+				return fmt.Errorf("failed to hold job: %w", err)
+			}
+
+			fmt.Printf("✓ Job %s held\n", jobID)
+			fmt.Printf("  Until: %s\n", formatTime(until))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&duration, "for", 10*time.Minute, "How long to hold the job")
+
+	return cmd
+}
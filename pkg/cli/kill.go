@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/spf13/cobra"
+)
+
+func killCmd() *cobra.Command {
+	var reason string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "kill [job-id]",
+		Short: "Mark a job as permanently dead without running it",
+		Long: `Move a pending or failed job straight to the Dead Letter Queue.
+
+The job is not executed and is not retried. Unlike deleting it, the job
+is kept as a terminal failure with the given reason for auditing.
+
+Jobs that already completed or are dead cannot be killed, and jobs that
+a worker is currently processing are refused since the worker would
+overwrite the result.
+
+Example:
+  queuectl kill abc123-def456 --reason "superseded by nightly rebuild"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jobID := args[0]
+
+			j, err := getStorage().GetJob(jobID)
+			if err != nil {
+				return fmt.Errorf("failed to get job: %w", err)
+			}
+
+			if j.IsTerminal() {
+				return fmt.Errorf("job %s is already in a terminal state (%s)", jobID, j.State)
+			}
+			if j.State == job.StateProcessing {
+				return fmt.Errorf("job %s is currently being processed by worker %s", jobID, j.WorkerID)
+			}
+
+			if dryRun {
+				printDryRun("marked as dead", []*job.Job{j})
+				return nil
+			}
+
+			from := j.State
+			j.MarkAsDead(fmt.Sprintf("killed: %s", reason))
+			j.ErrorType = job.ErrorTypeKilled
+
+			// Only update the job if no worker has claimed it since it was read
+			if err := getStorage().SaveJobIfState(j, from); err != nil {
+				return fmt.Errorf("failed to kill job: %w", err)
+			}
+
+			fmt.Printf("✓ Job %s marked as dead\n", jobID)
+			fmt.Printf("  Reason: %s\n", reason)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&reason, "reason", "r", "killed by operator", "Reason recorded on the dead job")
+	addDryRunFlag(cmd, &dryRun)
+
+	return cmd
+}
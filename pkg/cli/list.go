@@ -3,25 +3,54 @@ package cli
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/MithileshwaranS/queuectl/internal/storage"
+	"github.com/MithileshwaranS/queuectl/internal/worker"
 	"github.com/spf13/cobra"
 )
 
 func listCmd() *cobra.Command {
 	var stateFilter string
+	var commandFilter, tag string
+	var since, until, workerID, queue string
+	var glob bool
+	var limit, offset int
+	var sortFlag string
 
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List jobs by state",
 		Long: `List all jobs or filter by specific state.
 
-States: pending, processing, completed, failed, dead
+States: pending, processing, completed, failed, dead, held, archived, cancelled
 
 Examples:
   queuectl list                    # List all jobs
   queuectl list --state pending    # List only pending jobs
-  queuectl list --state failed     # List failed jobs`,
+  queuectl list --state failed     # List failed jobs
+  queuectl list --command backup   # Commands containing "backup"
+  queuectl list --command 'backup-*' --glob   # Commands matching a glob
+  queuectl list --tag nightly                 # Jobs tagged "nightly"
+  queuectl list --queue emails --state dead   # Dead jobs of the emails queue
+  queuectl list --since 1h --worker ab12cd34  # Updated by a worker in the last hour
+  queuectl list --since "2024-05-01 14:00" --until "2024-05-01 15:00"
+  queuectl list --limit 20 --offset 20        # Second page of 20
+  queuectl list --sort priority               # Highest priority first
+  queuectl list --sort updated:asc            # Least recently updated first
+
+Jobs are listed newest first by default. --sort takes created, updated,
+priority or attempts, optionally followed by :asc or :desc (default).
+
+--since and --until select jobs by when they were last updated,
+inclusive. Each takes an RFC3339 time, a local "YYYY-MM-DD HH:MM[:SS]"
+time, or a duration meaning that long ago (e.g. 90m). --worker selects
+jobs last claimed by the worker with that ID, as shown by status; failed
+jobs waiting to retry have no worker. All filters combine with --state.
+
+With --output json the jobs are printed as a JSON array of full job
+objects instead.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var state job.State
 			if stateFilter != "" {
@@ -33,6 +62,9 @@ Examples:
 					job.StateCompleted,
 					job.StateFailed,
 					job.StateDead,
+					job.StateHeld,
+					job.StateArchived,
+					job.StateCancelled,
 				}
 				valid := false
 				for _, s := range validStates {
@@ -42,16 +74,69 @@ Examples:
 					}
 				}
 				if !valid {
-					return fmt.Errorf("invalid state: %s (valid: pending, processing, completed, failed, dead)", stateFilter)
+					return fmt.Errorf("invalid state: %s (valid: pending, processing, completed, failed, dead, held, archived, cancelled)", stateFilter)
+				}
+			}
+
+			var matcher commandMatcher
+			if commandFilter != "" {
+				var err error
+				matcher, err = newCommandMatcher(commandFilter, glob)
+				if err != nil {
+					return err
+				}
+			} else if glob {
+				return fmt.Errorf("--glob requires --command")
+			}
+
+			opts, err := parseListSort(sortFlag)
+			if err != nil {
+				return err
+			}
+			opts.Tag = tag
+			opts.WorkerID = workerID
+			opts.Queue = queue
+			now := time.Now()
+			if since != "" {
+				if opts.UpdatedSince, err = parseListTime("--since", since, now); err != nil {
+					return err
+				}
+			}
+			if until != "" {
+				if opts.UpdatedUntil, err = parseListTime("--until", until, now); err != nil {
+					return err
 				}
 			}
+			if since != "" && until != "" && opts.UpdatedUntil.Before(opts.UpdatedSince) {
+				return fmt.Errorf("--until cannot be before --since")
+			}
+			if limit < 0 || offset < 0 {
+				return fmt.Errorf("--limit and --offset cannot be negative")
+			}
+
+			// The command filter runs here rather than in storage, so page
+			// after filtering when it is set
+			if matcher == nil {
+				opts.Limit, opts.Offset = limit, offset
+			}
 
 			// Get jobs from storage
-			jobs, err := getStorage().ListJobs(state)
+			jobs, err := getStorage().ListJobs(state, opts)
 			if err != nil {
 				return fmt.Errorf("failed to list jobs: %w", err)
 			}
 
+			if matcher != nil {
+				jobs = paginateJobs(filterJobsByCommand(jobs, matcher), limit, offset)
+			}
+
+			if jsonOutput() {
+				if jobs == nil {
+					jobs = []*job.Job{}
+				}
+				return printJSON(jobs)
+			}
+
 			// Display results
 			if len(jobs) == 0 {
 				if stateFilter != "" {
@@ -66,7 +151,8 @@ Examples:
 			if stateFilter != "" {
 				fmt.Printf("=== Jobs (state: %s) ===\n\n", stateFilter)
 			} else {
-				fmt.Println("=== All Jobs ===\n")
+				fmt.Println("=== All Jobs ===")
+				fmt.Println()
 			}
 
 			// Print jobs
@@ -75,45 +161,178 @@ Examples:
 					fmt.Println(strings.Repeat("-", 60))
 				}
 
-				icon := getStateIcon(j.State)
-				fmt.Printf("Job ID: %s\n", j.ID)
-				fmt.Printf("Command: %s\n", j.Command)
-				fmt.Printf("State: %s %s\n", icon, j.State)
-				fmt.Printf("Attempts: %d/%d\n", j.Attempts, j.MaxRetries)
-				fmt.Printf("Created: %s\n", j.CreatedAt.Format("2006-01-02 15:04:05"))
-				fmt.Printf("Updated: %s\n", j.UpdatedAt.Format("2006-01-02 15:04:05"))
-
-				if j.NextRetryAt != nil {
-					fmt.Printf("Next Retry: %s\n", j.NextRetryAt.Format("2006-01-02 15:04:05"))
-				}
-
-				if j.WorkerID != "" {
-					fmt.Printf("Worker: %s\n", j.WorkerID)
-				}
-
-				if j.Error != "" {
-					fmt.Printf("Error: %s\n", j.Error)
-				}
-
-				if j.Output != "" {
-					// Truncate long output
-					output := j.Output
-					if len(output) > 200 {
-						output = output[:200] + "..."
-					}
-					fmt.Printf("Output: %s\n", output)
-				}
-
+				printJob(j)
 				fmt.Println()
 			}
 
-			fmt.Printf("Total: %d job(s)\n", len(jobs))
+			if limit > 0 || offset > 0 {
+				fmt.Printf("Showing %d job(s) from offset %d\n", len(jobs), offset)
+			} else {
+				fmt.Printf("Total: %d job(s)\n", len(jobs))
+			}
 
 			return nil
 		},
 	}
 
-	cmd.Flags().StringVarP(&stateFilter, "state", "s", "", "Filter by state (pending, processing, completed, failed, dead)")
+	cmd.Flags().StringVarP(&stateFilter, "state", "s", "", "Filter by state (pending, processing, completed, failed, dead, held, archived, cancelled)")
+	cmd.Flags().StringVar(&commandFilter, "command", "", "Filter by command (substring, or glob with --glob)")
+	cmd.Flags().BoolVar(&glob, "glob", false, "Treat --command as a glob pattern")
+	cmd.Flags().StringVar(&tag, "tag", "", "Only jobs carrying this tag (exact match)")
+	cmd.Flags().StringVarP(&queue, "queue", "q", "", "Only jobs in this queue")
+	cmd.Flags().IntVar(&limit, "limit", 0, "Show at most this many jobs (0 = all)")
+	cmd.Flags().IntVar(&offset, "offset", 0, "Skip this many jobs first")
+	cmd.Flags().StringVar(&since, "since", "", "Only jobs updated at or after this time (timestamp or duration ago, e.g. 1h)")
+	cmd.Flags().StringVar(&until, "until", "", "Only jobs updated at or before this time (timestamp or duration ago)")
+	cmd.Flags().StringVar(&workerID, "worker", "", "Only jobs last claimed by this worker ID")
+	cmd.Flags().StringVar(&sortFlag, "sort", "", "Sort by created, updated, priority or attempts, with optional :asc or :desc")
 
 	return cmd
-}
\ No newline at end of file
+}
+
+// printJob prints the details of a single job as shown by list
+func printJob(j *job.Job) {
+	icon := getStateIcon(j.State)
+	fmt.Printf("Job ID: %s\n", j.ID)
+	fmt.Printf("Queue: %s\n", j.Queue)
+	if j.RetryOf != "" {
+		fmt.Printf("Retry Of: %s\n", j.RetryOf)
+	}
+	if j.ParentID != "" {
+		fmt.Printf("Parent: %s\n", j.ParentID)
+	}
+	fmt.Printf("Command: %s\n", j.Command)
+	if len(j.Tags) > 0 {
+		fmt.Printf("Tags: %s\n", strings.Join(j.Tags, ", "))
+	}
+	if j.FallbackCommand != "" {
+		fmt.Printf("Fallback: %s\n", j.FallbackCommand)
+	}
+	if j.NextJob != nil {
+		fmt.Printf("Next Job: %s\n", j.NextJob.Command)
+	}
+	fmt.Printf("State: %s %s\n", icon, j.State)
+	if j.State == job.StateProcessing && j.Progress > 0 {
+		fmt.Printf("Progress: %s\n", progressBar(j.Progress))
+	}
+	fmt.Printf("Attempts: %d/%d\n", j.Attempts, j.MaxRetries)
+	if j.Priority != 0 {
+		fmt.Printf("Priority: %d\n", j.Priority)
+	}
+	if j.TimeoutSeconds > 0 {
+		fmt.Printf("Timeout: %ds\n", j.TimeoutSeconds)
+	}
+	if j.EnvFile != "" {
+		fmt.Printf("Env File: %s\n", j.EnvFile)
+	}
+	if j.WorkDir != "" {
+		fmt.Printf("Work Dir: %s\n", j.WorkDir)
+	}
+	fmt.Printf("Created: %s\n", formatTime(j.CreatedAt))
+	fmt.Printf("Updated: %s\n", formatTime(j.UpdatedAt))
+
+	if j.NextRetryAt != nil {
+		fmt.Printf("Next Retry: %s\n", formatTime(*j.NextRetryAt))
+	}
+
+	if j.ScheduledAt != nil {
+		fmt.Printf("Scheduled: %s\n", formatTime(*j.ScheduledAt))
+	}
+
+	if j.HeldUntil != nil {
+		fmt.Printf("Held Until: %s\n", formatTime(*j.HeldUntil))
+	}
+
+	if j.CompletedAt != nil {
+		fmt.Printf("Completed: %s\n", formatTime(*j.CompletedAt))
+	}
+
+	if j.WorkerID != "" {
+		fmt.Printf("Worker: %s\n", j.WorkerID)
+	}
+
+	if j.DurationMS > 0 {
+		fmt.Printf("Duration: %s\n", formatRunTime(j.DurationMS))
+	}
+
+	if j.CPUTimeMS > 0 || j.MaxRSSKB > 0 {
+		fmt.Printf("Resources: %s\n", worker.FormatUsage(j))
+	}
+
+	if len(j.History) > 0 {
+		fmt.Printf("Exit Code: %d\n", j.ExitCode)
+	}
+
+	if j.Error != "" {
+		fmt.Printf("Error: %s\n", j.Error)
+	}
+
+	if j.Output != "" {
+		fmt.Printf("Output: %s\n", truncateText(j.Output, getConfig().ListOutputTruncate))
+	}
+}
+
+// parseListTime parses a --since or --until value: an RFC3339 or local
+// timestamp, or a duration before now
+func parseListTime(flag, s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("%s cannot be a negative duration", flag)
+		}
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		// Compare in the same zone as the stored times
+		return t.Local(), nil
+	}
+	for _, layout := range scheduleTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid %s: %s (use an RFC3339 time, \"YYYY-MM-DD HH:MM\" or a duration such as 1h)", flag, s)
+}
+
+// parseListSort parses a --sort value such as "priority" or "updated:asc"
+// into list options. An empty value keeps the default newest-first order.
+func parseListSort(value string) (storage.ListOptions, error) {
+	var opts storage.ListOptions
+	if value == "" {
+		return opts, nil
+	}
+
+	field, dir, _ := strings.Cut(value, ":")
+	switch dir {
+	case "", "desc":
+	case "asc":
+		opts.Ascending = true
+	default:
+		return opts, fmt.Errorf("invalid sort direction: %s (valid: asc, desc)", dir)
+	}
+	opts.SortBy = field
+	return opts, opts.Validate()
+}
+
+// paginateJobs returns the page of jobs starting at offset with at most
+// limit entries (0 = no limit)
+func paginateJobs(jobs []*job.Job, limit, offset int) []*job.Job {
+	if offset >= len(jobs) {
+		return nil
+	}
+	jobs = jobs[offset:]
+	if limit > 0 && len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	return jobs
+}
+
+// filterJobsByCommand returns the jobs whose command satisfies the matcher
+func filterJobsByCommand(jobs []*job.Job, match commandMatcher) []*job.Job {
+	var filtered []*job.Job
+	for _, j := range jobs {
+		if match(j.Command) {
+			filtered = append(filtered, j)
+		}
+	}
+	return filtered
+}
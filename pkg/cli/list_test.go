@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseListTime(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"90m", now.Add(-90 * time.Minute)},
+		{"0s", now},
+		{"2024-05-01T10:00:00Z", time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+		{"2024-05-01T10:00:00+05:30", time.Date(2024, 5, 1, 4, 30, 0, 0, time.UTC)},
+		{"2024-05-01 14:00", time.Date(2024, 5, 1, 14, 0, 0, 0, time.Local)},
+		{"2024-05-01 14:00:30", time.Date(2024, 5, 1, 14, 0, 30, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		got, err := parseListTime("--since", tt.value, now)
+		if err != nil {
+			t.Errorf("%s: %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: got %s, want %s", tt.value, got, tt.want)
+		}
+		if got.Location() != time.Local {
+			t.Errorf("%s: got location %s, want Local", tt.value, got.Location())
+		}
+	}
+}
+
+func TestParseListTimeInvalid(t *testing.T) {
+	now := time.Now()
+	for _, value := range []string{"-1h", "yesterday", "2024-05-01", "14:00"} {
+		if _, err := parseListTime("--until", value, now); err == nil {
+			t.Errorf("%s: expected an error", value)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/MithileshwaranS/queuectl/internal/worker"
+	"github.com/spf13/cobra"
+)
+
+// logsPollInterval is how often 'logs --follow' checks for new output
+const logsPollInterval = 500 * time.Millisecond
+
+func logsCmd() *cobra.Command {
+	var follow bool
+
+	cmd := &cobra.Command{
+		Use:   "logs [job-id]",
+		Short: "Show the output of a running job",
+		Long: `Print the output a running job has written so far. Workers stream
+stdout and stderr of every attempt to logs/<job-id>.log in the profile
+directory (~/.queuectl for the default profile) as the command runs.
+
+With --follow the output is printed as it arrives until the job stops
+processing. For a job that is not running, the stored output of its
+last attempt is printed instead.
+
+Examples:
+  queuectl logs abc123-def456
+  queuectl logs abc123-def456 --follow`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jobID := args[0]
+
+			j, err := getStorage().GetJob(jobID)
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("job not found: %s", jobID)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to get job: %w", err)
+			}
+
+			if j.State != job.StateProcessing {
+				printStoredOutput(j)
+				return nil
+			}
+
+			path := worker.JobLogPath(jobID)
+			offset, err := printLogFrom(path, 0)
+			if err != nil {
+				return err
+			}
+			if !follow {
+				return nil
+			}
+
+			for {
+				time.Sleep(logsPollInterval)
+
+				j, err := getStorage().GetJob(jobID)
+				if err != nil {
+					return fmt.Errorf("failed to get job: %w", err)
+				}
+				// Print what the attempt wrote before it finished
+				if offset, err = printLogFrom(path, offset); err != nil {
+					return err
+				}
+				if j.State != job.StateProcessing {
+					fmt.Fprintf(os.Stderr, "\nJob %s is %s\n", jobID, j.State)
+					return nil
+				}
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "Keep printing output until the job stops processing")
+
+	return cmd
+}
+
+// printStoredOutput prints the output stored with a job's last attempt
+func printStoredOutput(j *job.Job) {
+	if j.Output == "" {
+		fmt.Fprintf(os.Stderr, "Job %s is %s and has no stored output\n", j.ID, j.State)
+		return
+	}
+	fmt.Print(j.Output)
+	if j.Output[len(j.Output)-1] != '\n' {
+		fmt.Println()
+	}
+}
+
+// printLogFrom prints the log file at path from offset on and returns the
+// offset to continue from. A missing file has nothing to print yet, and a
+// file shorter than offset was restarted by a new attempt, so it is
+// printed from the start.
+func printLogFrom(path string, offset int64) (int64, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return offset, nil
+	}
+	if err != nil {
+		return offset, fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return offset, fmt.Errorf("failed to read log file: %w", err)
+	}
+	if info.Size() < offset {
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return offset, fmt.Errorf("failed to read log file: %w", err)
+	}
+	n, err := io.Copy(os.Stdout, f)
+	if err != nil {
+		return offset + n, fmt.Errorf("failed to read log file: %w", err)
+	}
+	return offset + n, nil
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// commandMatcher reports whether a job command matches a search pattern
+type commandMatcher func(command string) bool
+
+// newCommandMatcher builds a matcher for the given pattern. In substring mode
+// the pattern matches anywhere in the command. In glob mode the pattern must
+// match the whole command, where '*' matches any run of characters (including
+// '/'), '?' matches a single character, '[...]' matches a character class and
+// a backslash escapes the following character.
+func newCommandMatcher(pattern string, glob bool) (commandMatcher, error) {
+	if !glob {
+		return func(command string) bool {
+			return strings.Contains(command, pattern)
+		}, nil
+	}
+
+	re, err := globToRegexp(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return re.MatchString, nil
+}
+
+// globToRegexp translates a glob pattern into an anchored regular expression
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch c := runes[i]; c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '\\':
+			if i+1 >= len(runes) {
+				return nil, fmt.Errorf("invalid glob %q: trailing backslash", pattern)
+			}
+			i++
+			b.WriteString(regexp.QuoteMeta(string(runes[i])))
+		case '[':
+			end := i + 1
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("invalid glob %q: unclosed '['", pattern)
+			}
+			class := string(runes[i+1 : end])
+			if class == "" {
+				return nil, fmt.Errorf("invalid glob %q: empty character class", pattern)
+			}
+			if class[0] == '!' {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i = end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+	}
+	return re, nil
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/MithileshwaranS/queuectl/internal/metrics"
+	"github.com/spf13/cobra"
+)
+
+func metricsCmd() *cobra.Command {
+	var addr string
+
+	cmd := &cobra.Command{
+		Use:   "metrics",
+		Short: "Serve Prometheus metrics for the queue",
+		Long: `Serve the number of jobs in each state at /metrics on --addr in the
+Prometheus text format until interrupted. Counts are read from the
+database on every scrape.
+
+Attempt counters (processed, failures, retries, DLQ entries) and the job
+duration histogram are only known to the worker process that ran the
+jobs; start workers with 'queuectl worker start --metrics-addr' to
+export those as well.
+
+Example:
+  queuectl metrics --addr :9090`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			srv, err := serveMetrics(addr)
+			if err != nil {
+				return err
+			}
+			defer srv.Close()
+
+			fmt.Printf("Serving metrics on http://%s/metrics (Ctrl+C to stop)\n", addr)
+
+			sigChan := make(chan os.Signal, 1)
+			signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+			<-sigChan
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&addr, "addr", ":9090", "Address to listen on")
+
+	return cmd
+}
+
+// serveMetrics registers the job state gauges and serves the Prometheus
+// registry on addr
+func serveMetrics(addr string) (*http.Server, error) {
+	metrics.RegisterJobStates(getStorage().GetJobStats)
+	return metrics.ServePrometheus(addr)
+}
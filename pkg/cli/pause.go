@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/MithileshwaranS/queuectl/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func pauseCmd() *cobra.Command {
+	var queue string
+
+	cmd := &cobra.Command{
+		Use:   "pause",
+		Short: "Stop workers from claiming new jobs",
+		Long: `Pause job processing for every queue, or only for one queue with --queue.
+
+Workers keep running and finish the jobs they are already processing,
+but claim no new jobs from a paused queue. Jobs in other queues are
+processed as usual. Use 'queuectl resume' to undo.
+
+Examples:
+  queuectl pause                  # Pause all queues
+  queuectl pause --queue batch    # Pause only the batch queue`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := queueOrAll(queue)
+			if err := getStorage().PauseQueue(name); err != nil {
+				return err
+			}
+
+			fmt.Printf("✓ Paused %s\n", describeQueue(name))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&queue, "queue", "q", "", "Pause only this queue")
+
+	return cmd
+}
+
+func resumeCmd() *cobra.Command {
+	var queue string
+
+	cmd := &cobra.Command{
+		Use:   "resume",
+		Short: "Resume claiming jobs after a pause",
+		Long: `Resume job processing paused with 'queuectl pause'.
+
+Without --queue this lifts a pause of all queues; queues paused
+individually stay paused until resumed with --queue.
+
+Examples:
+  queuectl resume                 # Lift the pause of all queues
+  queuectl resume --queue batch   # Resume the batch queue`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := queueOrAll(queue)
+			if err := getStorage().ResumeQueue(name); err != nil {
+				return err
+			}
+
+			fmt.Printf("✓ Resumed %s\n", describeQueue(name))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&queue, "queue", "q", "", "Resume only this queue")
+
+	return cmd
+}
+
+// queueOrAll maps an empty --queue flag to every queue
+func queueOrAll(queue string) string {
+	if queue == "" {
+		return storage.AllQueues
+	}
+	return queue
+}
+
+// describeQueue renders a paused queue name for display
+func describeQueue(queue string) string {
+	if queue == storage.AllQueues {
+		return "all queues"
+	}
+	return fmt.Sprintf("queue %q", queue)
+}
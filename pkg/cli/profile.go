@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MithileshwaranS/queuectl/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// profileFlag holds the value of the global --profile flag. It is applied
+// by ProfileFromArgs before the config is loaded; the flag is registered
+// so cobra accepts it.
+var profileFlag string
+
+func configProfileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage named configuration profiles",
+		Long: `Keep separate configurations, e.g. for dev, staging and prod queues.
+
+Each profile has its own config file and default database under
+~/.queuectl/profiles/<name>. The "default" profile is the configuration
+directly in ~/.queuectl. 'use' selects the profile for later commands;
+the global --profile flag selects one for a single invocation.
+
+Examples:
+  queuectl config profile create staging
+  queuectl --profile staging config set worker-count 4
+  queuectl config profile use staging
+  queuectl config profile use default`,
+	}
+
+	cmd.AddCommand(configProfileCreateCmd())
+	cmd.AddCommand(configProfileUseCmd())
+	cmd.AddCommand(configProfileListCmd())
+
+	return cmd
+}
+
+func configProfileCreateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "create [name]",
+		Short: "Create a profile from a copy of the current configuration",
+		Long: `Create a profile whose settings are copied from the active profile,
+except db-path, which points at a new database in the profile's own
+directory. The active profile is not changed.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := config.CreateProfile(args[0])
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("✓ Profile %s created from %s\n", args[0], config.ActiveProfile())
+			fmt.Printf("  Config file: %s\n", path)
+			fmt.Printf("  Switch to it with 'queuectl config profile use %s'\n", args[0])
+			return nil
+		},
+	}
+}
+
+func configProfileUseCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "use [name]",
+		Short: "Make a profile active for later commands",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.UseProfile(args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("✓ Now using profile %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+func configProfileListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List profiles, marking the active one",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names, err := config.ListProfiles()
+			if err != nil {
+				return err
+			}
+
+			active := config.ActiveProfile()
+			for _, name := range names {
+				marker := " "
+				if name == active {
+					marker = "*"
+				}
+				fmt.Printf("%s %s\n", marker, name)
+			}
+			return nil
+		},
+	}
+}
+
+// ProfileFromArgs returns the value of a --profile flag in args, which
+// must be known before the configuration is loaded
+func ProfileFromArgs(args []string) string {
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			return ""
+		case arg == "--profile" && i+1 < len(args):
+			return args[i+1]
+		case strings.HasPrefix(arg, "--profile="):
+			return strings.TrimPrefix(arg, "--profile=")
+		}
+	}
+	return ""
+}
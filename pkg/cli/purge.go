@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/MithileshwaranS/queuectl/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func purgeCmd() *cobra.Command {
+	var state string
+	var olderThan string
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "purge",
+		Short: "Bulk-delete jobs in a state that are older than a given age",
+		Long: `Delete every job in the given state whose last update is older than
+--older-than. Ages accept Go durations (90m, 36h) and days (7d).
+
+Without --force nothing is deleted; the command reports how many jobs
+would be. Processing jobs cannot be purged since a worker owns them.
+
+Warning: Deleted jobs cannot be recovered.
+
+Examples:
+  queuectl purge --state completed --older-than 7d
+  queuectl purge --state completed --older-than 7d --force
+  queuectl purge --state dead --older-than 30d --force`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if state == "" {
+				return fmt.Errorf("--state is required")
+			}
+			if olderThan == "" {
+				return fmt.Errorf("--older-than is required")
+			}
+
+			s := job.State(state)
+			if !isJobState(s) {
+				return fmt.Errorf("invalid state: %s (valid: pending, processing, completed, failed, dead, held, archived, cancelled)", state)
+			}
+			if s == job.StateProcessing {
+				return fmt.Errorf("processing jobs cannot be purged (use 'queuectl cancel' to stop them)")
+			}
+
+			age, err := parseAge(olderThan)
+			if err != nil {
+				return err
+			}
+			cutoff := time.Now().Add(-age)
+
+			if !force {
+				jobs, err := getStorage().ListJobs(s, storage.ListOptions{})
+				if err != nil {
+					return fmt.Errorf("failed to list jobs: %w", err)
+				}
+				count := 0
+				for _, j := range jobs {
+					if j.UpdatedAt.Unix() < cutoff.Unix() {
+						count++
+					}
+				}
+				fmt.Printf("Dry run: %d %s job(s) older than %s would be deleted\n", count, s, olderThan)
+				if count > 0 {
+					fmt.Println("Run again with --force to delete them")
+				}
+				return nil
+			}
+
+			n, err := getStorage().DeleteJobsByState(s, cutoff)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("✓ Purged %d %s job(s) older than %s\n", n, s, olderThan)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&state, "state", "", "State of the jobs to delete")
+	cmd.Flags().StringVar(&olderThan, "older-than", "", "Only delete jobs last updated longer ago than this (e.g. 7d, 12h)")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Delete the jobs instead of only counting them")
+
+	return cmd
+}
+
+// isJobState reports whether s is one of job.AllStates
+func isJobState(s job.State) bool {
+	for _, state := range job.AllStates {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAge parses a non-negative Go duration, also accepting a whole
+// number of days such as "7d"
+func parseAge(s string) (time.Duration, error) {
+	var d time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid age: %s (use a duration such as 12h or a number of days such as 7d)", s)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		parsed, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid age: %s (use a duration such as 12h or a number of days such as 7d)", s)
+		}
+		d = parsed
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("age cannot be negative: %s", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func queueCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "queue",
+		Short: "Inspect the job queue",
+		Long:  `Commands for inspecting how queued jobs will be scheduled.`,
+	}
+
+	cmd.AddCommand(queuePreviewCmd())
+
+	return cmd
+}
+
+func queuePreviewCmd() *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "preview",
+		Short: "Show the order in which jobs will be claimed",
+		Long: `List claimable jobs in the exact order workers will claim them, without
+claiming anything.
+
+This uses the same selection and ordering as the workers: jobs that are
+pending (and past any scheduled time) or failed and due for retry, in
+unpaused queues, ordered by effective priority, then creation time, then
+enqueue order. Effective priority is the job's priority plus the aging
+boost from age-priority-boost. Held jobs whose hold has expired are
+included since the next claim releases them.
+
+When queue weights are configured, workers first pick a queue by
+weighted round-robin and then take its first job in this order, so the
+list shows each queue's order but not how claims interleave between
+queues.
+
+Example:
+  queuectl queue preview --limit 20`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if limit < 1 {
+				return fmt.Errorf("--limit must be at least 1")
+			}
+
+			candidates, err := getStorage().PreviewClaimOrder(limit)
+			if err != nil {
+				return err
+			}
+
+			if len(candidates) == 0 {
+				fmt.Println("No jobs are ready to be claimed")
+				return nil
+			}
+
+			fmt.Printf("=== Claim Order (next %d) ===\n\n", len(candidates))
+			fmt.Printf("%-4s %-36s %-10s %8s %8s %8s  %s\n", "#", "JOB ID", "STATE", "PRIORITY", "EFFECT.", "AGE", "COMMAND")
+			for i, c := range candidates {
+				j := c.Job
+				age := time.Since(j.CreatedAt).Round(time.Second)
+				fmt.Printf("%-4d %-36s %-10s %8d %8d %8s  %s\n",
+					i+1, j.ID, j.State, j.Priority, c.EffectivePriority, age, j.Command)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "Maximum number of jobs to show")
+
+	return cmd
+}
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/MithileshwaranS/queuectl/internal/worker"
+	"github.com/spf13/cobra"
+)
+
+func reproduceCmd() *cobra.Command {
+	var run bool
+
+	cmd := &cobra.Command{
+		Use:   "reproduce [job-id]",
+		Short: "Show or re-run a job's command exactly as a worker ran it",
+		Long: `Print how a worker executes the given job: the shell wrapper, the
+command of its last attempt (the fallback command if that is what ran),
+the extra environment from env_file, env and parent output, the working
+directory and the timeout, followed by an equivalent shell command line.
+
+With --run the command is executed inline in the same way, with its
+output streamed to the terminal, and the result is checked against the
+job's success_pattern and failure_pattern. The stored job is never
+modified, so this is safe to use on DLQ jobs.
+
+Examples:
+  queuectl reproduce abc123-def456
+  queuectl reproduce abc123-def456 --run`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			j, err := getStorage().GetJob(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to get job: %w", err)
+			}
+
+			command := lastCommand(j)
+			env, err := worker.JobEnv(j)
+			if err != nil {
+				return fmt.Errorf("failed to build job environment: %w", err)
+			}
+
+			fmt.Printf("=== Reproduce job %s ===\n", j.ID)
+			fmt.Println()
+			fmt.Printf("Command: %s\n", command)
+			fmt.Println("Shell:   sh -c")
+			fmt.Printf("Timeout: %s\n", worker.JobTimeout(j, getConfig()))
+			if j.Sandbox {
+				fmt.Println("Dir:     a fresh temporary directory (sandbox), exported as QUEUECTL_SANDBOX")
+			} else if j.WorkDir != "" {
+				fmt.Printf("Dir:     %s\n", j.WorkDir)
+			} else {
+				fmt.Println("Dir:     the worker's working directory")
+			}
+			if len(env) > 0 {
+				fmt.Println("Env:")
+				for _, kv := range env {
+					// In full: the point is to run the job exactly as a worker would
+					fmt.Printf("  %s\n", kv)
+				}
+			}
+			fmt.Println()
+			fmt.Println("Shell equivalent:")
+			fmt.Printf("  %s\n", shellEquivalent(j, command, env))
+
+			if !run {
+				return nil
+			}
+
+			fmt.Println()
+			fmt.Println("=== Running ===")
+			return reproduceRun(j, command)
+		},
+	}
+
+	cmd.Flags().BoolVar(&run, "run", false, "Execute the command inline (the stored job is not changed)")
+
+	return cmd
+}
+
+// lastCommand returns the command of the job's most recent attempt, or the
+// command its next attempt would run if it has not run yet
+func lastCommand(j *job.Job) string {
+	if len(j.History) > 0 {
+		return j.History[len(j.History)-1].Command
+	}
+	return j.CommandForAttempt()
+}
+
+// reproduceRun executes command the way a worker would and reports the
+// outcome without touching the stored job
+func reproduceRun(j *job.Job, command string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), worker.JobTimeout(j, getConfig()))
+	defer cancel()
+
+	c, cleanup, err := worker.JobCommand(ctx, j, command)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	var stdout, stderr bytes.Buffer
+	c.Stdout = io.MultiWriter(os.Stdout, &stdout)
+	c.Stderr = io.MultiWriter(os.Stderr, &stderr)
+
+	start := time.Now()
+	err = c.Run()
+	duration := time.Since(start)
+
+	fmt.Println()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("command timed out after %s", worker.JobTimeout(j, getConfig()))
+	}
+	if err != nil {
+		return fmt.Errorf("command failed after %.2fs: %w", duration.Seconds(), err)
+	}
+
+	output := stdout.String()
+	if stderr.Len() > 0 {
+		output += "\nSTDERR:\n" + stderr.String()
+	}
+	if err := j.CheckOutput(output); err != nil {
+		return fmt.Errorf("command exited 0 after %.2fs but a worker would fail it: %w", duration.Seconds(), err)
+	}
+
+	fmt.Printf("✓ Command succeeded (%.2fs)\n", duration.Seconds())
+	return nil
+}
+
+// shellEquivalent renders a command line that runs command with the job's
+// environment and working directory from an interactive shell
+func shellEquivalent(j *job.Job, command string, env []string) string {
+	var parts []string
+	if j.Sandbox {
+		parts = append(parts, `cd "$(mktemp -d)" &&`)
+	} else if j.WorkDir != "" {
+		parts = append(parts, "cd", shellQuote(j.WorkDir), "&&")
+	}
+	if len(env) > 0 || j.Sandbox {
+		parts = append(parts, "env")
+		for _, kv := range env {
+			parts = append(parts, shellQuote(kv))
+		}
+		if j.Sandbox {
+			parts = append(parts, `QUEUECTL_SANDBOX="$PWD"`)
+		}
+	}
+	parts = append(parts, "sh", "-c", shellQuote(command))
+	return strings.Join(parts, " ")
+}
+
+// shellQuote quotes s as a single POSIX shell word
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/MithileshwaranS/queuectl/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func requeueStuckCmd() *cobra.Command {
+	var olderThan string
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "requeue-stuck",
+		Short: "Return processing jobs left behind by dead workers to pending",
+		Long: `Return every processing job whose last update is older than
+--older-than to pending, clearing its worker, e.g. after an outage killed
+the workers running them. Attempts are kept and the reason is recorded in
+the job's error. This is what worker pools do on their own when
+stale-job-threshold is set.
+
+Without --force nothing is changed; the command lists the jobs that would
+be requeued. Ages accept Go durations (90m, 36h) and days (7d).
+
+Workers only refresh a running job's update time when stale-job-threshold
+is set. Without it, a job that has simply been running for longer than
+--older-than also counts as stuck, so --force is refused; it is also
+refused for an --older-than below stale-job-threshold, which live jobs can
+reach between heartbeats. A worker still running a requeued job drops its
+result when it finishes.
+
+Examples:
+  queuectl requeue-stuck
+  queuectl requeue-stuck --older-than 1h --force`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			age, err := parseAge(olderThan)
+			if err != nil {
+				return err
+			}
+
+			if !force {
+				jobs, err := getStorage().ListJobs(job.StateProcessing, storage.ListOptions{})
+				if err != nil {
+					return fmt.Errorf("failed to list jobs: %w", err)
+				}
+				cutoff := time.Now().Add(-age)
+				var stuck []*job.Job
+				for _, j := range jobs {
+					if j.UpdatedAt.Unix() <= cutoff.Unix() {
+						stuck = append(stuck, j)
+					}
+				}
+				fmt.Printf("Dry run: %d processing job(s) not updated for %s would be requeued\n", len(stuck), olderThan)
+				for _, j := range stuck {
+					fmt.Printf("  • %s (worker %s, updated %s) %s\n", j.ID, j.WorkerID, formatTime(j.UpdatedAt), j.Command)
+				}
+				if len(stuck) > 0 {
+					fmt.Println("Run again with --force to requeue them")
+				}
+				return nil
+			}
+
+			// Only missed heartbeats tell a dead worker's job from one
+			// that is still running
+			threshold := getConfig().StaleJobThreshold
+			if threshold <= 0 {
+				return fmt.Errorf("--force needs stale-job-threshold set, so running jobs are told apart by their heartbeats (see 'queuectl config set stale-job-threshold')")
+			}
+			if age < threshold {
+				return fmt.Errorf("--older-than %s is below stale-job-threshold (%s); running jobs may not have sent a heartbeat yet", olderThan, threshold)
+			}
+
+			jobs, err := getStorage().RecoverStaleJobs(age)
+			if err != nil {
+				return fmt.Errorf("failed to requeue stuck jobs: %w", err)
+			}
+			for _, j := range jobs {
+				fmt.Printf("  • %s (was on worker %s)\n", j.ID, j.WorkerID)
+			}
+			fmt.Printf("✓ Requeued %d stuck job(s)\n", len(jobs))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&olderThan, "older-than", "10m", "Only requeue jobs last updated longer ago than this (e.g. 30m, 1d)")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Requeue the jobs instead of only listing them")
+
+	return cmd
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package cli
+
+import "os"
+
+// notifyResize does nothing where terminals do not signal resizes; the
+// next redraw picks up the new size
+func notifyResize(c chan<- os.Signal) {}
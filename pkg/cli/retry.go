@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/spf13/cobra"
+)
+
+func retryCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "retry [job-id]",
+		Short: "Retry a failed, completed or dead job now",
+		Long: `Run a job again without waiting.
+
+A failed job waiting for its backoff is made due immediately, keeping
+its attempt count, so the next worker poll picks it up. A completed or
+dead job is reset to pending with its attempts cleared, as
+'queuectl dlq retry' does for dead jobs.
+
+Example:
+  queuectl retry abc123-def456`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jobID := args[0]
+
+			j, err := getStorage().GetJob(jobID)
+			if err != nil {
+				return fmt.Errorf("failed to get job: %w", err)
+			}
+
+			from := j.State
+			switch from {
+			case job.StateFailed, job.StateCompleted, job.StateDead:
+			default:
+				return fmt.Errorf("job %s cannot be retried (state: %s)", jobID, from)
+			}
+
+			if dryRun {
+				printDryRun("retried", []*job.Job{j})
+				return nil
+			}
+
+			if from == job.StateFailed {
+				j.RetryNow()
+			} else {
+				j.ResetForRetry()
+			}
+			// Only update the job if no worker has claimed it since it was read
+			if err := getStorage().SaveJobIfState(j, from); err != nil {
+				return fmt.Errorf("failed to retry job: %w", err)
+			}
+
+			if from == job.StateFailed {
+				fmt.Printf("✓ Job %s (failed, attempt %d/%d) is due for retry now\n", jobID, j.Attempts, j.MaxRetries)
+			} else {
+				fmt.Printf("✓ Job %s moved from %s to pending\n", jobID, from)
+			}
+			return nil
+		},
+	}
+
+	addDryRunFlag(cmd, &dryRun)
+
+	return cmd
+}
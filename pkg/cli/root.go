@@ -2,9 +2,11 @@ package cli
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/MithileshwaranS/queuectl/internal/config"
 	"github.com/MithileshwaranS/queuectl/internal/storage"
+	"github.com/MithileshwaranS/queuectl/internal/version"
 	"github.com/spf13/cobra"
 )
 
@@ -20,7 +22,7 @@ func Execute(c *config.Config) error {
 
 	// Initialize storage
 	var err error
-	store, err = storage.NewSQLiteStorage(cfg.DBPath)
+	store, err = openStorage()
 	if err != nil {
 		return fmt.Errorf("failed to initialize storage: %w", err)
 	}
@@ -36,20 +38,88 @@ func Execute(c *config.Config) error {
 		Long: `QueueCTL is a production-grade job queue system that manages 
 background jobs with worker processes, retries with exponential backoff,
 and a Dead Letter Queue (DLQ) for permanently failed jobs.`,
-		Version: "1.0.0",
+		Version: version.Get().String(),
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutputFormat(outputFormat); err != nil {
+				return err
+			}
+			// A bad time_format in the config is already replaced by the
+			// default when it is loaded, so only the flag is checked here
+			if timeFormatFlag != "" {
+				return validateTimeFormat(timeFormatFlag)
+			}
+			return nil
+		},
 	}
 
+	rootCmd.SetVersionTemplate("queuectl {{.Version}}\n")
+
+	// Global flags
+	rootCmd.PersistentFlags().StringVar(&timeFormatFlag, "time-format", "", "Timestamp format: local, utc, rfc3339, unix, relative (default from config)")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputText, "Output format for list, status and describe: text, json")
+	rootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "Configuration profile to use for this command (default: the active profile)")
+
 	// Add all subcommands
 	rootCmd.AddCommand(enqueueCmd())
 	rootCmd.AddCommand(workerCmd())
 	rootCmd.AddCommand(statusCmd())
 	rootCmd.AddCommand(listCmd())
+	rootCmd.AddCommand(describeCmd())
+	rootCmd.AddCommand(logsCmd())
+	rootCmd.AddCommand(searchCmd())
 	rootCmd.AddCommand(dlqCmd())
+	rootCmd.AddCommand(killCmd())
+	rootCmd.AddCommand(cancelCmd())
+	rootCmd.AddCommand(deleteCmd())
+	rootCmd.AddCommand(retryCmd())
+	rootCmd.AddCommand(purgeCmd())
+	rootCmd.AddCommand(requeueStuckCmd())
+	rootCmd.AddCommand(holdCmd())
+	rootCmd.AddCommand(scheduleCmd())
+	rootCmd.AddCommand(chainCmd())
+	rootCmd.AddCommand(reproduceCmd())
+	rootCmd.AddCommand(queueCmd())
+	rootCmd.AddCommand(pauseCmd())
+	rootCmd.AddCommand(resumeCmd())
+	rootCmd.AddCommand(throughputCmd())
+	rootCmd.AddCommand(statsCmd())
+	rootCmd.AddCommand(metricsCmd())
+	rootCmd.AddCommand(etaCmd())
+	rootCmd.AddCommand(topCmd())
+	rootCmd.AddCommand(benchCmd())
 	rootCmd.AddCommand(configCmd())
+	rootCmd.AddCommand(transferCmd())
+	rootCmd.AddCommand(exportCmd())
+	rootCmd.AddCommand(importCmd())
+	rootCmd.AddCommand(dbCmd())
+	rootCmd.AddCommand(debugCmd())
+	rootCmd.AddCommand(versionCmd())
 
 	return rootCmd.Execute()
 }
 
+// openStorage opens the configured database
+func openStorage() (storage.Storage, error) {
+	if storage.IsRedisURL(cfg.DBPath) {
+		redisStore, err := storage.NewRedisStorage(cfg.DBPath)
+		if err != nil {
+			return nil, err
+		}
+		redisStore.SetAgePriorityBoost(time.Duration(cfg.AgePriorityBoost) * time.Minute)
+		redisStore.SetQueueWeights(cfg.QueueWeights())
+		return redisStore, nil
+	}
+
+	sqliteStore, err := storage.NewSQLiteStorage(cfg.DBPath)
+	if err != nil {
+		return nil, err
+	}
+	sqliteStore.SetAgePriorityBoost(time.Duration(cfg.AgePriorityBoost) * time.Minute)
+	sqliteStore.SetQueueWeights(cfg.QueueWeights())
+	sqliteStore.SetBusyRetries(cfg.DBBusyRetries)
+	return sqliteStore, nil
+}
+
 // getStorage returns the storage instance
 func getStorage() storage.Storage {
 	return store
@@ -58,4 +128,4 @@ func getStorage() storage.Storage {
 // getConfig returns the config instance
 func getConfig() *config.Config {
 	return cfg
-}
\ No newline at end of file
+}
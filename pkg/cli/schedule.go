@@ -0,0 +1,368 @@
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/MithileshwaranS/queuectl/internal/storage"
+	"github.com/MithileshwaranS/queuectl/internal/worker"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+// scheduleTimeLayouts are the absolute formats accepted by --at, besides RFC3339
+var scheduleTimeLayouts = []string{displayTimeLayout, "2006-01-02 15:04"}
+
+func scheduleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "Manage recurring jobs and jobs scheduled to run later",
+		Long: `Commands for recurring cron schedules, and for pending jobs whose
+scheduled_at is in the future, such as jobs enqueued with scheduled_at,
+DLQ retries with --delay or --rate, and next_job follow-ups with
+depends_delay_seconds. Workers do not claim these jobs until their
+scheduled time.
+
+Recurring schedules are fired by running worker pools: each time a
+schedule's cron expression fires, a fresh job is enqueued.`,
+	}
+
+	cmd.AddCommand(scheduleAddCmd())
+	cmd.AddCommand(scheduleRemoveCmd())
+	cmd.AddCommand(scheduleListCmd())
+	cmd.AddCommand(scheduleCancelCmd())
+	cmd.AddCommand(scheduleRescheduleCmd())
+
+	return cmd
+}
+
+func scheduleAddCmd() *cobra.Command {
+	var cronExpr, command, queue, id string
+
+	cmd := &cobra.Command{
+		Use:   "add --cron <expr> --command <cmd>",
+		Short: "Add a recurring job enqueued on a cron schedule",
+		Long: `Add a schedule that enqueues a fresh job running --command each time
+the cron expression fires.
+
+--cron takes the five standard fields (minute, hour, day of month, month,
+day of week) in local time, or a descriptor such as @hourly, @daily or
+@every 30m. Prefix it with CRON_TZ=<zone> to use another time zone.
+
+Schedules are fired by running worker pools ('queuectl worker start'),
+checked every few seconds. Each fire is enqueued once, with the job ID
+<schedule-id>-<fire time in UTC>, even if several pools run or one
+restarts. Fires missed while no pool was running are caught up with a
+single job. The job gets the queue's defaults (max_retries,
+timeout_seconds, backoff_base) and the tag schedule:<schedule-id>.
+
+Examples:
+  queuectl schedule add --cron "0 3 * * *" --command "backup.sh"
+  queuectl schedule add --id nightly-report --cron "@daily" --command "./report.sh" --queue reports`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cronExpr == "" || command == "" {
+				return fmt.Errorf("--cron and --command are required")
+			}
+			if _, err := worker.ParseCron(cronExpr); err != nil {
+				return err
+			}
+			if id == "" {
+				id = uuid.New().String()[:8]
+			}
+			if queue == "" {
+				queue = job.DefaultQueue
+			}
+
+			sc := &storage.Schedule{
+				ID:        id,
+				Cron:      cronExpr,
+				Command:   command,
+				Queue:     queue,
+				CreatedAt: time.Now(),
+			}
+			schema, err := loadJobSchema()
+			if err != nil {
+				return err
+			}
+			if schema != nil {
+				if err := schema.Validate(worker.ScheduleSpec(sc)); err != nil {
+					return err
+				}
+			}
+			if err := getStorage().AddSchedule(sc); err != nil {
+				if errors.Is(err, storage.ErrScheduleExists) {
+					return fmt.Errorf("schedule %s already exists", id)
+				}
+				return err
+			}
+
+			next, _ := worker.NextFire(sc)
+			fmt.Printf("✓ Schedule %s added\n", id)
+			fmt.Printf("  Cron: %s\n", sc.Cron)
+			fmt.Printf("  Command: %s\n", sc.Command)
+			fmt.Printf("  Queue: %s\n", sc.Queue)
+			fmt.Printf("  Next Run: %s\n", formatTime(next))
+			if len(getActiveWorkers()) == 0 {
+				fmt.Println("  Note: no workers are running; schedules only fire while one is")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&cronExpr, "cron", "", `Cron expression, e.g. "0 3 * * *" or @hourly`)
+	cmd.Flags().StringVar(&command, "command", "", "Shell command each enqueued job runs")
+	cmd.Flags().StringVarP(&queue, "queue", "q", "", "Queue the jobs are enqueued in (default \"default\")")
+	cmd.Flags().StringVar(&id, "id", "", "Schedule ID (default: generated)")
+
+	return cmd
+}
+
+func scheduleRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove [schedule-id]",
+		Short: "Remove a recurring schedule",
+		Long: `Remove a recurring schedule so it enqueues no more jobs. Jobs it has
+already enqueued are left alone.
+
+Example:
+  queuectl schedule remove nightly-report`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := getStorage().DeleteSchedule(args[0]); err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return fmt.Errorf("schedule %s not found", args[0])
+				}
+				return err
+			}
+			fmt.Printf("✓ Schedule %s removed\n", args[0])
+			return nil
+		},
+	}
+}
+
+func scheduleListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List recurring schedules and scheduled jobs in the order they will run",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := printSchedules(); err != nil {
+				return err
+			}
+
+			jobs, err := getStorage().ListJobs(job.StatePending, storage.ListOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to list jobs: %w", err)
+			}
+
+			now := time.Now()
+			var scheduled []*job.Job
+			for _, j := range jobs {
+				if isScheduled(j, now) {
+					scheduled = append(scheduled, j)
+				}
+			}
+			sort.SliceStable(scheduled, func(a, b int) bool {
+				return scheduled[a].ScheduledAt.Before(*scheduled[b].ScheduledAt)
+			})
+
+			fmt.Println("=== Scheduled Jobs ===")
+			fmt.Println()
+			if len(scheduled) == 0 {
+				fmt.Println("No scheduled jobs")
+				return nil
+			}
+
+			runAts := make([]string, len(scheduled))
+			width := len("Run At")
+			for i, j := range scheduled {
+				runAts[i] = formatTime(*j.ScheduledAt)
+				if len(runAts[i]) > width {
+					width = len(runAts[i])
+				}
+			}
+
+			fmt.Printf("%-36s %-*s %12s  %-10s %s\n", "Job ID", width, "Run At", "In", "Queue", "Command")
+			for i, j := range scheduled {
+				until := j.ScheduledAt.Sub(now).Round(time.Second)
+				fmt.Printf("%-36s %-*s %12s  %-10s %s\n", j.ID, width, runAts[i], until, j.Queue, firstLine(j.Command))
+			}
+			fmt.Printf("\nTotal: %d scheduled job(s)\n", len(scheduled))
+
+			return nil
+		},
+	}
+}
+
+func scheduleCancelCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "cancel [job-id]",
+		Short: "Delete a scheduled job before it runs",
+		Long: `Delete a pending job that is scheduled to run in the future.
+
+The job is removed without a trace; use 'queuectl kill' instead to keep
+it in the DLQ with a reason.
+
+Example:
+  queuectl schedule cancel abc123-def456`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jobID := args[0]
+
+			j, err := getStorage().GetJob(jobID)
+			if err != nil {
+				return fmt.Errorf("failed to get job: %w", err)
+			}
+			if !isScheduled(j, time.Now()) {
+				return fmt.Errorf("job %s is not scheduled (state: %s)", jobID, j.State)
+			}
+
+			if dryRun {
+				printDryRun("cancelled", []*job.Job{j})
+				return nil
+			}
+
+			if err := getStorage().DeleteJob(jobID); err != nil {
+				return fmt.Errorf("failed to cancel job: %w", err)
+			}
+
+			fmt.Printf("✓ Scheduled job %s cancelled\n", jobID)
+			return nil
+		},
+	}
+
+	addDryRunFlag(cmd, &dryRun)
+
+	return cmd
+}
+
+func scheduleRescheduleCmd() *cobra.Command {
+	var at string
+
+	cmd := &cobra.Command{
+		Use:   "reschedule [job-id]",
+		Short: "Change when a pending job runs",
+		Long: `Set the time a pending job becomes claimable. Jobs that are not yet
+scheduled can be deferred this way too.
+
+--at accepts an RFC3339 timestamp, a local "YYYY-MM-DD HH:MM[:SS]" time,
+a duration from now such as 30m, or "now" to make the job claimable
+immediately.
+
+Examples:
+  queuectl schedule reschedule abc123-def456 --at 2h
+  queuectl schedule reschedule abc123-def456 --at "2026-01-02 03:00"
+  queuectl schedule reschedule abc123-def456 --at now`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jobID := args[0]
+
+			if at == "" {
+				return fmt.Errorf("--at is required")
+			}
+			runAt, err := parseScheduleTime(at, time.Now())
+			if err != nil {
+				return err
+			}
+
+			j, err := getStorage().GetJob(jobID)
+			if err != nil {
+				return fmt.Errorf("failed to get job: %w", err)
+			}
+
+			// Only a job that is still pending is rescheduled, so one a
+			// worker claims meanwhile is not put back in the queue
+			j.ScheduledAt = runAt
+			j.UpdatedAt = time.Now()
+			if err := getStorage().SaveJobIfState(j, job.StatePending); err != nil {
+				return fmt.Errorf("failed to reschedule job: %w", err)
+			}
+
+			fmt.Printf("✓ Job %s rescheduled\n", jobID)
+			if runAt != nil {
+				fmt.Printf("  Runs at: %s\n", formatTime(*runAt))
+			} else {
+				fmt.Println("  Runs at: now")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&at, "at", "", `When the job should run (timestamp, duration from now, or "now")`)
+
+	return cmd
+}
+
+// printSchedules lists the recurring schedules by their next run, if there
+// are any
+func printSchedules() error {
+	schedules, err := getStorage().ListSchedules()
+	if err != nil {
+		return fmt.Errorf("failed to list schedules: %w", err)
+	}
+	if len(schedules) == 0 {
+		return nil
+	}
+
+	next := make(map[string]time.Time, len(schedules))
+	for _, sc := range schedules {
+		next[sc.ID], _ = worker.NextFire(sc)
+	}
+	sort.SliceStable(schedules, func(a, b int) bool {
+		return next[schedules[a].ID].Before(next[schedules[b].ID])
+	})
+
+	fmt.Println("=== Recurring Schedules ===")
+	fmt.Println()
+	fmt.Printf("%-16s %-16s %-20s %-20s %-10s %s\n", "Schedule ID", "Cron", "Next Run", "Last Run", "Queue", "Command")
+	for _, sc := range schedules {
+		last := "never"
+		if sc.LastFiredAt != nil {
+			last = formatTime(*sc.LastFiredAt)
+		}
+		fmt.Printf("%-16s %-16s %-20s %-20s %-10s %s\n", sc.ID, sc.Cron, formatTime(next[sc.ID]), last, sc.Queue, firstLine(sc.Command))
+	}
+	fmt.Printf("\nTotal: %d schedule(s)\n\n", len(schedules))
+	return nil
+}
+
+// isScheduled reports whether j is a pending job waiting for a future
+// scheduled_at
+func isScheduled(j *job.Job, now time.Time) bool {
+	return j.State == job.StatePending && j.ScheduledAt != nil && j.ScheduledAt.After(now)
+}
+
+// parseScheduleTime parses a --at value. It returns nil for "now", and an
+// error for times that are not in the future.
+func parseScheduleTime(s string, now time.Time) (*time.Time, error) {
+	if s == "now" {
+		return nil, nil
+	}
+
+	var t time.Time
+	if d, err := time.ParseDuration(s); err == nil {
+		t = now.Add(d)
+	} else if t, err = time.Parse(time.RFC3339, s); err != nil {
+		parsed := false
+		for _, layout := range scheduleTimeLayouts {
+			if t, err = time.ParseInLocation(layout, s, time.Local); err == nil {
+				parsed = true
+				break
+			}
+		}
+		if !parsed {
+			return nil, fmt.Errorf("invalid --at: %s (use an RFC3339 time, \"YYYY-MM-DD HH:MM\", a duration or \"now\")", s)
+		}
+	}
+
+	if !t.After(now) {
+		return nil, fmt.Errorf("--at must be in the future (use \"now\" to run the job immediately)")
+	}
+	return &t, nil
+}
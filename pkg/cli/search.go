@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MithileshwaranS/queuectl/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func searchCmd() *cobra.Command {
+	var glob bool
+
+	cmd := &cobra.Command{
+		Use:   "search [pattern]",
+		Short: "Search jobs by command",
+		Long: `Search all jobs whose command matches a pattern.
+
+By default the pattern matches anywhere in the command (substring).
+With --glob the pattern must match the whole command:
+  *       matches any sequence of characters (including '/')
+  ?       matches any single character
+  [abc]   matches one character in the class ([!abc] negates it)
+  \x      matches the character x literally (e.g. \* or \?)
+
+Quote glob patterns so your shell doesn't expand them.
+
+Examples:
+  queuectl search backup
+  queuectl search 'backup-*' --glob
+  queuectl search '*.sh' --glob`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			matcher, err := newCommandMatcher(args[0], glob)
+			if err != nil {
+				return err
+			}
+
+			jobs, err := getStorage().ListJobs("", storage.ListOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to list jobs: %w", err)
+			}
+
+			jobs = filterJobsByCommand(jobs, matcher)
+			if len(jobs) == 0 {
+				fmt.Printf("No jobs found matching: %s\n", args[0])
+				return nil
+			}
+
+			fmt.Printf("=== Jobs matching %q ===\n\n", args[0])
+
+			for i, j := range jobs {
+				if i > 0 {
+					fmt.Println(strings.Repeat("-", 60))
+				}
+
+				printJob(j)
+				fmt.Println()
+			}
+
+			fmt.Printf("Total: %d job(s)\n", len(jobs))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&glob, "glob", "g", false, "Treat the pattern as a glob instead of a substring")
+
+	return cmd
+}
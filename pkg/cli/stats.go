@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/MithileshwaranS/queuectl/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// historyStates are the columns shown by 'stats history'
+var historyStates = []job.State{
+	job.StatePending,
+	job.StateProcessing,
+	job.StateCompleted,
+	job.StateFailed,
+	job.StateDead,
+	job.StateHeld,
+	job.StateArchived,
+	job.StateCancelled,
+}
+
+func statsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show recorded job statistics",
+		Long:  `Commands for inspecting job statistics recorded over time.`,
+	}
+
+	cmd.AddCommand(statsHistoryCmd())
+	cmd.AddCommand(statsResourcesCmd())
+
+	return cmd
+}
+
+func statsHistoryCmd() *cobra.Command {
+	var since time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show how job counts by state changed over time",
+		Long: `Show the job counts by state sampled by running workers.
+
+Sampling is opt-in: set stats-interval (e.g. 5m) and every 'worker start'
+records the count of each state at that interval. The last line shows
+how each count changed over the period, e.g. to spot a growing DLQ or
+a rising pending backlog. Samples older than stats-retention (default
+7 days) are deleted as new ones are recorded.
+
+Examples:
+  queuectl config set stats-interval 5m
+  queuectl stats history               # Last 24 hours
+  queuectl stats history --since 168h  # Last week`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if since <= 0 {
+				return fmt.Errorf("--since must be positive")
+			}
+
+			samples, err := getStorage().GetStatsHistory(time.Now().Add(-since))
+			if err != nil {
+				return fmt.Errorf("failed to get stats history: %w", err)
+			}
+
+			fmt.Printf("=== Job Count History (last %s) ===\n\n", since)
+			if len(samples) == 0 {
+				if getConfig().StatsInterval == 0 {
+					fmt.Println("No samples recorded; enable sampling with 'queuectl config set stats-interval 5m'")
+				} else {
+					fmt.Println("No samples recorded in this period")
+				}
+				return nil
+			}
+
+			times := make([]string, len(samples))
+			width := len("Time")
+			for i, sample := range samples {
+				times[i] = formatTime(sample.Time)
+				if len(times[i]) > width {
+					width = len(times[i])
+				}
+			}
+
+			header := fmt.Sprintf("%-*s", width, "Time")
+			for _, state := range historyStates {
+				header += fmt.Sprintf(" %10s", state)
+			}
+			fmt.Println(header)
+			fmt.Println(strings.Repeat("-", len(header)))
+
+			for i, sample := range samples {
+				line := fmt.Sprintf("%-*s", width, times[i])
+				for _, state := range historyStates {
+					line += fmt.Sprintf(" %10d", sample.Counts[state])
+				}
+				fmt.Println(line)
+			}
+
+			first, last := samples[0], samples[len(samples)-1]
+			change := fmt.Sprintf("%-*s", width, "Change")
+			for _, state := range historyStates {
+				change += fmt.Sprintf(" %+10d", last.Counts[state]-first.Counts[state])
+			}
+			fmt.Println(strings.Repeat("-", len(header)))
+			fmt.Println(change)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&since, "since", 24*time.Hour, "How far back to show samples")
+
+	return cmd
+}
+
+func statsResourcesCmd() *cobra.Command {
+	var top int
+	var sortBy string
+
+	cmd := &cobra.Command{
+		Use:   "resources",
+		Short: "List the jobs that used the most CPU or memory",
+		Long: `List jobs by the resources their last attempt used: CPU time (user plus
+system) and peak resident memory, as recorded by the worker from the
+command's process and the children it waited for.
+
+Jobs that have not run, and jobs run on platforms without resource
+usage reporting, have no usage recorded and are not listed. For very
+short commands the peak memory can reflect the worker process the
+command was started from.
+
+Examples:
+  queuectl stats resources                   # Top 10 by CPU time
+  queuectl stats resources --sort memory --top 20`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if top < 1 {
+				return fmt.Errorf("--top must be at least 1")
+			}
+			if sortBy != "cpu" && sortBy != "memory" {
+				return fmt.Errorf("invalid --sort: %s (valid: cpu, memory)", sortBy)
+			}
+
+			jobs, err := getStorage().ListJobs("", storage.ListOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to list jobs: %w", err)
+			}
+
+			var measured []*job.Job
+			for _, j := range jobs {
+				if j.CPUTimeMS > 0 || j.MaxRSSKB > 0 {
+					measured = append(measured, j)
+				}
+			}
+			sort.SliceStable(measured, func(a, b int) bool {
+				if sortBy == "memory" {
+					return measured[a].MaxRSSKB > measured[b].MaxRSSKB
+				}
+				return measured[a].CPUTimeMS > measured[b].CPUTimeMS
+			})
+			if len(measured) > top {
+				measured = measured[:top]
+			}
+
+			fmt.Printf("=== Top %d job(s) by %s ===\n\n", top, sortBy)
+			if len(measured) == 0 {
+				fmt.Println("No resource usage recorded yet")
+				return nil
+			}
+
+			fmt.Printf("%-36s %10s %12s  %s\n", "Job ID", "CPU", "Max RSS", "Command")
+			for _, j := range measured {
+				fmt.Printf("%-36s %9.2fs %9.1f MB  %s\n",
+					j.ID, float64(j.CPUTimeMS)/1000, float64(j.MaxRSSKB)/1024, firstLine(j.Command))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&top, "top", 10, "Number of jobs to show")
+	cmd.Flags().StringVar(&sortBy, "sort", "cpu", "Order by cpu or memory")
+
+	return cmd
+}
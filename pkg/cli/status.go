@@ -2,79 +2,377 @@ package cli
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"os/signal"
 	"path/filepath"
-	"strconv"
+	"sort"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/MithileshwaranS/queuectl/internal/storage"
+	"github.com/MithileshwaranS/queuectl/internal/worker"
 	"github.com/spf13/cobra"
 )
 
+// statusDurationSample is how many recently completed jobs status
+// summarizes the run time of
+const statusDurationSample = 100
+
+// workerStalledAfter is how old a worker's heartbeat may get before status
+// reports the worker as stalled
+const workerStalledAfter = 10 * time.Second
+
+// statusStates are the states status reports, in display order
+var statusStates = []job.State{
+	job.StatePending,
+	job.StateProcessing,
+	job.StateCompleted,
+	job.StateFailed,
+	job.StateDead,
+	job.StateHeld,
+	job.StateArchived,
+	job.StateCancelled,
+}
+
+// statusReport is the JSON form of the status command's output
+type statusReport struct {
+	Queue        string                       `json:"queue,omitempty"` // Set when counts are limited to one queue
+	Total        int                          `json:"total"`
+	States       map[job.State]int            `json:"states"`
+	Queues       map[string]map[job.State]int `json:"queues"` // Counts of every queue with jobs
+	PausedQueues []string                     `json:"paused_queues"`
+	Workers      []Worker                     `json:"workers"`
+	Durations    *statusDurations             `json:"durations"` // nil if no completed job recorded one
+	Config       statusConfig                 `json:"config"`
+}
+
+// statusDurations summarizes the run times of recently completed jobs
+type statusDurations struct {
+	Sample    int   `json:"sample"` // Number of jobs summarized
+	LastMS    int64 `json:"last_ms"`
+	AverageMS int64 `json:"average_ms"`
+	MaxMS     int64 `json:"max_ms"`
+}
+
+// summarizeDurations summarizes the recorded run times of jobs, which are
+// ordered newest first. Jobs without a recorded duration are skipped.
+func summarizeDurations(jobs []*job.Job) *statusDurations {
+	var d statusDurations
+	var total int64
+	for _, j := range jobs {
+		if j.DurationMS <= 0 {
+			continue
+		}
+		if d.Sample == 0 {
+			d.LastMS = j.DurationMS
+		}
+		d.Sample++
+		total += j.DurationMS
+		if j.DurationMS > d.MaxMS {
+			d.MaxMS = j.DurationMS
+		}
+	}
+	if d.Sample == 0 {
+		return nil
+	}
+	d.AverageMS = total / int64(d.Sample)
+	return &d
+}
+
+// statusConfig holds the configuration values status reports
+type statusConfig struct {
+	MaxRetries  int     `json:"max_retries"`
+	BackoffBase float64 `json:"backoff_base"`
+	DBPath      string  `json:"db_path"`
+}
+
+// newStatusReport builds the JSON status report. Every state is listed,
+// and empty lists encode as [] rather than null.
+func newStatusReport(stats map[job.State]int, total int, queues map[string]map[job.State]int, paused []string, workers []Worker, durations *statusDurations) statusReport {
+	states := make(map[job.State]int, len(statusStates))
+	for _, state := range statusStates {
+		states[state] = stats[state]
+	}
+	if queues == nil {
+		queues = map[string]map[job.State]int{}
+	}
+	if paused == nil {
+		paused = []string{}
+	}
+	if workers == nil {
+		workers = []Worker{}
+	}
+
+	return statusReport{
+		Total:        total,
+		States:       states,
+		Queues:       queues,
+		PausedQueues: paused,
+		Workers:      workers,
+		Durations:    durations,
+		Config: statusConfig{
+			MaxRetries:  getConfig().MaxRetries,
+			BackoffBase: getConfig().BackoffBase,
+			DBPath:      displayDBPath(getConfig().DBPath),
+		},
+	}
+}
+
+// displayDBPath returns db_path with any Redis password masked
+func displayDBPath(path string) string {
+	if !storage.IsRedisURL(path) {
+		return path
+	}
+	u, err := url.Parse(path)
+	if err != nil {
+		return path
+	}
+	return u.Redacted()
+}
+
 func statusCmd() *cobra.Command {
+	var watch bool
+	var queue string
+	var interval time.Duration
+
 	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Show summary of all job states and active workers",
-		Long:  `Display a summary of job counts by state and list active workers.`,
+		Long: `Display a summary of job counts by state and list active workers.
+
+Each worker is shown with the age of its last heartbeat and the jobs it
+is running. Running workers refresh their heartbeat every few seconds;
+a worker whose heartbeat is more than 10s old is marked stalled, which
+usually means its process is hung or was suspended.
+
+With more than one queue in use, the job counts of each queue are listed
+too. With --queue the counts and run times cover that queue only.
+
+The run time of the last 100 completed jobs is summarized as the last,
+average and longest duration, to spot jobs getting slower.
+
+With --watch the screen is cleared and the summary redrawn every
+--interval (and whenever the terminal is resized) until Ctrl+C.
+
+With --output json the summary is printed as a JSON object with the
+total, the count of every state, the counts of each queue (queues), paused queues, active workers (with
+last_heartbeat, current_jobs and stalled), durations (sample, last_ms,
+average_ms and max_ms, or null) and the main configuration values.
+
+Examples:
+  queuectl status
+  queuectl status --queue emails
+  queuectl status --watch
+  queuectl status --watch --interval 500ms`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// Get job statistics
-			stats, err := getStorage().GetJobStats()
-			if err != nil {
-				return fmt.Errorf("failed to get job stats: %w", err)
+			if watch {
+				if jsonOutput() {
+					return fmt.Errorf("--watch cannot be used with --output json")
+				}
+				if interval <= 0 {
+					return fmt.Errorf("--interval must be positive")
+				}
+				return watchStatus(interval, queue)
 			}
 
-			// Calculate totals
-			total := 0
-			for _, count := range stats {
-				total += count
+			report, err := collectStatus(queue)
+			if err != nil {
+				return err
 			}
+			if jsonOutput() {
+				return printJSON(report)
+			}
+			fmt.Print(renderStatus(report, time.Now()))
+			return nil
+		},
+	}
 
-			// Display job statistics
-			fmt.Println("=== Job Queue Status ===")
-			fmt.Println()
-			fmt.Printf("Total Jobs: %d\n", total)
-			fmt.Println()
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "Redraw the status until interrupted")
+	cmd.Flags().DurationVar(&interval, "interval", 2*time.Second, "How often --watch redraws the status")
+	cmd.Flags().StringVarP(&queue, "queue", "q", "", "Only count jobs in this queue")
 
-			// Show counts for each state
-			states := []job.State{
-				job.StatePending,
-				job.StateProcessing,
-				job.StateCompleted,
-				job.StateFailed,
-				job.StateDead,
-			}
+	return cmd
+}
 
-			fmt.Println("Job States:")
-			for _, state := range states {
-				count := stats[state]
-				icon := getStateIcon(state)
-				fmt.Printf("  %s %-12s: %d\n", icon, state, count)
-			}
+// collectStatus gathers the job counts, paused queues and workers status
+// reports. A non-empty queue limits the counts and run times to it.
+func collectStatus(queue string) (statusReport, error) {
+	queues, err := getStorage().GetQueueStats()
+	if err != nil {
+		return statusReport{}, fmt.Errorf("failed to get job stats: %w", err)
+	}
 
-			// Show active workers
-			fmt.Println()
-			fmt.Println("Active Workers:")
-			workers := getActiveWorkers()
-			if len(workers) == 0 {
-				fmt.Println("  No active workers")
-			} else {
-				for _, w := range workers {
-					fmt.Printf("  • Worker %s (PID: %s)\n", w.ID, w.PID)
+	var stats map[job.State]int
+	if queue != "" {
+		stats = queues[queue]
+	} else if stats, err = getStorage().GetJobStats(); err != nil {
+		return statusReport{}, fmt.Errorf("failed to get job stats: %w", err)
+	}
+
+	// Calculate totals
+	total := 0
+	for _, count := range stats {
+		total += count
+	}
+
+	paused, err := getStorage().ListPausedQueues()
+	if err != nil {
+		return statusReport{}, fmt.Errorf("failed to list paused queues: %w", err)
+	}
+
+	recent, err := getStorage().ListJobs(job.StateCompleted, storage.ListOptions{Limit: statusDurationSample, SortBy: storage.SortUpdated, Queue: queue})
+	if err != nil {
+		return statusReport{}, fmt.Errorf("failed to list completed jobs: %w", err)
+	}
+
+	workers := getActiveWorkers()
+	addJobProgress(workers)
+
+	report := newStatusReport(stats, total, queues, paused, workers, summarizeDurations(recent))
+	report.Queue = queue
+	return report, nil
+}
+
+// renderStatus formats a status report as text
+func renderStatus(report statusReport, now time.Time) string {
+	var b strings.Builder
+
+	// Display job statistics
+	if report.Queue != "" {
+		fmt.Fprintf(&b, "=== Job Queue Status (queue: %s) ===\n", report.Queue)
+	} else {
+		fmt.Fprintln(&b, "=== Job Queue Status ===")
+	}
+	fmt.Fprintln(&b)
+	fmt.Fprintf(&b, "Total Jobs: %d\n", report.Total)
+	fmt.Fprintln(&b)
+
+	// Show counts for each state
+	fmt.Fprintln(&b, "Job States:")
+	for _, state := range statusStates {
+		count := report.States[state]
+		icon := getStateIcon(state)
+		fmt.Fprintf(&b, "  %s %-12s: %d\n", icon, state, count)
+	}
+
+	// Break the counts down by queue once there is more than one
+	if report.Queue == "" && len(report.Queues) > 1 {
+		names := make([]string, 0, len(report.Queues))
+		for name := range report.Queues {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Fprintln(&b)
+		fmt.Fprintln(&b, "Queues:")
+		for _, name := range names {
+			var counts []string
+			for _, state := range statusStates {
+				if n := report.Queues[name][state]; n > 0 {
+					counts = append(counts, fmt.Sprintf("%s %d", state, n))
 				}
 			}
+			fmt.Fprintf(&b, "  %-12s %s\n", name, strings.Join(counts, ", "))
+		}
+	}
 
-			// Show configuration
-			fmt.Println()
-			fmt.Println("Configuration:")
-			fmt.Printf("  Max Retries: %d\n", getConfig().MaxRetries)
-			fmt.Printf("  Backoff Base: %.1f\n", getConfig().BackoffBase)
-			fmt.Printf("  Database: %s\n", getConfig().DBPath)
+	// Show paused queues
+	if len(report.PausedQueues) > 0 {
+		fmt.Fprintln(&b)
+		fmt.Fprintln(&b, "Paused:")
+		for _, queue := range report.PausedQueues {
+			fmt.Fprintf(&b, "  ⏸ %s\n", describeQueue(queue))
+		}
+	}
 
-			return nil
-		},
+	// Show how long recent jobs took
+	if d := report.Durations; d != nil {
+		fmt.Fprintln(&b)
+		fmt.Fprintf(&b, "Run Time (last %d completed):\n", d.Sample)
+		fmt.Fprintf(&b, "  Last: %s  Average: %s  Max: %s\n", formatRunTime(d.LastMS), formatRunTime(d.AverageMS), formatRunTime(d.MaxMS))
 	}
 
-	return cmd
+	// Show active workers
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "Active Workers:")
+	if len(report.Workers) == 0 {
+		fmt.Fprintln(&b, "  No active workers")
+	} else {
+		for _, w := range report.Workers {
+			fmt.Fprintf(&b, "  • Worker %s (PID: %s) %s\n", w.ID, w.PID, describeWorkerHealth(w, now))
+		}
+	}
+
+	// Show configuration
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "Configuration:")
+	fmt.Fprintf(&b, "  Max Retries: %d\n", report.Config.MaxRetries)
+	fmt.Fprintf(&b, "  Backoff Base: %.1f\n", report.Config.BackoffBase)
+	fmt.Fprintf(&b, "  Database: %s\n", report.Config.DBPath)
+
+	return b.String()
+}
+
+// Terminal control sequences used by status --watch and top
+const (
+	clearScreen = "\033[H\033[2J"
+	hideCursor  = "\033[?25l"
+	showCursor  = "\033[?25h"
+)
+
+// watchStatus redraws the status of queue (all queues if empty) every
+// interval until interrupted
+func watchStatus(interval time.Duration, queue string) error {
+	return watchScreen(interval, "queuectl status", func(now time.Time) (string, error) {
+		report, err := collectStatus(queue)
+		if err != nil {
+			return "", err
+		}
+		return renderStatus(report, now), nil
+	})
+}
+
+// watchScreen redraws the frame returned by render every interval, and
+// when the terminal is resized, until SIGINT or SIGTERM. The cursor is
+// hidden while watching and restored on exit.
+func watchScreen(interval time.Duration, title string, render func(now time.Time) (string, error)) error {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	resized := make(chan os.Signal, 1)
+	notifyResize(resized)
+	defer signal.Stop(resized)
+
+	fmt.Print(hideCursor)
+	defer fmt.Print(showCursor)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// Render before clearing so the screen never sits empty while
+		// the database is queried
+		now := time.Now()
+		frame := fmt.Sprintf("Every %s: %s    %s\n\n", interval, title, formatTime(now))
+		if body, err := render(now); err != nil {
+			frame += fmt.Sprintf("Error: %v\n", err)
+		} else {
+			frame += body
+		}
+		fmt.Print(clearScreen + frame)
+
+		select {
+		case <-sigChan:
+			fmt.Println()
+			return nil
+		case <-resized:
+		case <-ticker.C:
+		}
+	}
 }
 
 // getStateIcon returns an emoji/icon for each state
@@ -90,6 +388,12 @@ func getStateIcon(state job.State) string {
 		return "⚠"
 	case job.StateDead:
 		return "✗"
+	case job.StateHeld:
+		return "⏸"
+	case job.StateArchived:
+		return "🗄"
+	case job.StateCancelled:
+		return "⊘"
 	default:
 		return "•"
 	}
@@ -97,18 +401,60 @@ func getStateIcon(state job.State) string {
 
 // Worker represents an active worker process
 type Worker struct {
-	ID  string
-	PID string
+	ID            string     `json:"id"`
+	PID           string     `json:"pid"`
+	LastHeartbeat *time.Time `json:"last_heartbeat"` // nil if the worker has not written one
+	CurrentJobs   []string   `json:"current_jobs"`
+	Stalled       bool       `json:"stalled"`
+	// Progress maps the current jobs that reported progress to its percent
+	Progress map[string]int `json:"progress,omitempty"`
 }
 
-// getActiveWorkers reads worker PIDs from filesystem
-func getActiveWorkers() []Worker {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil
+// addJobProgress looks up the progress each worker's current jobs have
+// reported. Jobs that have not reported any, or are gone, are left out.
+func addJobProgress(workers []Worker) {
+	for i := range workers {
+		for _, id := range workers[i].CurrentJobs {
+			j, err := getStorage().GetJob(id)
+			if err != nil || j.State != job.StateProcessing || j.Progress == 0 {
+				continue
+			}
+			if workers[i].Progress == nil {
+				workers[i].Progress = make(map[string]int)
+			}
+			workers[i].Progress[id] = j.Progress
+		}
+	}
+}
+
+// describeWorkerHealth summarizes a worker's last heartbeat and the jobs
+// it is running
+func describeWorkerHealth(w Worker, now time.Time) string {
+	if w.LastHeartbeat == nil {
+		return "- no heartbeat"
 	}
 
-	workerDir := filepath.Join(homeDir, ".queuectl", "workers")
+	activity := "idle"
+	if len(w.CurrentJobs) > 0 {
+		running := make([]string, len(w.CurrentJobs))
+		for i, id := range w.CurrentJobs {
+			running[i] = id
+			if p, ok := w.Progress[id]; ok {
+				running[i] += " " + progressBar(p)
+			}
+		}
+		activity = "running " + strings.Join(running, ", ")
+	}
+	beat := "heartbeat " + formatRelative(*w.LastHeartbeat, now)
+	if w.Stalled {
+		return fmt.Sprintf("- ⚠ stalled: %s, %s", beat, activity)
+	}
+	return fmt.Sprintf("- %s, %s", beat, activity)
+}
+
+// getActiveWorkers reads worker PIDs from filesystem
+func getActiveWorkers() []Worker {
+	workerDir := worker.WorkersDir()
 	entries, err := os.ReadDir(workerDir)
 	if err != nil {
 		return nil
@@ -124,33 +470,22 @@ func getActiveWorkers() []Worker {
 
 			pid := strings.TrimSpace(string(pidData))
 			// Check if process is still running
-			if isProcessRunning(pid) {
+			if worker.ProcessRunning(pid) {
 				workerID := strings.TrimSuffix(entry.Name(), ".pid")
-				workers = append(workers, Worker{
-					ID:  workerID,
-					PID: pid,
-				})
+				w := Worker{
+					ID:          workerID,
+					PID:         pid,
+					CurrentJobs: []string{},
+				}
+				if hb, err := worker.ReadHeartbeat(workerID); err == nil {
+					w.LastHeartbeat = &hb.Time
+					w.CurrentJobs = append(w.CurrentJobs, hb.Jobs...)
+					w.Stalled = time.Since(hb.Time) > workerStalledAfter
+				}
+				workers = append(workers, w)
 			}
 		}
 	}
 
 	return workers
 }
-
-// isProcessRunning checks if a process with given PID is running
-func isProcessRunning(pid string) bool {
-	pidInt, err := strconv.Atoi(pid)
-	if err != nil {
-		return false
-	}
-
-	process, err := os.FindProcess(pidInt)
-	if err != nil {
-		return false
-	}
-
-	// On Unix, FindProcess always succeeds, so we need to send signal 0
-	// to check if process actually exists
-	err = process.Signal(os.Signal(nil))
-	return err == nil
-}
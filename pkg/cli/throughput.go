@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// sparkLevels are the glyphs used to draw a sparkline, lowest first
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+func throughputCmd() *cobra.Command {
+	var window, bucket time.Duration
+	var sparkline bool
+
+	cmd := &cobra.Command{
+		Use:   "throughput",
+		Short: "Show completed jobs per interval",
+		Long: `Show how many jobs completed in each interval over a recent window.
+
+Jobs are bucketed by their completion time. Use this to check whether the
+worker pool is keeping pace with incoming work.
+
+Examples:
+  queuectl throughput                          # Last hour in 5 minute buckets
+  queuectl throughput --window 24h --bucket 1h # Last day, hourly
+  queuectl throughput --sparkline              # Add an ASCII sparkline`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if window <= 0 {
+				return fmt.Errorf("--window must be positive")
+			}
+			if bucket < time.Second {
+				return fmt.Errorf("--bucket must be at least 1s")
+			}
+			if bucket > window {
+				return fmt.Errorf("--bucket must not be larger than --window")
+			}
+
+			since := time.Now().Add(-window).Truncate(bucket)
+			buckets, err := getStorage().GetThroughput(since, bucket)
+			if err != nil {
+				return fmt.Errorf("failed to get throughput: %w", err)
+			}
+
+			total, max := 0, 0
+			for _, b := range buckets {
+				total += b.Count
+				if b.Count > max {
+					max = b.Count
+				}
+			}
+
+			fmt.Printf("=== Throughput (last %s, %s buckets) ===\n\n", window, bucket)
+			for _, b := range buckets {
+				bar := ""
+				if max > 0 {
+					bar = strings.Repeat("█", b.Count*40/max)
+				}
+				fmt.Printf("%s  %5d  %s\n", formatTime(b.Start), b.Count, bar)
+			}
+
+			fmt.Println()
+			if sparkline {
+				fmt.Printf("Trend: %s\n", renderSparkline(buckets, max))
+			}
+			fmt.Printf("Total: %d job(s) completed, %.2f/min\n", total, float64(total)/window.Minutes())
+
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&window, "window", time.Hour, "How far back to look")
+	cmd.Flags().DurationVar(&bucket, "bucket", 5*time.Minute, "Width of each interval")
+	cmd.Flags().BoolVar(&sparkline, "sparkline", false, "Print an ASCII sparkline of the counts")
+
+	return cmd
+}
+
+// renderSparkline draws one glyph per bucket scaled against max
+func renderSparkline(buckets []storage.ThroughputBucket, max int) string {
+	var b strings.Builder
+	for _, bucket := range buckets {
+		level := 0
+		if max > 0 {
+			level = bucket.Count * (len(sparkLevels) - 1) / max
+		}
+		b.WriteRune(sparkLevels[level])
+	}
+	return b.String()
+}
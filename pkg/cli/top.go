@@ -0,0 +1,201 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/MithileshwaranS/queuectl/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// topTrendWidth is how many intervals the top sparklines show
+const topTrendWidth = 30
+
+func topCmd() *cobra.Command {
+	var interval, window time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "top",
+		Short: "Show live completion and failure rates",
+		Long: `Sample the job counts every --interval and show how many jobs are
+completing and how many attempts are failing per second, averaged over
+the last --window, with a sparkline of the recent intervals.
+
+The backlog (pending, processing and failed jobs awaiting retry) is
+shown with the time it would take to drain at the current completion
+rate. Rates appear once two samples have been taken. Jobs deleted or
+reset from the DLQ while top runs are not counted as negative activity.
+
+The screen is redrawn until Ctrl+C.
+
+Examples:
+  queuectl top
+  queuectl top --interval 5s --window 5m`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if jsonOutput() {
+				return fmt.Errorf("top cannot be used with --output json")
+			}
+			if interval < 100*time.Millisecond {
+				return fmt.Errorf("--interval must be at least 100ms")
+			}
+			if window < interval {
+				return fmt.Errorf("--window must not be shorter than --interval")
+			}
+
+			t := &topState{window: window, keep: window}
+			if trend := topTrendWidth * interval; trend > t.keep {
+				t.keep = trend
+			}
+			return watchScreen(interval, "queuectl top", func(now time.Time) (string, error) {
+				// A resize redraws early; only sample once most of an
+				// interval has passed so the sparkline stays even
+				if t.last == nil || now.Sub(t.last.at) >= interval/2 {
+					if err := t.sample(now); err != nil {
+						return "", err
+					}
+				}
+				return t.render(now), nil
+			})
+		},
+	}
+
+	cmd.Flags().DurationVar(&interval, "interval", time.Second, "How often to sample the job counts")
+	cmd.Flags().DurationVar(&window, "window", time.Minute, "How far back the rates are averaged")
+
+	return cmd
+}
+
+// topSample is the state of the queue at one point in time
+type topSample struct {
+	at     time.Time
+	stats  map[job.State]int
+	failed int // Failed attempts recorded across all jobs
+}
+
+// topInterval is the activity between two consecutive samples
+type topInterval struct {
+	start, end time.Time
+	completed  int
+	failed     int
+}
+
+// topState holds the samples taken by top
+type topState struct {
+	window    time.Duration // Span the rates are averaged over
+	keep      time.Duration // Span of intervals kept, covering window and the trend
+	last      *topSample
+	intervals []topInterval
+}
+
+// sample records the current counts and the activity since the last sample
+func (t *topState) sample(now time.Time) error {
+	stats, err := getStorage().GetJobStats()
+	if err != nil {
+		return fmt.Errorf("failed to get job stats: %w", err)
+	}
+	failed, err := getStorage().CountFailedAttempts()
+	if err != nil {
+		return err
+	}
+
+	cur := &topSample{at: now, stats: stats, failed: failed}
+	if t.last != nil {
+		t.intervals = append(t.intervals, intervalBetween(*t.last, *cur))
+	}
+	t.last = cur
+
+	for len(t.intervals) > 0 && now.Sub(t.intervals[0].end) > t.keep {
+		t.intervals = t.intervals[1:]
+	}
+	return nil
+}
+
+// intervalBetween returns the jobs completed and attempts failed between
+// two samples. Totals drop when jobs are deleted or reset, which is
+// counted as no activity rather than negative activity.
+func intervalBetween(prev, cur topSample) topInterval {
+	return topInterval{
+		start:     prev.at,
+		end:       cur.at,
+		completed: max(cur.stats[job.StateCompleted]-prev.stats[job.StateCompleted], 0),
+		failed:    max(cur.failed-prev.failed, 0),
+	}
+}
+
+// rates returns the completions and failed attempts per second over the
+// intervals that ended within the window
+func (t *topState) rates(now time.Time) (completed, failed float64) {
+	var elapsed time.Duration
+	var c, f int
+	for _, iv := range t.intervals {
+		if now.Sub(iv.end) > t.window {
+			continue
+		}
+		elapsed += iv.end.Sub(iv.start)
+		c += iv.completed
+		f += iv.failed
+	}
+	if elapsed <= 0 {
+		return 0, 0
+	}
+	return float64(c) / elapsed.Seconds(), float64(f) / elapsed.Seconds()
+}
+
+// render formats the rates, trend and backlog as text
+func (t *topState) render(now time.Time) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "=== Job Throughput (rates over the last %s) ===\n", t.window)
+	fmt.Fprintln(&b)
+
+	if len(t.intervals) == 0 {
+		fmt.Fprintln(&b, "Measuring... rates appear after the next sample")
+	} else {
+		completed, failed := t.rates(now)
+		trend := t.intervals
+		if len(trend) > topTrendWidth {
+			trend = trend[len(trend)-topTrendWidth:]
+		}
+		last := trend[len(trend)-1]
+
+		fmt.Fprintf(&b, "%-11s %10s %6s  %s\n", "", "Rate", "Last", "Trend")
+		fmt.Fprintf(&b, "%-11s %8.2f/s %6d  %s\n", "Completed", completed, last.completed,
+			topSparkline(trend, func(iv topInterval) int { return iv.completed }))
+		fmt.Fprintf(&b, "%-11s %8.2f/s %6d  %s\n", "Failed", failed, last.failed,
+			topSparkline(trend, func(iv topInterval) int { return iv.failed }))
+	}
+
+	stats := t.last.stats
+	backlog := stats[job.StatePending] + stats[job.StateProcessing] + stats[job.StateFailed]
+	fmt.Fprintln(&b)
+	fmt.Fprintf(&b, "Pending:    %d\n", stats[job.StatePending])
+	fmt.Fprintf(&b, "Processing: %d\n", stats[job.StateProcessing])
+	fmt.Fprintf(&b, "Retrying:   %d\n", stats[job.StateFailed])
+	fmt.Fprintf(&b, "Dead:       %d\n", stats[job.StateDead])
+	fmt.Fprintln(&b)
+
+	completed, _ := t.rates(now)
+	switch {
+	case backlog == 0:
+		fmt.Fprintln(&b, "Drain ETA:  backlog is empty")
+	case completed == 0:
+		fmt.Fprintf(&b, "Drain ETA:  unknown, no jobs completed in the last %s\n", t.window)
+	default:
+		fmt.Fprintf(&b, "Drain ETA:  %s for %d job(s) at %.2f/s\n", formatETA(backlog, completed*60), backlog, completed)
+	}
+
+	return b.String()
+}
+
+// topSparkline draws one count of each interval as a sparkline
+func topSparkline(intervals []topInterval, count func(topInterval) int) string {
+	buckets := make([]storage.ThroughputBucket, len(intervals))
+	peak := 0
+	for i, iv := range intervals {
+		buckets[i] = storage.ThroughputBucket{Start: iv.start, Count: count(iv)}
+		peak = max(peak, buckets[i].Count)
+	}
+	return renderSparkline(buckets, peak)
+}
@@ -0,0 +1,236 @@
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/MithileshwaranS/queuectl/internal/job"
+	"github.com/MithileshwaranS/queuectl/internal/storage"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+// Values for transfer --on-conflict
+const (
+	conflictSkip      = "skip"
+	conflictOverwrite = "overwrite"
+	conflictNewID     = "new-id"
+)
+
+func transferCmd() *cobra.Command {
+	var from, to, state, commandFilter, onConflict string
+	var glob bool
+
+	cmd := &cobra.Command{
+		Use:   "transfer",
+		Short: "Move jobs from one database to another",
+		Long: `Move the jobs matching a state (and optionally a command filter) from one
+queuectl database to another, e.g. to rebalance work across queue
+instances or migrate to a new database file.
+
+Each job is written to the destination and then deleted from the source.
+If a worker claims the job in the meantime, or the delete fails, the
+destination copy is removed again, so a job is never left in both
+databases. Processing jobs cannot be moved, since a worker owns them.
+With job-schema-path set, jobs that do not match the schema are not
+moved.
+
+A job whose ID already exists in the destination is handled according to
+--on-conflict:
+  skip       Leave it in the source (default)
+  overwrite  Replace the destination job
+  new-id     Move it under a freshly generated ID
+
+--from defaults to the configured database. The destination is created
+if it does not exist. --command-like matches a substring of the command,
+or the whole command as a glob with --glob.
+
+Examples:
+  queuectl transfer --to /data/shard2.db --state pending
+  queuectl transfer --from a.db --to b.db --state dead --command-like backup
+  queuectl transfer --to b.db --state pending --command-like 'report-*' --glob --on-conflict new-id`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if to == "" {
+				return fmt.Errorf("--to is required")
+			}
+			if from == "" {
+				from = getConfig().DBPath
+			}
+			if samePath(from, to) {
+				return fmt.Errorf("--from and --to refer to the same database")
+			}
+			if job.State(state) == job.StateProcessing {
+				return fmt.Errorf("processing jobs cannot be transferred")
+			}
+			switch onConflict {
+			case conflictSkip, conflictOverwrite, conflictNewID:
+			default:
+				return fmt.Errorf("invalid --on-conflict: %s (valid: skip, overwrite, new-id)", onConflict)
+			}
+
+			var matcher commandMatcher
+			if commandFilter != "" {
+				var err error
+				matcher, err = newCommandMatcher(commandFilter, glob)
+				if err != nil {
+					return err
+				}
+			}
+
+			src, err := openTransferStorage(from)
+			if err != nil {
+				return fmt.Errorf("failed to open source: %w", err)
+			}
+			defer src.Close()
+
+			dst, err := openTransferStorage(to)
+			if err != nil {
+				return fmt.Errorf("failed to open destination: %w", err)
+			}
+			defer dst.Close()
+
+			// The destination's schema is the configured one, as for any
+			// job stored from outside the queue
+			schema, err := loadJobSchema()
+			if err != nil {
+				return err
+			}
+
+			jobs, err := src.ListJobs(job.State(state), storage.ListOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to list jobs: %w", err)
+			}
+			if matcher != nil {
+				jobs = filterJobsByCommand(jobs, matcher)
+			}
+
+			moved, skipped, failed := 0, 0, 0
+			for _, j := range jobs {
+				if j.State == job.StateProcessing {
+					continue
+				}
+
+				srcID := j.ID
+				if schema != nil {
+					if err := schema.ValidateJob(j); err != nil {
+						fmt.Printf("✗ Failed to transfer %s: %v\n", srcID, err)
+						failed++
+						continue
+					}
+				}
+				existing, err := dst.GetJob(j.ID)
+				if errors.Is(err, sql.ErrNoRows) {
+					existing = nil
+				} else if err != nil {
+					fmt.Printf("✗ Failed to transfer %s: %v\n", srcID, err)
+					failed++
+					continue
+				}
+				if existing != nil && existing.State == job.StateProcessing && onConflict == conflictOverwrite {
+					fmt.Printf("• Skipped %s: destination job is being processed\n", srcID)
+					skipped++
+					continue
+				}
+				if existing != nil {
+					switch onConflict {
+					case conflictSkip:
+						fmt.Printf("• Skipped %s: ID already exists in destination\n", srcID)
+						skipped++
+						continue
+					case conflictNewID:
+						j.ID = uuid.New().String()
+						existing = nil
+					}
+				}
+
+				if err := transferJob(src, dst, srcID, j, existing); err != nil {
+					fmt.Printf("✗ Failed to transfer %s: %v\n", srcID, err)
+					failed++
+					continue
+				}
+				if j.ID != srcID {
+					fmt.Printf("✓ Moved %s as %s\n", srcID, j.ID)
+				}
+				moved++
+			}
+
+			fmt.Printf("✓ Moved %d job(s) from %s to %s (%d skipped, %d failed)\n", moved, from, to, skipped, failed)
+			if failed > 0 {
+				return fmt.Errorf("%d job(s) could not be transferred", failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "Source database (default: the configured database)")
+	cmd.Flags().StringVar(&to, "to", "", "Destination database")
+	cmd.Flags().StringVar(&state, "state", string(job.StatePending), "Only move jobs in this state (empty for every state but processing)")
+	cmd.Flags().StringVar(&commandFilter, "command-like", "", "Only move jobs whose command contains this text (or matches it with --glob)")
+	cmd.Flags().BoolVar(&glob, "glob", false, "Treat --command-like as a glob matched against the whole command")
+	cmd.Flags().StringVar(&onConflict, "on-conflict", conflictSkip, "What to do when the ID exists in the destination: skip, overwrite, new-id")
+
+	return cmd
+}
+
+// transferJob writes j to dst, replacing existing if there is one, and
+// deletes it (as srcID) from src. The source copy is deleted only if it
+// is still in the state it was listed in; if a worker claimed it
+// meanwhile, or the delete fails, dst is restored to existing (or the
+// copy removed) so the job stays in exactly one database.
+func transferJob(src, dst storage.Storage, srcID string, j, existing *job.Job) error {
+	var err error
+	if existing != nil {
+		err = dst.SaveJobIfState(j, existing.State)
+	} else {
+		err = dst.InsertJob(j)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save to destination: %w", err)
+	}
+
+	if err := src.DeleteJobIfState(srcID, j.State); err != nil {
+		var undoErr error
+		if existing != nil {
+			undoErr = dst.SaveJobIfState(existing, j.State)
+		} else {
+			undoErr = dst.DeleteJobIfState(j.ID, j.State)
+		}
+		if undoErr != nil {
+			return fmt.Errorf("failed to delete from source (%v) and to undo the destination copy: %w", err, undoErr)
+		}
+		return fmt.Errorf("failed to delete from source: %w", err)
+	}
+	return nil
+}
+
+// openTransferStorage opens and migrates a SQLite database or Redis
+// server for transfer
+func openTransferStorage(path string) (storage.Storage, error) {
+	var s storage.Storage
+	var err error
+	if storage.IsRedisURL(path) {
+		s, err = storage.NewRedisStorage(path)
+	} else {
+		s, err = storage.NewSQLiteStorage(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Initialize(); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// samePath reports whether two database paths refer to the same file
+func samePath(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return absA == absB
+}
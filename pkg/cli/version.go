@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/MithileshwaranS/queuectl/internal/version"
+	"github.com/spf13/cobra"
+)
+
+func versionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Show the version and build information",
+		Long: `Show the queuectl version, the git commit and date it was built from,
+and the Go version and platform it was built with. Please include this
+when reporting an issue.
+
+Examples:
+  queuectl version
+  queuectl version -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			info := version.Get()
+			if jsonOutput() {
+				return printJSON(info)
+			}
+
+			fmt.Printf("Version:    %s\n", info.Version)
+			fmt.Printf("Commit:     %s\n", orUnknown(info.Commit))
+			fmt.Printf("Built:      %s\n", orUnknown(info.Date))
+			fmt.Printf("Go version: %s\n", info.GoVersion)
+			fmt.Printf("Platform:   %s\n", info.Platform)
+			return nil
+		},
+	}
+}
+
+// orUnknown returns s, or "unknown" if it is empty
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
@@ -1,8 +1,18 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
 
+	"github.com/MithileshwaranS/queuectl/internal/audit"
+	"github.com/MithileshwaranS/queuectl/internal/logrotate"
+	"github.com/MithileshwaranS/queuectl/internal/metrics"
+	"github.com/MithileshwaranS/queuectl/internal/notify"
+	"github.com/MithileshwaranS/queuectl/internal/storage"
 	"github.com/MithileshwaranS/queuectl/internal/worker"
 	"github.com/spf13/cobra"
 )
@@ -21,7 +31,11 @@ func workerCmd() *cobra.Command {
 }
 
 func workerStartCmd() *cobra.Command {
-	var count int
+	var count, concurrency int
+	var logFile, metricsAddr string
+	var exitOnFatal bool
+	var maxLifetime, shutdownTimeout time.Duration
+	var commandPrefixes, queues []string
 
 	cmd := &cobra.Command{
 		Use:   "start",
@@ -30,14 +44,70 @@ func workerStartCmd() *cobra.Command {
 
 Workers will run in the foreground and can be stopped with Ctrl+C.
 They will gracefully finish any currently processing jobs before exiting.
+With --shutdown-timeout, jobs still running that long after the stop
+are killed and returned to pending, without counting as an attempt.
+
+Each worker runs one job at a time unless --concurrency is given, in
+which case it runs up to that many in parallel, so up to
+count × concurrency jobs run at once. This suits I/O-bound jobs.
 
 Examples:
   queuectl worker start              # Start 1 worker (default)
-  queuectl worker start --count 3    # Start 3 workers`,
+  queuectl worker start --count 3    # Start 3 workers
+  queuectl worker start --concurrency 8   # 1 worker running up to 8 jobs at once
+  queuectl worker start --log-file ~/.queuectl/worker.log
+  queuectl worker start --max-lifetime 1h   # Exit after an hour for a supervisor to restart
+  queuectl worker start --command-prefix backup- --command-prefix ./restore
+  queuectl worker start --queue emails --queue reports
+
+With --log-file, logs are written to the file instead of stdout and the
+file is rotated once it reaches log-max-size-mb, keeping log-max-backups
+gzip-compressed segments (see 'queuectl config list').
+
+A worker stops itself after max-consecutive-errors failed attempts in a
+row to fetch a job (e.g. a corrupt database). The command exits with an
+error once every worker has stopped, or as soon as one has with
+--exit-on-fatal. If the database becomes unreachable instead (e.g. a
+network mount disappears), workers re-open it with backoff until it is
+back, without counting towards max-consecutive-errors.
+
+If a notifier is configured (see 'queuectl config list'), it is told
+whenever a job moves to the DLQ, and also on completion with
+notify-on-success.
+
+With audit-command or audit-url set, every job start is recorded there
+before the command runs. A failed delivery is logged, and with
+audit-required the attempt fails instead of running.
+
+With --command-prefix the workers only claim jobs whose command starts
+with one of the given prefixes (case-sensitive), leaving other jobs for
+other workers. Repeat the flag to accept several prefixes.
+
+With --queue the workers only claim jobs in the named queues, so workers
+can be dedicated to a queue. Repeat the flag to serve several queues.
+Combined with --command-prefix, a job must match both.
+
+If otel-endpoint is set, job counts by outcome, job durations and the DLQ
+size are pushed to that OpenTelemetry collector over OTLP/HTTP every 15s
+and once more on shutdown.
+
+With --metrics-addr the workers serve Prometheus metrics at /metrics on
+that address: job counts by state, and counters of processed attempts,
+failures, retries and DLQ entries plus a job duration histogram for the
+workers in this process.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if count < 1 {
 				return fmt.Errorf("worker count must be at least 1")
 			}
+			if concurrency < 1 {
+				return fmt.Errorf("--concurrency must be at least 1")
+			}
+			if maxLifetime < 0 {
+				return fmt.Errorf("--max-lifetime cannot be negative")
+			}
+			if shutdownTimeout < 0 {
+				return fmt.Errorf("--shutdown-timeout cannot be negative")
+			}
 
 			// Cleanup any orphaned PID files from previous runs
 			if err := worker.CleanupOrphanedPIDs(); err != nil {
@@ -46,41 +116,188 @@ Examples:
 
 			// Create worker pool
 			pool := worker.NewPool(getStorage(), getConfig(), count)
+			pool.SetExitOnFatal(exitOnFatal)
+			pool.SetMaxLifetime(maxLifetime)
+			pool.SetConcurrency(concurrency)
+			pool.SetShutdownTimeout(shutdownTimeout)
+			pool.SetClaimFilter(storage.ClaimFilter{CommandPrefixes: commandPrefixes, Queues: queues})
+			pool.SetReconnect(func() (storage.Storage, error) {
+				// Don't let SQLite create an empty database in place of
+				// one that has gone missing
+				if path := getConfig().DBPath; !storage.IsRedisURL(path) {
+					if _, err := os.Stat(path); err != nil {
+						return nil, err
+					}
+				}
+				// openStorage applies the claim settings to the new
+				// connection, as it did to the first
+				return openStorage()
+			})
+
+			notifier, err := notify.New(getConfig())
+			if err != nil {
+				return fmt.Errorf("invalid notifier configuration: %w", err)
+			}
+			if notifier != nil {
+				pool.SetNotifier(notifier)
+			}
+			if auditor := audit.New(getConfig()); auditor != nil {
+				pool.SetAuditor(auditor)
+			}
+			if endpoint := getConfig().OTelEndpoint; endpoint != "" {
+				exporter, err := metrics.NewOTLPExporter(endpoint)
+				if err != nil {
+					return err
+				}
+				if err := pool.SetOTLPExporter(exporter); err != nil {
+					exporter.Shutdown(context.Background())
+					return fmt.Errorf("failed to set up metrics export: %w", err)
+				}
+			}
+			if metricsAddr != "" {
+				srv, err := serveMetrics(metricsAddr)
+				if err != nil {
+					return err
+				}
+				defer srv.Close()
+				pool.SetPromRecorder(metrics.NewPromRecorder())
+				fmt.Printf("Serving metrics on http://%s/metrics\n", metricsAddr)
+			}
+
+			if logFile != "" {
+				cfg := getConfig()
+				w := logrotate.New(logFile, int64(cfg.LogMaxSizeMB)*1024*1024, cfg.LogMaxBackups)
+				defer w.Close()
+				pool.SetLogOutput(w)
+				fmt.Printf("Logging to %s\n", logFile)
+			}
 
 			// Start workers
 			if err := pool.Start(); err != nil {
 				return fmt.Errorf("failed to start workers: %w", err)
 			}
 
-			// Wait for shutdown signal
-			pool.Wait()
+			// Wait for shutdown signal or fatal worker error
+			if err := pool.Wait(); err != nil {
+				return fmt.Errorf("workers stopped: %w", err)
+			}
 
 			return nil
 		},
 	}
 
 	cmd.Flags().IntVarP(&count, "count", "c", 1, "Number of workers to start")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 1, "Number of jobs each worker runs at once")
+	cmd.Flags().StringVar(&logFile, "log-file", "", "Write logs to a size-rotated file instead of stdout")
+	cmd.Flags().DurationVar(&maxLifetime, "max-lifetime", 0, "Stop gracefully after running this long (e.g. 1h)")
+	cmd.Flags().DurationVar(&shutdownTimeout, "shutdown-timeout", 0, "On stop, wait at most this long for running jobs before requeueing them (0 waits for them to finish)")
+	cmd.Flags().StringArrayVar(&commandPrefixes, "command-prefix", nil, "Only claim jobs whose command starts with this prefix (repeatable)")
+	cmd.Flags().StringArrayVarP(&queues, "queue", "q", nil, "Only claim jobs in this queue (repeatable)")
+	cmd.Flags().BoolVar(&exitOnFatal, "exit-on-fatal", false, "Exit as soon as any worker stops on a fatal error")
+	cmd.Flags().StringVar(&metricsAddr, "metrics-addr", "", "Serve Prometheus metrics at /metrics on this address (e.g. :9090)")
 
 	return cmd
 }
 
 func workerStopCmd() *cobra.Command {
+	var timeout time.Duration
+
 	cmd := &cobra.Command{
 		Use:   "stop",
 		Short: "Stop running workers",
 		Long: `Stop all running worker processes gracefully.
 
-This command will signal workers to stop processing new jobs and wait
-for currently executing jobs to complete before shutting down.
+Every worker process of the profile (recorded in its workers
+directory, ~/.queuectl/workers for the default profile) is sent SIGTERM,
+the same as pressing Ctrl+C in its terminal: it stops claiming jobs and
+finishes the ones it is running (or requeues them after the
+--shutdown-timeout it was started with) before exiting.
 
-Note: This is primarily for documentation. In practice, workers are
-stopped by pressing Ctrl+C in the terminal where they're running.`,
+The command waits up to --timeout for the workers to exit and reports
+which stopped and which are still running, failing if any are.
+
+Examples:
+  queuectl worker stop
+  queuectl worker stop --timeout 5m`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			fmt.Println("To stop workers, press Ctrl+C in the terminal where they are running.")
-			fmt.Println("Workers will gracefully finish their current jobs before stopping.")
+			if timeout < 0 {
+				return fmt.Errorf("--timeout cannot be negative")
+			}
+
+			workers := getActiveWorkers()
+			if len(workers) == 0 {
+				fmt.Println("No running workers")
+				return nil
+			}
+
+			// Workers started together share a process; signal it once
+			signalled := make(map[string]bool)
+			for _, w := range workers {
+				if signalled[w.PID] {
+					continue
+				}
+				if err := terminateProcess(w.PID); err != nil {
+					fmt.Printf("Warning: Failed to signal process %s: %v\n", w.PID, err)
+					continue
+				}
+				signalled[w.PID] = true
+			}
+			fmt.Printf("Sent SIGTERM to %d worker process(es), waiting up to %s...\n", len(signalled), timeout)
+
+			deadline := time.Now().Add(timeout)
+			for {
+				running := 0
+				for _, w := range workers {
+					if !workerStopped(w) {
+						running++
+					}
+				}
+				if running == 0 || !time.Now().Before(deadline) {
+					break
+				}
+				time.Sleep(200 * time.Millisecond)
+			}
+
+			var stillRunning int
+			for _, w := range workers {
+				if workerStopped(w) {
+					fmt.Printf("  ✓ Worker %s (pid %s) stopped\n", w.ID, w.PID)
+				} else {
+					fmt.Printf("  ✗ Worker %s (pid %s) still running\n", w.ID, w.PID)
+					stillRunning++
+				}
+			}
+
+			if stillRunning > 0 {
+				return fmt.Errorf("%d of %d worker(s) did not stop within %s (they may still be finishing jobs)", stillRunning, len(workers), timeout)
+			}
 			return nil
 		},
 	}
 
+	cmd.Flags().DurationVar(&timeout, "timeout", 30*time.Second, "How long to wait for the workers to exit")
+
 	return cmd
 }
+
+// terminateProcess sends SIGTERM to the process with the given PID
+func terminateProcess(pid string) error {
+	pidInt, err := strconv.Atoi(pid)
+	if err != nil {
+		return fmt.Errorf("invalid pid %q", pid)
+	}
+	process, err := os.FindProcess(pidInt)
+	if err != nil {
+		return err
+	}
+	return process.Signal(syscall.SIGTERM)
+}
+
+// workerStopped reports whether the worker has removed its PID file on
+// exit, or its process is gone
+func workerStopped(w Worker) bool {
+	if _, err := os.Stat(worker.PIDPath(w.ID)); os.IsNotExist(err) {
+		return true
+	}
+	return !worker.ProcessRunning(w.PID)
+}